@@ -0,0 +1,52 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethersphere/bee/pkg/auth"
+	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
+	"github.com/spf13/cobra"
+)
+
+func (c *command) initAuthSecretCmd() {
+	cmd := &cobra.Command{
+		Use:   "auth-secret",
+		Short: "Print the admin secret that is exchanged for HTTP API tokens",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			dataDir := c.config.GetString(optionNameDataDir)
+			if dataDir == "" {
+				return errors.New("data directory is required")
+			}
+			password, err := c.password(cmd)
+			if err != nil {
+				return err
+			}
+			swarmPrivateKey, _, err := filekeystore.New(filepath.Join(dataDir, "keys")).Key("swarm", password)
+			if err != nil {
+				return fmt.Errorf("swarm key: %w", err)
+			}
+
+			cmd.Println(auth.AdminSecret(swarmPrivateKey))
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
+	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
+	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
+
+	c.root.AddCommand(cmd)
+}
@@ -0,0 +1,193 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethersphere/bee/pkg/client"
+	"github.com/ethersphere/bee/pkg/listener"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	optionNameAPIToken = "api-token"
+	optionNameJSON     = "json"
+)
+
+// initClientCmds adds commands that make requests
+// to the HTTP API and the debug HTTP API of a node.
+func (c *command) initClientCmds() {
+	c.initUploadCmd()
+	c.initDownloadCmd()
+	c.initTagsCmd()
+	c.initPeersCmd()
+	c.initTopologyCmd()
+	c.initConnectCmd()
+	c.initPingCmd()
+}
+
+// newClientCmd returns a command with flags that locate the node. The
+// flags have the same names as the start command ones, so that the
+// node addresses are found in the same config file and environment.
+func (c *command) newClientCmd(cmd *cobra.Command, debug bool) *cobra.Command {
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		return c.config.BindPFlags(cmd.Flags())
+	}
+
+	if debug {
+		cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory with the debug HTTP API socket")
+		cmd.Flags().String(optionNameDebugAPIAddr, "", "debug HTTP API address, or a Unix socket path prefixed with unix:, the debug.sock socket in the data directory if empty")
+		cmd.Flags().String(optionNameDebugAPITLSCert, "", "path to the PEM encoded TLS certificate of the debug HTTP API")
+	} else {
+		cmd.Flags().String(optionNameAPIAddr, ":8080", "HTTP API address, or a Unix socket path prefixed with unix:")
+		cmd.Flags().String(optionNameAPITLSCert, "", "path to the PEM encoded TLS certificate of the HTTP API")
+		cmd.Flags().String(optionNameAPIToken, "", "bearer token for the HTTP API")
+	}
+	cmd.Flags().Bool(optionNameJSON, false, "print JSON responses")
+	return cmd
+}
+
+// apiClient returns the client of the HTTP API of the node.
+func (c *command) apiClient() (*client.Client, error) {
+	addr, o, err := clientOptions(c.config.GetString(optionNameAPIAddr), c.config.GetString(optionNameAPITLSCert))
+	if err != nil {
+		return nil, err
+	}
+	o.Token = c.config.GetString(optionNameAPIToken)
+	return client.New(addr, o)
+}
+
+// debugClient returns the client of the debug HTTP API of the node.
+func (c *command) debugClient() (*client.DebugClient, error) {
+	addr, err := c.debugAPIAddr()
+	if err != nil {
+		return nil, err
+	}
+	addr, o, err := clientOptions(addr, c.config.GetString(optionNameDebugAPITLSCert))
+	if err != nil {
+		return nil, err
+	}
+	return client.NewDebug(addr, o)
+}
+
+// clientOptions returns the URL and client options for the listen
+// address of the server. TCP addresses of servers with the TLS
+// certificate are requested over HTTPS, trusting the certificate.
+func clientOptions(addr, certFile string) (string, *client.Options, error) {
+	o := new(client.Options)
+	if certFile == "" || strings.HasPrefix(addr, listener.UnixPrefix) || strings.Contains(addr, "://") {
+		return addr, o, nil
+	}
+
+	pem, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("read tls certificate: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return "", nil, fmt.Errorf("invalid tls certificate %s", certFile)
+	}
+	o.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    pool,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+	return "https://" + addr, o, nil
+}
+
+// printJSON writes the value as indented JSON if the json option is set
+// and returns true, or returns false for the caller to print it.
+func (c *command) printJSON(cmd *cobra.Command, v interface{}) (bool, error) {
+	if !c.config.GetBool(optionNameJSON) {
+		return false, nil
+	}
+	e := json.NewEncoder(cmd.OutOrStdout())
+	e.SetIndent("", "  ")
+	return true, e.Encode(v)
+}
+
+// newTable returns a writer that aligns tab separated columns.
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+}
+
+// isTerminal returns true if the writer is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+// progressBarWidth is the number of characters
+// between brackets of the progress bar.
+const progressBarWidth = 40
+
+// progressBar prints the progress of a long running command on a single
+// line of a terminal. It prints nothing if the writer is not a terminal.
+type progressBar struct {
+	w       io.Writer
+	unit    string
+	total   int64
+	last    string
+	enabled bool
+}
+
+func newProgressBar(w io.Writer, unit string, total int64) *progressBar {
+	return &progressBar{
+		w:       w,
+		unit:    unit,
+		total:   total,
+		enabled: isTerminal(w),
+	}
+}
+
+// set prints the progress if it changed since it was last printed. The
+// bar is omitted if the total is not known.
+func (p *progressBar) set(n int64) {
+	if !p.enabled {
+		return
+	}
+	var line string
+	if p.total > 0 {
+		if n > p.total {
+			n = p.total
+		}
+		done := int(n * progressBarWidth / p.total)
+		line = fmt.Sprintf("[%s%s] %3d%% %d/%d %s",
+			strings.Repeat("=", done), strings.Repeat(" ", progressBarWidth-done),
+			n*100/p.total, n, p.total, p.unit)
+	} else {
+		line = fmt.Sprintf("%d %s", n, p.unit)
+	}
+	if line == p.last {
+		return
+	}
+	p.last = line
+	fmt.Fprintf(p.w, "\r%s", line)
+}
+
+// done ends the progress line.
+func (p *progressBar) done() {
+	if p.enabled && p.last != "" {
+		fmt.Fprintln(p.w)
+	}
+}
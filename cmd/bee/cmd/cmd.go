@@ -59,6 +59,9 @@ func newCommand(opts ...option) (c *command, err error) {
 		return nil, err
 	}
 
+	c.initDBCmd()
+	c.initAuthSecretCmd()
+	c.initClientCmds()
 	c.initVersionCmd()
 	return c, nil
 }
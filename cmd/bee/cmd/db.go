@@ -0,0 +1,396 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/file"
+	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// dbProgressInterval is the number of chunks after
+// which db commands report their progress.
+const dbProgressInterval = 10000
+
+func (c *command) initDBCmd() {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the local chunk database of a stopped node",
+	}
+
+	c.initDBExportCmd(cmd)
+	c.initDBImportCmd(cmd)
+	c.initDBCheckCmd(cmd)
+	c.initDBMigrateCmd(cmd)
+
+	c.root.AddCommand(cmd)
+}
+
+func (c *command) initDBExportCmd(parent *cobra.Command) {
+	const (
+		optionNameOutput       = "output"
+		optionNameBins         = "bin"
+		optionNameSince        = "since"
+		optionNameSinceArchive = "since-archive"
+		optionNameReferences   = "reference"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export chunks from the local database to a tar archive",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			o := new(localstore.ExportOptions)
+			for _, bin := range c.config.GetIntSlice(optionNameBins) {
+				if bin < 0 || bin > swarm.MaxPO {
+					return fmt.Errorf("invalid bin %v", bin)
+				}
+				o.Bins = append(o.Bins, uint8(bin))
+			}
+			if a := c.config.GetString(optionNameSinceArchive); a != "" {
+				f, err := os.Open(a)
+				if err != nil {
+					return fmt.Errorf("open since archive: %w", err)
+				}
+				o.Since, err = localstore.ReadExportCursor(f)
+				f.Close()
+				if err != nil {
+					return fmt.Errorf("read since archive: %w", err)
+				}
+			}
+			for _, v := range c.config.GetStringSlice(optionNameSince) {
+				bin, id, err := parseBinID(v)
+				if err != nil {
+					return fmt.Errorf("invalid since value %q: %w", v, err)
+				}
+				if o.Since == nil {
+					o.Since = make(map[uint8]uint64)
+				}
+				o.Since[bin] = id
+			}
+			for _, v := range c.config.GetStringSlice(optionNameReferences) {
+				ref, err := swarm.ParseHexAddress(v)
+				if err != nil {
+					return fmt.Errorf("invalid reference %q: %w", v, err)
+				}
+				o.References = append(o.References, ref)
+			}
+
+			db, err := c.openMigratedLocalstore(cmd)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			w := cmd.OutOrStdout()
+			if out := c.config.GetString(optionNameOutput); out != "-" {
+				f, err := os.Create(out)
+				if err != nil {
+					return fmt.Errorf("create output file: %w", err)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			o.Progress = dbProgress(cmd, "exported")
+			count, err := db.Export(w, o)
+			if err != nil {
+				return fmt.Errorf("export: %w", err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "export done: %d chunks\n", count)
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	c.setDBFlags(cmd)
+	cmd.Flags().String(optionNameOutput, "-", "file to write the archive to, - for standard output")
+	cmd.Flags().IntSlice(optionNameBins, nil, "export only chunks in these proximity order bins")
+	cmd.Flags().StringSlice(optionNameSince, nil, "export only chunks with bin IDs greater than the provided ones, in bin:binID format")
+	cmd.Flags().String(optionNameSinceArchive, "", "export only chunks stored after the export to this archive")
+	cmd.Flags().StringSlice(optionNameReferences, nil, "export only chunk trees with these root references")
+
+	parent.AddCommand(cmd)
+}
+
+func (c *command) initDBImportCmd(parent *cobra.Command) {
+	const (
+		optionNameInput = "input"
+		optionNamePin   = "pin"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import chunks from a tar archive to the local database",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			var r io.Reader = cmd.InOrStdin()
+			if i := c.config.GetString(optionNameInput); i != "-" {
+				f, err := os.Open(i)
+				if err != nil {
+					return fmt.Errorf("open input file: %w", err)
+				}
+				defer f.Close()
+				r = f
+			}
+
+			db, err := c.openLocalstore(cmd, false, nil)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			count, err := db.Import(r, &localstore.ImportOptions{
+				Validator: file.NewContentAddressValidator(),
+				Pin:       c.config.GetBool(optionNamePin),
+				Progress:  dbProgress(cmd, "imported"),
+			})
+			if err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "import done: %d chunks\n", count)
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	c.setDBFlags(cmd)
+	cmd.Flags().String(optionNameInput, "-", "file to read the archive from, - for standard input which requires the password option or password file")
+	cmd.Flags().Bool(optionNamePin, false, "pin all imported chunks")
+
+	parent.AddCommand(cmd)
+}
+
+func (c *command) initDBCheckCmd(parent *cobra.Command) {
+	const (
+		optionNameRepair = "repair"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the consistency of the local database and print a JSON report",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			db, err := c.openMigratedLocalstore(cmd)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			o := &localstore.VerifyOptions{
+				Validator: file.NewContentAddressValidator(),
+			}
+			repair := c.config.GetBool(optionNameRepair)
+			var r *localstore.VerifyReport
+			if repair {
+				r, err = db.Repair(o)
+			} else {
+				r, err = db.Verify(o)
+			}
+			if err != nil {
+				return fmt.Errorf("check: %w", err)
+			}
+
+			e := json.NewEncoder(cmd.OutOrStdout())
+			e.SetIndent("", "  ")
+			if err := e.Encode(r); err != nil {
+				return fmt.Errorf("write report: %w", err)
+			}
+			if !repair && !r.OK() {
+				return errors.New("database is inconsistent")
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	c.setDBFlags(cmd)
+	cmd.Flags().Bool(optionNameRepair, false, "remove dangling index items and chunks with invalid content, restore missing or inconsistent index items and recompute counters")
+
+	parent.AddCommand(cmd)
+}
+
+func (c *command) initDBMigrateCmd(parent *cobra.Command) {
+	const (
+		optionNameDryRun = "dry-run"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Run pending schema migrations of the local database and print a JSON report",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			db, err := c.openLocalstore(cmd, true, &localstore.Options{
+				SkipMigrations: true,
+			})
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			reports, err := db.Migrate(&localstore.MigrateOptions{
+				DryRun: c.config.GetBool(optionNameDryRun),
+				Progress: func(name string, n, total int) {
+					fmt.Fprintf(cmd.ErrOrStderr(), "migration %d/%d: %s\n", n, total, name)
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("migrate: %w", err)
+			}
+			if reports == nil {
+				reports = []localstore.MigrationReport{}
+			}
+
+			e := json.NewEncoder(cmd.OutOrStdout())
+			e.SetIndent("", "  ")
+			if err := e.Encode(reports); err != nil {
+				return fmt.Errorf("write report: %w", err)
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	c.setDBFlags(cmd)
+	cmd.Flags().Bool(optionNameDryRun, false, "only report pending migrations without changing the database")
+
+	parent.AddCommand(cmd)
+}
+
+// dbProgress returns a progress function of db commands that reports
+// the number of processed chunks once at least dbProgressInterval
+// chunks are processed since the last report.
+func dbProgress(cmd *cobra.Command, action string) func(count int64) {
+	var reported int64
+	return func(count int64) {
+		if count-reported >= dbProgressInterval {
+			reported = count
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s %d chunks\n", action, count)
+		}
+	}
+}
+
+// parseBinID parses a bin and a bin ID separated by a colon.
+func parseBinID(v string) (bin uint8, id uint64, err error) {
+	parts := strings.SplitN(v, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, errors.New("missing bin ID")
+	}
+	b, err := strconv.ParseUint(parts[0], 10, 8)
+	if err != nil {
+		return 0, 0, err
+	}
+	if b > swarm.MaxPO {
+		return 0, 0, fmt.Errorf("bin %v out of range", b)
+	}
+	id, err = strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint8(b), id, nil
+}
+
+// setDBFlags sets flags that are required to open the local database.
+func (c *command) setDBFlags(cmd *cobra.Command) {
+	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
+	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
+	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
+	cmd.Flags().String(optionNameDBBackend, shed.BackendBadger, "local database key-value store, badger or leveldb")
+}
+
+// openMigratedLocalstore opens the existing local database without
+// running its schema migrations, and returns an error if any of
+// them are pending, as they are run only by the db migrate command.
+func (c *command) openMigratedLocalstore(cmd *cobra.Command) (*localstore.DB, error) {
+	db, err := c.openLocalstore(cmd, true, &localstore.Options{
+		SkipMigrations: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	pending, err := db.MigrationsPending()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("localstore: %w", err)
+	}
+	if pending {
+		db.Close()
+		return nil, errors.New("localstore has pending schema migrations, run the db migrate command first")
+	}
+	return db, nil
+}
+
+// openLocalstore opens the local database in the data directory with the
+// overlay address of the node. If mustExist is true, an error is returned
+// if the data directory does not contain a local database.
+func (c *command) openLocalstore(cmd *cobra.Command, mustExist bool, o *localstore.Options) (*localstore.DB, error) {
+	dataDir := c.config.GetString(optionNameDataDir)
+	if dataDir == "" {
+		return nil, errors.New("data directory is required")
+	}
+	path := filepath.Join(dataDir, "localstore")
+	if mustExist {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("localstore: %w", err)
+		}
+	}
+
+	password, err := c.password(cmd)
+	if err != nil {
+		return nil, err
+	}
+	swarmPrivateKey, _, err := filekeystore.New(filepath.Join(dataDir, "keys")).Key("swarm", password)
+	if err != nil {
+		return nil, fmt.Errorf("swarm key: %w", err)
+	}
+	address := crypto.NewAddress(swarmPrivateKey.PublicKey)
+
+	if o == nil {
+		o = new(localstore.Options)
+	}
+	o.Backend = c.config.GetString(optionNameDBBackend)
+
+	logger := logging.New(cmd.ErrOrStderr(), logrus.WarnLevel)
+	db, err := localstore.New(path, address.Bytes(), o, logger)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: %w", err)
+	}
+	return db, nil
+}
@@ -14,6 +14,7 @@ type (
 
 var (
 	NewCommand = newCommand
+	ParseSize  = parseSize
 
 	// avoid unused lint errors until the functions are used
 	_ = WithCfgFile
@@ -0,0 +1,155 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/spf13/cobra"
+)
+
+func (c *command) initPeersCmd() {
+	cmd := c.newClientCmd(&cobra.Command{
+		Use:   "peers",
+		Short: "Print peers connected to the node",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			debugClient, err := c.debugClient()
+			if err != nil {
+				return err
+			}
+			resp, err := debugClient.Peers(context.Background())
+			if err != nil {
+				return err
+			}
+
+			if ok, err := c.printJSON(cmd, resp); ok {
+				return err
+			}
+			w := newTable(cmd.OutOrStdout())
+			fmt.Fprintln(w, "ADDRESS")
+			for _, p := range resp.Peers {
+				fmt.Fprintln(w, p.Address)
+			}
+			return w.Flush()
+		},
+	}, true)
+
+	c.root.AddCommand(cmd)
+}
+
+func (c *command) initConnectCmd() {
+	cmd := c.newClientCmd(&cobra.Command{
+		Use:   "connect <multiaddr>",
+		Short: "Connect the node to the peer with the underlay address",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			addr, err := multiaddr.NewMultiaddr(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid multiaddr %q: %w", args[0], err)
+			}
+			debugClient, err := c.debugClient()
+			if err != nil {
+				return err
+			}
+			resp, err := debugClient.Connect(context.Background(), addr)
+			if err != nil {
+				return err
+			}
+
+			if ok, err := c.printJSON(cmd, resp); ok {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), resp.Address)
+			return nil
+		},
+	}, true)
+
+	c.root.AddCommand(cmd)
+}
+
+func (c *command) initPingCmd() {
+	cmd := c.newClientCmd(&cobra.Command{
+		Use:   "ping <overlay>",
+		Short: "Ping the connected peer with the overlay address and print the round trip time",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			peer, err := swarm.ParseHexAddress(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid overlay address %q: %w", args[0], err)
+			}
+			apiClient, err := c.apiClient()
+			if err != nil {
+				return err
+			}
+			resp, err := apiClient.Pingpong(context.Background(), peer)
+			if err != nil {
+				return err
+			}
+
+			if ok, err := c.printJSON(cmd, resp); ok {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), resp.RTT)
+			return nil
+		},
+	}, false)
+
+	c.root.AddCommand(cmd)
+}
+
+func (c *command) initTopologyCmd() {
+	cmd := c.newClientCmd(&cobra.Command{
+		Use:   "topology",
+		Short: "Print the neighbourhood depth and connected and known peers in bins of the node",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			debugClient, err := c.debugClient()
+			if err != nil {
+				return err
+			}
+			resp, err := debugClient.Topology(context.Background())
+			if err != nil {
+				return err
+			}
+
+			if ok, err := c.printJSON(cmd, resp); ok {
+				return err
+			}
+			w := newTable(cmd.OutOrStdout())
+			fmt.Fprintf(w, "base\t%s\n", resp.BaseAddr)
+			fmt.Fprintf(w, "depth\t%d\n", resp.Depth)
+			fmt.Fprintf(w, "connected\t%d\n", resp.Connected)
+			fmt.Fprintf(w, "known\t%d\n", resp.Known)
+			if len(resp.Bins) > 0 {
+				fmt.Fprintln(w, "\nBIN\tCONNECTED\tKNOWN\tSATURATED")
+				for _, b := range resp.Bins {
+					if b.Known == 0 && b.Connected == 0 {
+						continue
+					}
+					fmt.Fprintf(w, "%d\t%d\t%d\t%t\n", b.PO, b.Connected, b.Known, b.Saturated)
+				}
+			}
+			return w.Flush()
+		},
+	}, true)
+
+	c.root.AddCommand(cmd)
+}
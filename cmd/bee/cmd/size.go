@@ -0,0 +1,47 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits are multipliers of size units, with decimal
+// units as powers of 1000 and binary units as powers of 1024.
+var sizeUnits = map[string]uint64{
+	"":    1,
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// parseSize parses a number of bytes with an optional
+// unit, such as "50GB", "512MiB" or "1024".
+func parseSize(s string) (size uint64, err error) {
+	v := strings.TrimSpace(s)
+	i := strings.IndexFunc(v, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(v)
+	}
+	unit, ok := sizeUnits[strings.ToUpper(strings.TrimSpace(v[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit", s)
+	}
+	n, err := strconv.ParseFloat(v[:i], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return uint64(n * float64(unit)), nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/cmd/bee/cmd"
+)
+
+func TestParseSize(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    uint64
+		wantErr bool
+	}{
+		{in: "1024", want: 1024},
+		{in: "100B", want: 100},
+		{in: "50GB", want: 50 * 1000 * 1000 * 1000},
+		{in: "50 gb", want: 50 * 1000 * 1000 * 1000},
+		{in: "1.5MB", want: 1500 * 1000},
+		{in: "512MiB", want: 512 * 1024 * 1024},
+		{in: "2TiB", want: 2 << 40},
+		{in: "10XB", wantErr: true},
+		{in: "GB", wantErr: true},
+		{in: "", wantErr: true},
+	} {
+		got, err := cmd.ParseSize(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%q: got no error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -19,28 +20,59 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/ethersphere/bee/pkg/listener"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/resolver/ens"
+	"github.com/ethersphere/bee/pkg/shed"
+)
+
+const (
+	optionNameDataDir         = "data-dir"
+	optionNamePassword        = "password"
+	optionNamePasswordFile    = "password-file"
+	optionNameDBBackend       = "db-backend"
+	optionNameAPIAddr         = "api-addr"
+	optionNameAPITLSCert      = "api-tls-cert"
+	optionNameDebugAPIAddr    = "debug-api-addr"
+	optionNameDebugAPITLSCert = "debug-api-tls-cert"
 )
 
 func (c *command) initStartCmd() (err error) {
 
 	const (
-		optionNameDataDir            = "data-dir"
-		optionNamePassword           = "password"
-		optionNamePasswordFile       = "password-file"
-		optionNameAPIAddr            = "api-addr"
-		optionNameP2PAddr            = "p2p-addr"
-		optionNameP2PDisableWS       = "p2p-disable-ws"
-		optionNameP2PDisableQUIC     = "p2p-disable-quic"
-		optionNameEnableDebugAPI     = "enable-debug-api"
-		optionNameDebugAPIAddr       = "debug-api-addr"
-		optionNameBootnodes          = "bootnode"
-		optionNameNetworkID          = "network-id"
-		optionNameTracingEnabled     = "tracing"
-		optionNameTracingEndpoint    = "tracing-endpoint"
-		optionNameTracingServiceName = "tracing-service-name"
-		optionNameVerbosity          = "verbosity"
+		optionNameAPITLSKey           = "api-tls-key"
+		optionNameAPISocketMode       = "api-socket-mode"
+		optionNameAPIAuth             = "api-auth"
+		optionNameAPIPublicScopes     = "api-public-scopes"
+		optionNameGateway             = "gateway"
+		optionNameGatewayWriteAllow   = "gateway-write-allowlist"
+		optionNameGatewayRequestRate  = "gateway-request-rate"
+		optionNameGatewayRequestBurst = "gateway-request-burst"
+		optionNameGatewayByteRate     = "gateway-byte-rate"
+		optionNameGatewayByteBurst    = "gateway-byte-burst"
+		optionNameGatewayUploadQuota  = "gateway-daily-upload-quota"
+		optionNameResolverStaticFile  = "resolver-static-file"
+		optionNameResolverENSEndpoint = "resolver-ens-endpoint"
+		optionNameResolverENSRegistry = "resolver-ens-registry"
+		optionNameResolverCacheTTL    = "resolver-cache-ttl"
+		optionNameP2PAddr             = "p2p-addr"
+		optionNameP2PDisableWS        = "p2p-disable-ws"
+		optionNameP2PDisableQUIC      = "p2p-disable-quic"
+		optionNameEnableDebugAPI      = "enable-debug-api"
+		optionNameDebugAPITLSKey      = "debug-api-tls-key"
+		optionNameDebugAPISocketMode  = "debug-api-socket-mode"
+		optionNameBootnodes           = "bootnode"
+		optionNameNetworkID           = "network-id"
+		optionNameTracingEnabled      = "tracing"
+		optionNameTracingEndpoint     = "tracing-endpoint"
+		optionNameTracingServiceName  = "tracing-service-name"
+		optionNameVerbosity           = "verbosity"
+		optionNamePostageEventsFile   = "postage-events-file"
+		optionNameDBCapacity          = "db-capacity"
+		optionNameDBReserveCapacity   = "db-reserve-capacity"
+		optionNameDBFreeDiskWatermark = "db-free-disk-watermark"
+		optionNameDBCacheCapacity     = "db-cache-capacity"
 	)
 
 	cmd := &cobra.Command{
@@ -69,47 +101,131 @@ func (c *command) initStartCmd() (err error) {
 				return fmt.Errorf("unknown verbosity level %q", v)
 			}
 
-			debugAPIAddr := c.config.GetString(optionNameDebugAPIAddr)
-			if !c.config.GetBool(optionNameEnableDebugAPI) {
-				debugAPIAddr = ""
+			var debugAPIAddr string
+			if c.config.GetBool(optionNameEnableDebugAPI) {
+				if debugAPIAddr, err = c.debugAPIAddr(); err != nil {
+					return err
+				}
+			}
+			apiSocketMode, err := parseFileMode(c.config.GetString(optionNameAPISocketMode))
+			if err != nil {
+				return fmt.Errorf("%s: %w", optionNameAPISocketMode, err)
+			}
+			debugAPISocketMode, err := parseFileMode(c.config.GetString(optionNameDebugAPISocketMode))
+			if err != nil {
+				return fmt.Errorf("%s: %w", optionNameDebugAPISocketMode, err)
 			}
 
-			var password string
-			if p := c.config.GetString(optionNamePassword); p != "" {
-				password = p
-			} else if pf := c.config.GetString(optionNamePasswordFile); pf != "" {
-				b, err := ioutil.ReadFile(pf)
-				if err != nil {
-					return err
+			var dbCapacity uint64
+			if v := c.config.GetString(optionNameDBCapacity); v != "" {
+				if dbCapacity, err = parseSize(v); err != nil {
+					return fmt.Errorf("%s: %w", optionNameDBCapacity, err)
 				}
-				password = string(bytes.Trim(b, "\n"))
-			} else {
-				p, err := terminalPromptPassword(cmd, c.passwordReader, "Password")
-				if err != nil {
-					return err
+			}
+			var dbReserveCapacity uint64
+			if v := c.config.GetString(optionNameDBReserveCapacity); v != "" {
+				if dbReserveCapacity, err = parseSize(v); err != nil {
+					return fmt.Errorf("%s: %w", optionNameDBReserveCapacity, err)
+				}
+			}
+			var dbFreeDiskWatermark uint64
+			if v := c.config.GetString(optionNameDBFreeDiskWatermark); v != "" {
+				if dbFreeDiskWatermark, err = parseSize(v); err != nil {
+					return fmt.Errorf("%s: %w", optionNameDBFreeDiskWatermark, err)
+				}
+			}
+			var dbCacheCapacity uint64
+			if v := c.config.GetString(optionNameDBCacheCapacity); v != "" {
+				if dbCacheCapacity, err = parseSize(v); err != nil {
+					return fmt.Errorf("%s: %w", optionNameDBCacheCapacity, err)
+				}
+			}
+
+			var gatewayByteRate uint64
+			if v := c.config.GetString(optionNameGatewayByteRate); v != "" {
+				if gatewayByteRate, err = parseSize(v); err != nil {
+					return fmt.Errorf("%s: %w", optionNameGatewayByteRate, err)
+				}
+			}
+			var gatewayByteBurst uint64
+			if v := c.config.GetString(optionNameGatewayByteBurst); v != "" {
+				if gatewayByteBurst, err = parseSize(v); err != nil {
+					return fmt.Errorf("%s: %w", optionNameGatewayByteBurst, err)
+				}
+			}
+			var gatewayUploadQuota uint64
+			if v := c.config.GetString(optionNameGatewayUploadQuota); v != "" {
+				if gatewayUploadQuota, err = parseSize(v); err != nil {
+					return fmt.Errorf("%s: %w", optionNameGatewayUploadQuota, err)
 				}
-				password = p
+			}
+			if gatewayByteBurst == 0 {
+				gatewayByteBurst = gatewayByteRate
+			}
+
+			password, err := c.password(cmd)
+			if err != nil {
+				return err
 			}
 
 			b, err := node.NewBee(node.Options{
-				DataDir:            c.config.GetString(optionNameDataDir),
-				Password:           password,
-				APIAddr:            c.config.GetString(optionNameAPIAddr),
-				DebugAPIAddr:       debugAPIAddr,
-				Addr:               c.config.GetString(optionNameP2PAddr),
-				DisableWS:          c.config.GetBool(optionNameP2PDisableWS),
-				DisableQUIC:        c.config.GetBool(optionNameP2PDisableQUIC),
-				NetworkID:          c.config.GetInt32(optionNameNetworkID),
-				Bootnodes:          c.config.GetStringSlice(optionNameBootnodes),
-				TracingEnabled:     c.config.GetBool(optionNameTracingEnabled),
-				TracingEndpoint:    c.config.GetString(optionNameTracingEndpoint),
-				TracingServiceName: c.config.GetString(optionNameTracingServiceName),
-				Logger:             logger,
+				DataDir:               c.config.GetString(optionNameDataDir),
+				Password:              password,
+				APIAddr:               c.config.GetString(optionNameAPIAddr),
+				APITLSCertFile:        c.config.GetString(optionNameAPITLSCert),
+				APITLSKeyFile:         c.config.GetString(optionNameAPITLSKey),
+				APISocketMode:         apiSocketMode,
+				APIAuth:               c.config.GetBool(optionNameAPIAuth),
+				APIPublicScopes:       c.config.GetStringSlice(optionNameAPIPublicScopes),
+				Gateway:               c.config.GetBool(optionNameGateway),
+				GatewayWriteAllowlist: c.config.GetStringSlice(optionNameGatewayWriteAllow),
+				GatewayRequestRate:    c.config.GetFloat64(optionNameGatewayRequestRate),
+				GatewayRequestBurst:   c.config.GetInt(optionNameGatewayRequestBurst),
+				GatewayByteRate:       gatewayByteRate,
+				GatewayByteBurst:      gatewayByteBurst,
+				GatewayUploadQuota:    gatewayUploadQuota,
+				ResolverStaticFile:    c.config.GetString(optionNameResolverStaticFile),
+				ResolverENSEndpoint:   c.config.GetString(optionNameResolverENSEndpoint),
+				ResolverENSRegistry:   c.config.GetString(optionNameResolverENSRegistry),
+				ResolverCacheTTL:      c.config.GetDuration(optionNameResolverCacheTTL),
+				DebugAPIAddr:          debugAPIAddr,
+				DebugAPITLSCertFile:   c.config.GetString(optionNameDebugAPITLSCert),
+				DebugAPITLSKeyFile:    c.config.GetString(optionNameDebugAPITLSKey),
+				DebugAPISocketMode:    debugAPISocketMode,
+				Addr:                  c.config.GetString(optionNameP2PAddr),
+				DisableWS:             c.config.GetBool(optionNameP2PDisableWS),
+				DisableQUIC:           c.config.GetBool(optionNameP2PDisableQUIC),
+				NetworkID:             c.config.GetInt32(optionNameNetworkID),
+				Bootnodes:             c.config.GetStringSlice(optionNameBootnodes),
+				TracingEnabled:        c.config.GetBool(optionNameTracingEnabled),
+				TracingEndpoint:       c.config.GetString(optionNameTracingEndpoint),
+				TracingServiceName:    c.config.GetString(optionNameTracingServiceName),
+				PostageEventsFile:     c.config.GetString(optionNamePostageEventsFile),
+				DBBackend:             c.config.GetString(optionNameDBBackend),
+				DBCapacity:            dbCapacity,
+				DBReserveCapacity:     dbReserveCapacity,
+				DBFreeDiskWatermark:   dbFreeDiskWatermark,
+				DBCacheCapacity:       dbCacheCapacity,
+				Logger:                logger,
 			})
 			if err != nil {
 				return err
 			}
 
+			// Reload TLS certificates on hangup signals.
+			hangupChannel := make(chan os.Signal, 1)
+			signal.Notify(hangupChannel, syscall.SIGHUP)
+			defer signal.Stop(hangupChannel)
+			go func() {
+				for range hangupChannel {
+					if err := b.ReloadTLS(); err != nil {
+						logger.Errorf("reload tls certificates: %v", err)
+						continue
+					}
+					logger.Info("tls certificates reloaded")
+				}
+			}()
+
 			// Wait for termination or interrupt signals.
 			// We want to clean up things at the end.
 			interruptChannel := make(chan os.Signal, 1)
@@ -149,22 +265,86 @@ func (c *command) initStartCmd() (err error) {
 		},
 	}
 
-	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
+	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory, data is kept only in memory if empty")
 	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
 	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
-	cmd.Flags().String(optionNameAPIAddr, ":8080", "HTTP API listen address")
+	cmd.Flags().String(optionNameAPIAddr, ":8080", "HTTP API listen address, or a Unix socket path prefixed with unix:")
+	cmd.Flags().String(optionNameAPITLSCert, "", "path to the PEM encoded TLS certificate of the HTTP API, reloaded on SIGHUP")
+	cmd.Flags().String(optionNameAPITLSKey, "", "path to the PEM encoded TLS private key of the HTTP API, reloaded on SIGHUP")
+	cmd.Flags().String(optionNameAPISocketMode, "0660", "permissions of the HTTP API Unix socket")
+	cmd.Flags().Bool(optionNameAPIAuth, false, "require bearer tokens issued for the admin secret on HTTP API routes")
+	cmd.Flags().StringSlice(optionNameAPIPublicScopes, []string{"read"}, "HTTP API scopes that do not require a token if authentication is enabled")
+	cmd.Flags().Bool(optionNameGateway, false, "run the HTTP API as a public gateway with writes disabled and per-client limits")
+	cmd.Flags().StringSlice(optionNameGatewayWriteAllow, nil, "IP addresses or networks of gateway clients that are allowed to write")
+	cmd.Flags().Float64(optionNameGatewayRequestRate, 10, "number of gateway requests per second per client, 0 for unlimited")
+	cmd.Flags().Int(optionNameGatewayRequestBurst, 50, "number of gateway requests per client above the request rate")
+	cmd.Flags().String(optionNameGatewayByteRate, "", "number of bytes per second transferred by a gateway client, for example 1MB, unlimited if empty")
+	cmd.Flags().String(optionNameGatewayByteBurst, "", "number of bytes transferred by a gateway client above the byte rate, equal to the rate if empty")
+	cmd.Flags().String(optionNameGatewayUploadQuota, "", "number of bytes a gateway client can upload in a day, for example 1GB, unlimited if empty")
+	cmd.Flags().String(optionNameResolverStaticFile, "", "path to a file with names and hex references to serve content by name, one pair per line")
+	cmd.Flags().String(optionNameResolverENSEndpoint, "", "Ethereum JSON-RPC endpoint to resolve ENS names, ENS names are not resolved if empty")
+	cmd.Flags().String(optionNameResolverENSRegistry, ens.DefaultRegistryAddress, "address of the ENS registry contract")
+	cmd.Flags().Duration(optionNameResolverCacheTTL, 5*time.Minute, "time for which resolved names are cached")
 	cmd.Flags().String(optionNameP2PAddr, ":7070", "P2P listen address")
 	cmd.Flags().Bool(optionNameP2PDisableWS, false, "disable P2P WebSocket protocol")
 	cmd.Flags().Bool(optionNameP2PDisableQUIC, false, "disable P2P QUIC protocol")
 	cmd.Flags().StringSlice(optionNameBootnodes, nil, "initial nodes to connect to")
 	cmd.Flags().Bool(optionNameEnableDebugAPI, false, "enable debug HTTP API")
-	cmd.Flags().String(optionNameDebugAPIAddr, ":6060", "debug HTTP API listen address")
+	cmd.Flags().String(optionNameDebugAPIAddr, "", "debug HTTP API listen address, or a Unix socket path prefixed with unix:, the debug.sock socket in the data directory if empty")
+	cmd.Flags().String(optionNameDebugAPITLSCert, "", "path to the PEM encoded TLS certificate of the debug HTTP API, reloaded on SIGHUP")
+	cmd.Flags().String(optionNameDebugAPITLSKey, "", "path to the PEM encoded TLS private key of the debug HTTP API, reloaded on SIGHUP")
+	cmd.Flags().String(optionNameDebugAPISocketMode, "0600", "permissions of the debug HTTP API Unix socket")
 	cmd.Flags().Int32(optionNameNetworkID, 1, "ID of the Swarm network")
 	cmd.Flags().Bool(optionNameTracingEnabled, false, "enable tracing")
 	cmd.Flags().String(optionNameTracingEndpoint, "127.0.0.1:6831", "endpoint to send tracing data")
 	cmd.Flags().String(optionNameTracingServiceName, "bee", "service name identifier for tracing")
 	cmd.Flags().String(optionNameVerbosity, "info", "log verbosity level 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=trace")
+	cmd.Flags().String(optionNamePostageEventsFile, "", "file with postage batch events, enables postage stamps")
+	cmd.Flags().String(optionNameDBBackend, shed.BackendBadger, "local database key-value store, badger or leveldb")
+	cmd.Flags().String(optionNameDBCapacity, "", "local database size that triggers garbage collection, for example 50GB, 5000000 chunks are kept if empty")
+	cmd.Flags().String(optionNameDBReserveCapacity, "", "size of synced chunks in the neighbourhood that triggers their garbage collection, for example 16GB, counted in 4KiB chunks, 16GiB if empty")
+	cmd.Flags().String(optionNameDBFreeDiskWatermark, "1GB", "free disk space below which chunks from the network are refused, empty to disable")
+	cmd.Flags().String(optionNameDBCacheCapacity, "", "size of the in-memory cache of retrieved chunks, for example 256MB, empty to disable")
 
 	c.root.AddCommand(cmd)
 	return nil
 }
+
+// debugAPIAddr returns the debug API address option, or the Unix
+// socket in the data directory if it is not set, so that the debug
+// API is not exposed on the network unless it is configured.
+func (c *command) debugAPIAddr() (string, error) {
+	if addr := c.config.GetString(optionNameDebugAPIAddr); addr != "" {
+		return addr, nil
+	}
+	dataDir := c.config.GetString(optionNameDataDir)
+	if dataDir == "" {
+		return "", fmt.Errorf("%s is required if the data directory is not set", optionNameDebugAPIAddr)
+	}
+	return listener.UnixPrefix + filepath.Join(dataDir, "debug.sock"), nil
+}
+
+// parseFileMode returns file permissions from the octal notation.
+func parseFileMode(s string) (os.FileMode, error) {
+	m, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || os.FileMode(m)&^os.ModePerm != 0 {
+		return 0, fmt.Errorf("invalid file mode %q", s)
+	}
+	return os.FileMode(m), nil
+}
+
+// password returns the password for decrypting keys from the password
+// option, the password file or from the terminal prompt, in that order.
+func (c *command) password(cmd *cobra.Command) (password string, err error) {
+	if p := c.config.GetString(optionNamePassword); p != "" {
+		return p, nil
+	}
+	if pf := c.config.GetString(optionNamePasswordFile); pf != "" {
+		b, err := ioutil.ReadFile(pf)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes.Trim(b, "\n")), nil
+	}
+	return terminalPromptPassword(cmd, c.passwordReader, "Password")
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+func (c *command) initTagsCmd() {
+	cmd := c.newClientCmd(&cobra.Command{
+		Use:   "tags [uid]",
+		Short: "Print progress of uploads tracked by tags, or by the tag with the uid",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 1 {
+				return cmd.Help()
+			}
+
+			apiClient, err := c.apiClient()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			var tags []api.TagResponse
+			if len(args) == 1 {
+				uid, err := strconv.ParseUint(args[0], 10, 32)
+				if err != nil {
+					return fmt.Errorf("invalid tag uid %q: %w", args[0], err)
+				}
+				tag, err := apiClient.Tag(ctx, uint32(uid))
+				if err != nil {
+					return err
+				}
+				if ok, err := c.printJSON(cmd, tag); ok {
+					return err
+				}
+				tags = append(tags, *tag)
+			} else {
+				resp, err := apiClient.Tags(ctx)
+				if err != nil {
+					return err
+				}
+				if ok, err := c.printJSON(cmd, resp); ok {
+					return err
+				}
+				tags = resp.Tags
+			}
+
+			w := newTable(cmd.OutOrStdout())
+			fmt.Fprintln(w, "UID\tNAME\tTOTAL\tSPLIT\tSTORED\tSENT\tSYNCED\tSTARTED")
+			for _, t := range tags {
+				fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+					t.Uid, t.Name, t.Total, t.Split, t.Stored, t.Sent, t.Synced,
+					t.StartedAt.Local().Format(time.RFC3339))
+			}
+			return w.Flush()
+		},
+	}, false)
+
+	c.root.AddCommand(cmd)
+}
@@ -0,0 +1,244 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/client"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// progressInterval is the period in which
+// progress bars of transfers are updated.
+const progressInterval = 100 * time.Millisecond
+
+type uploadResponse struct {
+	Reference swarm.Address `json:"reference"`
+	Tag       uint32        `json:"tag"`
+}
+
+func (c *command) initUploadCmd() {
+	const (
+		optionNamePin          = "pin"
+		optionNameTag          = "tag"
+		optionNamePostageBatch = "postage-batch"
+		optionNameConcurrency  = "concurrency"
+	)
+
+	cmd := c.newClientCmd(&cobra.Command{
+		Use:   "upload <file>",
+		Short: "Upload a file, or the standard input if the file is -, and print its reference",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			o := &client.UploadOptions{
+				Tag: c.config.GetUint32(optionNameTag),
+				Pin: c.config.GetBool(optionNamePin),
+			}
+			if v := c.config.GetString(optionNamePostageBatch); v != "" {
+				if o.PostageBatchID, err = hex.DecodeString(v); err != nil {
+					return fmt.Errorf("invalid postage batch id %q: %w", v, err)
+				}
+			}
+			concurrency := c.config.GetInt(optionNameConcurrency)
+			if concurrency < 1 {
+				return fmt.Errorf("invalid concurrency %v", concurrency)
+			}
+
+			var (
+				r     io.Reader
+				name  string
+				total int64
+			)
+			if args[0] == "-" {
+				r, name = cmd.InOrStdin(), "stdin"
+			} else {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				fi, err := f.Stat()
+				if err != nil {
+					return err
+				}
+				if fi.IsDir() {
+					return fmt.Errorf("%s is a directory", args[0])
+				}
+				r, name, total = f, filepath.Base(args[0]), file.ChunkCount(fi.Size())
+			}
+
+			apiClient, err := c.apiClient()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			if o.Tag == 0 {
+				tag, err := apiClient.CreateTag(ctx, api.TagRequest{
+					Name:  name,
+					Total: total,
+				})
+				if err != nil {
+					return fmt.Errorf("create tag: %w", err)
+				}
+				o.Tag = tag.Uid
+			}
+
+			var (
+				root     swarm.Address
+				uploaded int64
+				chunks   = make(chan swarm.Chunk)
+			)
+			g, ctx := errgroup.WithContext(ctx)
+			g.Go(func() (err error) {
+				defer close(chunks)
+				root, err = file.Split(ctx, r, func(ctx context.Context, ch swarm.Chunk) error {
+					select {
+					case chunks <- ch:
+						return nil
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				})
+				return err
+			})
+			for i := 0; i < concurrency; i++ {
+				g.Go(func() error {
+					for ch := range chunks {
+						if err := apiClient.UploadChunk(ctx, ch.Address(), ch.Data(), o); err != nil {
+							return fmt.Errorf("upload chunk %s: %w", ch.Address(), err)
+						}
+						atomic.AddInt64(&uploaded, 1)
+					}
+					return nil
+				})
+			}
+
+			progress := newProgressBar(cmd.ErrOrStderr(), "chunks", total)
+			if err := waitProgress(g.Wait, progress, &uploaded); err != nil {
+				return err
+			}
+
+			resp := uploadResponse{
+				Reference: root,
+				Tag:       o.Tag,
+			}
+			if ok, err := c.printJSON(cmd, resp); ok {
+				return err
+			}
+			w := newTable(cmd.OutOrStdout())
+			fmt.Fprintf(w, "reference\t%s\n", resp.Reference)
+			fmt.Fprintf(w, "tag\t%d\n", resp.Tag)
+			return w.Flush()
+		},
+	}, false)
+
+	cmd.Flags().Bool(optionNamePin, false, "pin uploaded chunks")
+	cmd.Flags().Uint32(optionNameTag, 0, "uid of the tag to which chunks are bound, a new tag is created if zero")
+	cmd.Flags().String(optionNamePostageBatch, "", "hex encoded id of the postage batch that stamps chunks")
+	cmd.Flags().Int(optionNameConcurrency, 16, "number of chunks uploaded in parallel")
+
+	c.root.AddCommand(cmd)
+}
+
+func (c *command) initDownloadCmd() {
+	const optionNameOutput = "output"
+
+	cmd := c.newClientCmd(&cobra.Command{
+		Use:   "download <reference|name>",
+		Short: "Download content by its reference or name to the standard output or a file",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			apiClient, err := c.apiClient()
+			if err != nil {
+				return err
+			}
+			body, size, err := apiClient.Download(context.Background(), args[0])
+			if err != nil {
+				return err
+			}
+			defer body.Close()
+
+			var w io.Writer = cmd.OutOrStdout()
+			progress := newProgressBar(cmd.ErrOrStderr(), "bytes", size)
+			if output := c.config.GetString(optionNameOutput); output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if cerr := f.Close(); err == nil {
+						err = cerr
+					}
+				}()
+				w = f
+			} else if isTerminal(w) {
+				// the progress would be mixed with the content
+				progress.enabled = false
+			}
+
+			var written int64
+			return waitProgress(func() error {
+				_, err := io.Copy(&countingWriter{w: w, n: &written}, body)
+				return err
+			}, progress, &written)
+		},
+	}, false)
+
+	cmd.Flags().StringP(optionNameOutput, "o", "", "file to which content is written, the standard output if empty or -")
+
+	c.root.AddCommand(cmd)
+}
+
+// waitProgress calls the function and updates the progress
+// bar with the counter until the function returns.
+func waitProgress(f func() error, progress *progressBar, n *int64) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			progress.set(atomic.LoadInt64(n))
+			progress.done()
+			return err
+		case <-ticker.C:
+			progress.set(atomic.LoadInt64(n))
+		}
+	}
+}
+
+// countingWriter counts bytes written to the writer.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	atomic.AddInt64(w.n, int64(n))
+	return n, err
+}
@@ -7,13 +7,26 @@ package api
 import (
 	"net/http"
 
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/gateway"
 	"github.com/ethersphere/bee/pkg/logging"
 	m "github.com/ethersphere/bee/pkg/metrics"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/resolver"
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/tracing"
 )
 
+const (
+	// SwarmPostageBatchIdHeader is the HTTP header that holds the hex
+	// encoded ID of the postage batch used to stamp uploaded chunks.
+	SwarmPostageBatchIdHeader = "Swarm-Postage-Batch-Id"
+)
+
 type Service interface {
 	http.Handler
 	m.Collector
@@ -28,6 +41,20 @@ type server struct {
 type Options struct {
 	Pingpong pingpong.Interface
 	Storer   storage.Storer
+	Stamper  postage.Stamper
+	Tags     *tags.Tags
+	// ChunkValidator validates chunks uploaded with their addresses,
+	// content addressed chunks are validated if it is not set.
+	ChunkValidator swarm.ChunkValidator
+	// Auth restricts access to routes by scopes of bearer
+	// tokens, access is not restricted if it is not set.
+	Auth *auth.Authenticator
+	// Gateway limits access of clients to the API.
+	Gateway *gateway.Gateway
+	// Resolver resolves names of content served by name and by
+	// the Host header, content is served only by reference if
+	// it is not set.
+	Resolver resolver.Interface
 	Logger   logging.Logger
 	Tracer   *tracing.Tracer
 }
@@ -37,6 +64,12 @@ func New(o Options) Service {
 		Options: o,
 		metrics: newMetrics(),
 	}
+	if s.Tags == nil {
+		s.Tags = tags.NewTags()
+	}
+	if s.ChunkValidator == nil {
+		s.ChunkValidator = swarm.NewMultiValidator(file.NewContentAddressValidator())
+	}
 
 	s.setupRouting()
 
@@ -12,22 +12,42 @@ import (
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/gateway"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/resolver"
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
 	"resenje.org/web"
 )
 
 type testServerOptions struct {
 	Pingpong pingpong.Interface
 	Storer   storage.Storer
+	Stamper  postage.Stamper
+	Tags     *tags.Tags
+	// ChunkValidator validates uploaded chunks,
+	// content addressed if it is not set
+	ChunkValidator swarm.ChunkValidator
+	Auth           *auth.Authenticator
+	Gateway        *gateway.Gateway
+	Resolver       resolver.Interface
 }
 
 func newTestServer(t *testing.T, o testServerOptions) (client *http.Client, cleanup func()) {
 	s := api.New(api.Options{
-		Pingpong: o.Pingpong,
-		Storer:   o.Storer,
-		Logger:   logging.New(ioutil.Discard, 0),
+		Pingpong:       o.Pingpong,
+		Storer:         o.Storer,
+		Stamper:        o.Stamper,
+		Tags:           o.Tags,
+		ChunkValidator: o.ChunkValidator,
+		Auth:           o.Auth,
+		Gateway:        o.Gateway,
+		Resolver:       o.Resolver,
+		Logger:         logging.New(ioutil.Discard, 0),
 	})
 	ts := httptest.NewServer(s)
 	cleanup = ts.Close
@@ -0,0 +1,132 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+type claimsKey struct{}
+
+type AuthRequest struct {
+	Secret string   `json:"secret"`
+	Scopes []string `json:"scopes"`
+	// Expiry is the token validity in seconds.
+	Expiry int64 `json:"expiry"`
+}
+
+type AuthResponse struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// authHandler issues a token with requested scopes
+// in exchange for the admin secret.
+func (s *server) authHandler(w http.ResponseWriter, r *http.Request) {
+	var req AuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.Logger.Debugf("auth: unmarshal request: %v", err)
+		s.Logger.Error("auth: unmarshal request")
+		jsonhttp.BadRequest(w, "invalid request")
+		return
+	}
+	scopes, err := auth.ParseScopes(req.Scopes)
+	if err != nil {
+		s.Logger.Debugf("auth: parse scopes: %v", err)
+		jsonhttp.BadRequest(w, "unknown scope")
+		return
+	}
+
+	token, expires, err := s.Auth.Authorize(req.Secret, scopes, time.Duration(req.Expiry)*time.Second)
+	if err != nil {
+		s.Logger.Debugf("auth: authorize: %v", err)
+		switch {
+		case errors.Is(err, auth.ErrInvalidSecret):
+			s.Logger.Error("auth: invalid secret")
+			jsonhttp.Unauthorized(w, "invalid secret")
+		case errors.Is(err, auth.ErrInvalidExpiry):
+			jsonhttp.BadRequest(w, "invalid expiry")
+		case errors.Is(err, auth.ErrUnknownScope):
+			jsonhttp.BadRequest(w, "unknown scope")
+		default:
+			s.Logger.Error("auth: authorize")
+			jsonhttp.InternalServerError(w, "cannot issue token")
+		}
+		return
+	}
+
+	jsonhttp.Created(w, AuthResponse{
+		Token:   token,
+		Expires: expires,
+	})
+}
+
+// restricted returns a handler that serves only requests with a bearer
+// token that grants the scope, unless authentication is disabled or the
+// scope is public. Claims of a valid token are stored in the request
+// context.
+func (s *server) restricted(scope auth.Scope, h http.HandlerFunc) http.Handler {
+	if s.Auth == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, hasToken := bearerToken(r)
+		if hasToken {
+			claims, err := s.Auth.Verify(token)
+			if err != nil {
+				s.Logger.Debugf("auth: verify token: %v", err)
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				if errors.Is(err, auth.ErrTokenExpired) {
+					jsonhttp.Unauthorized(w, "token expired")
+				} else {
+					jsonhttp.Unauthorized(w, "invalid token")
+				}
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims))
+		}
+
+		if !s.allowed(r, scope) {
+			if !hasToken {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				jsonhttp.Unauthorized(w, nil)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+			jsonhttp.Forbidden(w, "insufficient scope")
+			return
+		}
+
+		h(w, r)
+	})
+}
+
+// allowed returns true if the request is granted the scope, either
+// publicly or by the token verified by the restricted handler.
+func (s *server) allowed(r *http.Request, scope auth.Scope) bool {
+	if s.Auth == nil || s.Auth.Public(scope) {
+		return true
+	}
+	claims, ok := r.Context().Value(claimsKey{}).(*auth.Claims)
+	return ok && claims.Allows(scope)
+}
+
+// bearerToken returns the token from the Authorization header.
+func bearerToken(r *http.Request) (token string, ok bool) {
+	const prefix = "bearer "
+	v := r.Header.Get("Authorization")
+	if len(v) < len(prefix) || !strings.EqualFold(v[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(v[len(prefix):]), true
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+)
+
+func TestAuth(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.New(key, &auth.Options{
+		PublicScopes: []auth.Scope{auth.ScopeRead},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(),
+		Auth:   authenticator,
+	})
+	defer cleanup()
+
+	issue := func(t *testing.T, scopes ...string) string {
+		t.Helper()
+
+		var resp api.AuthResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/auth", jsonBody(t, api.AuthRequest{
+			Secret: auth.AdminSecret(key),
+			Scopes: scopes,
+		}), http.StatusCreated, &resp)
+		return resp.Token
+	}
+	bearer := func(token string) http.Header {
+		return http.Header{"Authorization": []string{"Bearer " + token}}
+	}
+
+	chunk := chunktesting.GenerateValidRandomChunk()
+	resource := "/bzz-chunk/" + chunk.Address().String()
+
+	t.Run("no token", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource, bytes.NewReader(chunk.Data()), http.StatusUnauthorized, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusUnauthorized),
+			Code:    http.StatusUnauthorized,
+		})
+	})
+
+	t.Run("invalid secret", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/auth", jsonBody(t, api.AuthRequest{
+			Secret: "secret",
+		}), http.StatusUnauthorized, jsonhttp.StatusResponse{
+			Message: "invalid secret",
+			Code:    http.StatusUnauthorized,
+		})
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/auth", jsonBody(t, api.AuthRequest{
+			Secret: auth.AdminSecret(key),
+			Scopes: []string{"admin"},
+		}), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "unknown scope",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, resource, bytes.NewReader(chunk.Data()), http.StatusUnauthorized, jsonhttp.StatusResponse{
+			Message: "invalid token",
+			Code:    http.StatusUnauthorized,
+		}, bearer("token"))
+	})
+
+	t.Run("insufficient scope", func(t *testing.T) {
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, resource, bytes.NewReader(chunk.Data()), http.StatusForbidden, jsonhttp.StatusResponse{
+			Message: "insufficient scope",
+			Code:    http.StatusForbidden,
+		}, bearer(issue(t, "tags")))
+	})
+
+	t.Run("upload without pin scope", func(t *testing.T) {
+		headers := bearer(issue(t, "upload"))
+		headers.Set(api.SwarmPinHeader, "true")
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, resource, bytes.NewReader(chunk.Data()), http.StatusForbidden, jsonhttp.StatusResponse{
+			Message: "pinning not allowed",
+			Code:    http.StatusForbidden,
+		}, headers)
+	})
+
+	t.Run("upload and public read", func(t *testing.T) {
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, resource, bytes.NewReader(chunk.Data()), http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		}, bearer(issue(t, "upload")))
+
+		resp, err := client.Get(resource)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("got status %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+	})
+
+	t.Run("pin", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/pin/chunks", nil, http.StatusUnauthorized, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusUnauthorized),
+			Code:    http.StatusUnauthorized,
+		})
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, "/pin/chunks/"+chunk.Address().String(), nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		}, bearer(issue(t, "pin")))
+	})
+}
+
+func jsonBody(t *testing.T, v interface{}) *bytes.Reader {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(b)
+}
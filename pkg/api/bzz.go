@@ -0,0 +1,159 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+// bzzHandler serves data of the chunk tree with the root reference
+// which is either provided in hex or resolved from the name. The name
+// is the host from the context for requests routed by the Host header.
+// As there are no manifests, only the root path can be served.
+func (s *server) bzzHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	name, path := mux.Vars(r)["name"], mux.Vars(r)["path"]
+	if name == "" {
+		name, path = sctx.GetHost(ctx), strings.TrimPrefix(r.URL.Path, "/")
+	}
+
+	ref, resolved, err := s.resolveReference(r, name)
+	if err != nil {
+		if errors.Is(err, resolver.ErrNotFound) {
+			jsonhttp.NotFound(w, "name not found")
+			return
+		}
+		s.Logger.Debugf("bzz: resolve name %s: %v", name, err)
+		s.Logger.Error("bzz: resolve name")
+		jsonhttp.BadGateway(w, "cannot resolve name")
+		return
+	}
+	if path != "" {
+		jsonhttp.NotFound(w, "path not found")
+		return
+	}
+
+	etag := strconv.Quote(ref.String())
+	if !resolved && etagMatches(r, etag) {
+		setImmutableContentHeaders(w, etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	reader, err := file.NewReader(ctx, s.Storer, ref)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.Logger.Trace("bzz: chunk not found. addr %s", ref)
+			jsonhttp.NotFound(w, "not found")
+			return
+		}
+		s.Logger.Debugf("bzz: read %s: %v", ref, err)
+		s.Logger.Error("bzz: read error")
+		jsonhttp.InternalServerError(w, "read error")
+		return
+	}
+
+	if resolved {
+		// names can be changed to reference other content
+		w.Header().Set("ETag", etag)
+	} else {
+		setImmutableContentHeaders(w, etag)
+	}
+	// the content type is detected from the data
+	http.ServeContent(w, r, "", time.Time{}, &contentReader{
+		SectionReader: io.NewSectionReader(reader, 0, reader.Size()),
+		s:             s,
+		ref:           ref,
+	})
+}
+
+// resolveReference returns the reference from the name, which is
+// resolved if it is not a hex encoded reference.
+func (s *server) resolveReference(r *http.Request, name string) (ref swarm.Address, resolved bool, err error) {
+	if ref, err := swarm.ParseHexAddress(name); err == nil && len(ref.Bytes()) == swarm.HashSize {
+		return ref, false, nil
+	}
+	if s.Resolver == nil {
+		return swarm.ZeroAddress, false, resolver.ErrNotFound
+	}
+	ref, err = s.Resolver.Resolve(r.Context(), name)
+	return ref, true, err
+}
+
+// contentReader logs errors of reading the content after
+// http.ServeContent has started the response.
+type contentReader struct {
+	*io.SectionReader
+	s   *server
+	ref swarm.Address
+}
+
+func (r *contentReader) Read(p []byte) (int, error) {
+	n, err := r.SectionReader.Read(p)
+	if err != nil && err != io.EOF {
+		r.s.Logger.Debugf("bzz: read %s: %v", r.ref, err)
+		r.s.Logger.Error("bzz: read error")
+	}
+	return n, err
+}
+
+// hostHandler routes requests by the Host header to the content of the
+// name resolved from the host, by setting the host in the request
+// context. Requests for hosts that are not resolved are served by the
+// API.
+func (s *server) hostHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			h.ServeHTTP(w, r)
+			return
+		}
+		host := requestHost(r)
+		if host == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		// errors other than unknown names are returned by the
+		// bzz handler, which resolves the host again
+		if _, err := s.Resolver.Resolve(r.Context(), host); errors.Is(err, resolver.ErrNotFound) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(sctx.SetHost(r.Context(), host)))
+	})
+}
+
+// requestHost returns the host from the Host header if it can be
+// a resolvable name, without the port.
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if !strings.Contains(host, ".") || net.ParseIP(host) != nil {
+		return ""
+	}
+	return host
+}
+
+// hostMatcher matches requests routed by the Host header.
+func hostMatcher(r *http.Request, _ *mux.RouteMatch) bool {
+	return sctx.GetHost(r.Context()) != ""
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/resolver/static"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestBzz retrieves content by reference, by name
+// and by the Host header.
+func TestBzz(t *testing.T) {
+	content := []byte("<!DOCTYPE html><title>docs</title>")
+	data := make([]byte, file.SpanSize+len(content))
+	binary.LittleEndian.PutUint64(data, uint64(len(content)))
+	copy(data[file.SpanSize:], content)
+	addr, err := file.ContentAddress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storer := mock.NewStorer()
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, swarm.NewChunk(addr, data)); err != nil {
+		t.Fatal(err)
+	}
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer: storer,
+		Resolver: static.New(map[string]swarm.Address{
+			"docs.example.eth": addr,
+		}),
+	})
+	defer cleanup()
+
+	get := func(t *testing.T, resource, host string, responseCode int) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodGet, resource, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Host = host
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != responseCode {
+			t.Fatalf("got response status %s, want %v %s", resp.Status, responseCode, http.StatusText(responseCode))
+		}
+		if responseCode == http.StatusOK {
+			got, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Fatalf("got content %q, want %q", got, content)
+			}
+			if got := resp.Header.Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("got content type %q", got)
+			}
+		}
+		return resp
+	}
+	etag := strconv.Quote(addr.String())
+
+	t.Run("reference", func(t *testing.T) {
+		resp := get(t, "/bzz/"+addr.String(), "", http.StatusOK)
+		if got := resp.Header.Get("ETag"); got != etag {
+			t.Errorf("got etag %q, want %q", got, etag)
+		}
+		if got := resp.Header.Get("Cache-Control"); got != "public, max-age=31536000, immutable" {
+			t.Errorf("got cache control %q", got)
+		}
+	})
+
+	t.Run("name", func(t *testing.T) {
+		resp := get(t, "/bzz/docs.example.eth/", "", http.StatusOK)
+		if got := resp.Header.Get("ETag"); got != etag {
+			t.Errorf("got etag %q, want %q", got, etag)
+		}
+		// resolved content may change
+		if got := resp.Header.Get("Cache-Control"); got != "" {
+			t.Errorf("got cache control %q", got)
+		}
+	})
+
+	t.Run("host", func(t *testing.T) {
+		get(t, "/", "docs.example.eth:1633", http.StatusOK)
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/bzz/unknown.eth", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "name not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("unknown host", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Host = "unknown.eth"
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		got, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the api is served
+		if string(got) != "Ethereum Swarm Bee\n" {
+			t.Errorf("got body %q", got)
+		}
+	})
+
+	t.Run("path", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/bzz/docs.example.eth/index.html", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "path not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/bzz/"+swarm.MustParseHexAddress("aabbccddeeff00112233445566778899aabbccddeeff00112233445566778899").String(), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+}
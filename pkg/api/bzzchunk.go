@@ -6,20 +6,33 @@ package api
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
-	"io"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/gorilla/mux"
 )
 
+type ChunkAddressResponse struct {
+	Reference swarm.Address `json:"reference"`
+}
+
+// chunkUploadHandler stores the chunk under the address from the
+// request path, if it is validated by the chunk validator.
 func (s *server) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 	addr := mux.Vars(r)["addr"]
-	ctx := r.Context()
 
 	address, err := swarm.ParseHexAddress(addr)
 	if err != nil {
@@ -38,17 +51,172 @@ func (s *server) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 
 	}
 
-	_, err = s.Storer.Put(ctx, storage.ModePutUpload, swarm.NewChunk(address, data))
+	chunk := swarm.NewChunk(address, data)
+	if !s.ChunkValidator.Validate(chunk) {
+		s.Logger.Debugf("bzz-chunk: invalid chunk, addr %s", address)
+		s.Logger.Error("bzz-chunk: invalid chunk")
+		jsonhttp.BadRequest(w, "invalid chunk")
+		return
+	}
+
+	if !s.storeChunk(w, r, chunk) {
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+// chunkUploadContentHandler stores the chunk under the content address
+// computed from its span prefixed data.
+func (s *server) chunkUploadContentHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.Logger.Debugf("bzz-chunk: read chunk data error: %v", err)
+		s.Logger.Error("bzz-chunk: read chunk data error")
+		jsonhttp.InternalServerError(w, "cannot read chunk data")
+		return
+	}
+
+	address, err := file.ContentAddress(data)
+	if err != nil {
+		s.Logger.Debugf("bzz-chunk: content address: %v", err)
+		s.Logger.Error("bzz-chunk: invalid chunk data")
+		jsonhttp.BadRequest(w, "invalid chunk data")
+		return
+	}
+
+	if !s.storeChunk(w, r, swarm.NewChunk(address, data)) {
+		return
+	}
+
+	jsonhttp.Created(w, ChunkAddressResponse{
+		Reference: address,
+	})
+}
+
+// storeChunk binds the chunk to the tag and stamps it with the postage
+// batch from request headers, stores and optionally pins it. It writes
+// the error response and returns false if the chunk is not stored.
+func (s *server) storeChunk(w http.ResponseWriter, r *http.Request, chunk swarm.Chunk) (ok bool) {
+	ctx := r.Context()
+	address := chunk.Address()
+
+	tag, err := s.requestTag(r)
+	if err != nil {
+		s.Logger.Debugf("bzz-chunk: get tag: %v, addr %s", err, address)
+		s.Logger.Error("bzz-chunk: get tag error")
+		respondTagError(w, err)
+		return false
+	}
+	if tag != nil {
+		ctx = sctx.SetTag(ctx, tag.Uid)
+		chunk = chunk.WithTagID(tag.Uid)
+	}
+
+	pin := requestPin(r)
+	if pin && !s.allowed(r, auth.ScopePin) {
+		jsonhttp.Forbidden(w, "pinning not allowed")
+		return false
+	}
+
+	// the chunk is stamped after the request is checked and right
+	// before it is stored, not to use up the batch for rejected chunks
+	if s.Stamper != nil {
+		batchID, err := requestPostageBatchID(r)
+		if err != nil {
+			s.Logger.Debugf("bzz-chunk: parse postage batch id %q: %v, addr %s", r.Header.Get(SwarmPostageBatchIdHeader), err, address)
+			s.Logger.Error("bzz-chunk: invalid postage batch id")
+			jsonhttp.BadRequest(w, "invalid postage batch id")
+			return false
+		}
+		stamp, err := s.Stamper.Stamp(batchID, address)
+		if err != nil {
+			s.Logger.Debugf("bzz-chunk: stamp chunk: %v, addr %s", err, address)
+			code, message := stampErrorStatus(err)
+			s.Logger.Errorf("bzz-chunk: %s", message)
+			jsonhttp.Respond(w, code, message)
+			return false
+		}
+		chunk = chunk.WithStamp(stamp)
+	}
+
+	_, err = s.Storer.Put(ctx, storage.ModePutUpload, chunk)
 	if err != nil {
 		s.Logger.Debugf("bzz-chunk: chunk write error: %v, addr %s", err, address)
 		s.Logger.Error("bzz-chunk: chunk write error")
+		s.releaseStamps(chunk)
 		jsonhttp.BadRequest(w, "chunk write error")
+		return false
+	}
+
+	if pin {
+		if err := s.Storer.Set(ctx, storage.ModeSetPin, address); err != nil {
+			s.Logger.Debugf("bzz-chunk: pin chunk error: %v, addr %s", err, address)
+			s.Logger.Error("bzz-chunk: pin chunk error")
+			jsonhttp.InternalServerError(w, "cannot pin chunk")
+			return false
+		}
+	}
+
+	if tag != nil {
+		// the chunk is already split by the client
+		tag.Inc(tags.StateSplit)
+		w.Header().Set(SwarmTagHeader, strconv.FormatUint(uint64(tag.Uid), 10))
+	}
+	return true
+}
+
+// releaseStamps returns indexes of stamps of chunks that are not stored
+// to their batches. Chunks are released in reverse order, as the stamper
+// releases only the last issued index of a batch.
+func (s *server) releaseStamps(chunks ...swarm.Chunk) {
+	if s.Stamper == nil {
 		return
 	}
+	for i := len(chunks) - 1; i >= 0; i-- {
+		stamp, ok := chunks[i].Stamp().(*postage.Stamp)
+		if !ok || stamp == nil {
+			continue
+		}
+		if err := s.Stamper.Release(stamp); err != nil {
+			s.Logger.Debugf("release stamp: %v, addr %s", err, chunks[i].Address())
+			s.Logger.Error("release stamp")
+		}
+	}
+}
 
-	jsonhttp.OK(w, nil)
+// requestPostageBatchID returns the postage batch id
+// from the Swarm-Postage-Batch-Id header.
+func requestPostageBatchID(r *http.Request) ([]byte, error) {
+	batchID, err := hex.DecodeString(r.Header.Get(SwarmPostageBatchIdHeader))
+	if err != nil {
+		return nil, err
+	}
+	if len(batchID) != postage.BatchIDSize {
+		return nil, fmt.Errorf("invalid length %v", len(batchID))
+	}
+	return batchID, nil
 }
 
+// stampErrorStatus returns the HTTP status code and the
+// message for the error returned by the stamper.
+func stampErrorStatus(err error) (code int, message string) {
+	switch {
+	case errors.Is(err, postage.ErrNotFound):
+		return http.StatusBadRequest, "postage batch not found"
+	case errors.Is(err, postage.ErrOwnerMismatch):
+		return http.StatusBadRequest, "postage batch not owned by node"
+	case errors.Is(err, postage.ErrBatchFull):
+		return http.StatusPaymentRequired, "postage batch is full"
+	default:
+		return http.StatusInternalServerError, "stamp chunk error"
+	}
+}
+
+// chunkGetHandler serves chunk data. As the data addressed by its
+// content never changes, it is served with caching headers for
+// immutable content, and conditional, range and HEAD requests are
+// supported.
 func (s *server) chunkGetHandler(w http.ResponseWriter, r *http.Request) {
 	addr := mux.Vars(r)["addr"]
 	ctx := r.Context()
@@ -61,6 +229,14 @@ func (s *server) chunkGetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	etag := strconv.Quote(address.String())
+	if etagMatches(r, etag) {
+		// the client already has the data, it is not retrieved
+		setImmutableContentHeaders(w, etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	chunk, err := s.Storer.Get(ctx, storage.ModeGetRequest, address)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
@@ -74,6 +250,8 @@ func (s *server) chunkGetHandler(w http.ResponseWriter, r *http.Request) {
 		jsonhttp.InternalServerError(w, "chunk read error")
 		return
 	}
-	w.Header().Set("Content-Type", "binary/octet-stream")
-	_, _ = io.Copy(w, bytes.NewReader(chunk.Data()))
+
+	setImmutableContentHeaders(w, etag)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(chunk.Data()))
 }
@@ -6,14 +6,24 @@ package api_test
 
 import (
 	"bytes"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	mockstate "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
@@ -51,6 +61,8 @@ func TestChunkUploadDownload(t *testing.T) {
 	mockValidatingStorer := mock.NewValidatingStorer(validatorF)
 	client, cleanup := newTestServer(t, testServerOptions{
 		Storer: mockValidatingStorer,
+		// chunks are validated by the storer
+		ChunkValidator: swarm.NewMultiValidator(acceptValidator{}),
 	})
 	defer cleanup()
 
@@ -93,6 +105,262 @@ func TestChunkUploadDownload(t *testing.T) {
 	})
 }
 
+// TestChunkUploadPostage uploads chunks to an API that stamps them with
+// a postage batch provided in the request header.
+func TestChunkUploadPostage(t *testing.T) {
+	resource := func(addr swarm.Address) string {
+		return "/bzz-chunk/" + addr.String()
+	}
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchStore := batchstore.New(mockstate.NewStateStore())
+	batchID := bytes.Repeat([]byte{0x01}, postage.BatchIDSize)
+	// a batch of depth zero can stamp only one chunk
+	if err := batchStore.Create(batchID, owner, big.NewInt(1), 0); err != nil {
+		t.Fatal(err)
+	}
+	// uploads are public, pinning is not
+	authenticator, err := auth.New(key, &auth.Options{
+		PublicScopes: []auth.Scope{auth.ScopeUpload},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the storer fails to store this chunk
+	rejected := chunktesting.GenerateValidRandomChunk()
+	storer := mock.NewValidatingStorer(func(addr swarm.Address, _ []byte) bool {
+		return !addr.Equal(rejected.Address())
+	})
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer:  storer,
+		Stamper: postage.NewStamper(batchStore, mockstate.NewStateStore(), signer),
+		Auth:    authenticator,
+	})
+	defer cleanup()
+
+	batchHeader := func(id []byte) http.Header {
+		return http.Header{
+			api.SwarmPostageBatchIdHeader: []string{hex.EncodeToString(id)},
+		}
+	}
+
+	t.Run("missing batch id", func(t *testing.T) {
+		ch := chunktesting.GenerateValidRandomChunk()
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource(ch.Address()), bytes.NewReader(ch.Data()), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid postage batch id",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("unknown batch", func(t *testing.T) {
+		ch := chunktesting.GenerateValidRandomChunk()
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, resource(ch.Address()), bytes.NewReader(ch.Data()), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "postage batch not found",
+			Code:    http.StatusBadRequest,
+		}, batchHeader(bytes.Repeat([]byte{0x02}, postage.BatchIDSize)))
+	})
+
+	t.Run("pinning not allowed", func(t *testing.T) {
+		// the rejected chunk must not use up the only stamp of the batch
+		ch := chunktesting.GenerateValidRandomChunk()
+		headers := batchHeader(batchID)
+		headers.Set(api.SwarmPinHeader, "true")
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, resource(ch.Address()), bytes.NewReader(ch.Data()), http.StatusForbidden, jsonhttp.StatusResponse{
+			Message: "pinning not allowed",
+			Code:    http.StatusForbidden,
+		}, headers)
+	})
+
+	t.Run("chunk write error", func(t *testing.T) {
+		// the stamp of the chunk that is not stored is released
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, resource(rejected.Address()), bytes.NewReader(rejected.Data()), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "chunk write error",
+			Code:    http.StatusBadRequest,
+		}, batchHeader(batchID))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ch := chunktesting.GenerateValidRandomChunk()
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, resource(ch.Address()), bytes.NewReader(ch.Data()), http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		}, batchHeader(batchID))
+	})
+
+	t.Run("batch full", func(t *testing.T) {
+		ch := chunktesting.GenerateValidRandomChunk()
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, resource(ch.Address()), bytes.NewReader(ch.Data()), http.StatusPaymentRequired, jsonhttp.StatusResponse{
+			Message: "postage batch is full",
+			Code:    http.StatusPaymentRequired,
+		}, batchHeader(batchID))
+	})
+}
+
+// TestChunkUploadValidation uploads chunks to an API that validates
+// their content addresses, or computes them from the chunk data.
+func TestChunkUploadValidation(t *testing.T) {
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(),
+	})
+	defer cleanup()
+
+	ch := chunktesting.GenerateValidRandomChunk()
+
+	t.Run("address mismatch", func(t *testing.T) {
+		other := chunktesting.GenerateValidRandomChunk()
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/bzz-chunk/"+other.Address().String(), bytes.NewReader(ch.Data()), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid chunk",
+			Code:    http.StatusBadRequest,
+		})
+		_ = request(t, client, http.MethodGet, "/bzz-chunk/"+other.Address().String(), nil, http.StatusNotFound)
+	})
+
+	t.Run("short data", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/bzz-chunk/"+ch.Address().String(), bytes.NewReader(ch.Data()[:4]), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid chunk",
+			Code:    http.StatusBadRequest,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/bzz-chunk", bytes.NewReader(ch.Data()[:4]), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid chunk data",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("computed address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/bzz-chunk", bytes.NewReader(ch.Data()), http.StatusCreated, api.ChunkAddressResponse{
+			Reference: ch.Address(),
+		})
+
+		resp := request(t, client, http.MethodGet, "/bzz-chunk/"+ch.Address().String(), nil, http.StatusOK)
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ch.Data(), data) {
+			t.Fatal("data retrieved doesnt match uploaded content")
+		}
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ch := chunktesting.GenerateValidRandomChunk()
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/bzz-chunk/"+ch.Address().String(), bytes.NewReader(ch.Data()), http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		})
+	})
+}
+
+// TestChunkCaching retrieves a chunk with conditional,
+// HEAD and range requests.
+func TestChunkCaching(t *testing.T) {
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(),
+	})
+	defer cleanup()
+
+	ch := chunktesting.GenerateValidRandomChunk()
+	resource := "/bzz-chunk/" + ch.Address().String()
+	etag := `"` + ch.Address().String() + `"`
+
+	jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource, bytes.NewReader(ch.Data()), http.StatusOK, jsonhttp.StatusResponse{
+		Message: http.StatusText(http.StatusOK),
+		Code:    http.StatusOK,
+	})
+
+	do := func(t *testing.T, method string, header http.Header, responseCode int) (*http.Response, []byte) {
+		t.Helper()
+
+		req, err := http.NewRequest(method, resource, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header = header
+		// content is not compressed even if the client accepts it
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != responseCode {
+			t.Fatalf("got response status %s, want %v %s", resp.Status, responseCode, http.StatusText(responseCode))
+		}
+		if got := resp.Header.Get("ETag"); got != etag {
+			t.Errorf("got etag %q, want %q", got, etag)
+		}
+		if got := resp.Header.Get("Cache-Control"); got != "public, max-age=31536000, immutable" {
+			t.Errorf("got cache control %q", got)
+		}
+		if got := resp.Header.Get("Content-Encoding"); got != "" {
+			t.Errorf("got content encoding %q", got)
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, data
+	}
+
+	t.Run("get", func(t *testing.T) {
+		_, data := do(t, http.MethodGet, http.Header{}, http.StatusOK)
+		if !bytes.Equal(data, ch.Data()) {
+			t.Fatal("data retrieved doesnt match uploaded content")
+		}
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		for _, v := range []string{etag, "W/" + etag, `"other", ` + etag} {
+			_, data := do(t, http.MethodGet, http.Header{"If-None-Match": {v}}, http.StatusNotModified)
+			if len(data) != 0 {
+				t.Errorf("%s: got %v bytes of body", v, len(data))
+			}
+		}
+	})
+
+	t.Run("head", func(t *testing.T) {
+		resp, data := do(t, http.MethodHead, http.Header{}, http.StatusOK)
+		if resp.ContentLength != int64(len(ch.Data())) {
+			t.Errorf("got content length %v, want %v", resp.ContentLength, len(ch.Data()))
+		}
+		if len(data) != 0 {
+			t.Errorf("got %v bytes of body", len(data))
+		}
+	})
+
+	t.Run("range", func(t *testing.T) {
+		resp, data := do(t, http.MethodGet, http.Header{"Range": {"bytes=8-15"}}, http.StatusPartialContent)
+		if !bytes.Equal(data, ch.Data()[8:16]) {
+			t.Fatal("data retrieved doesnt match the requested range")
+		}
+		want := fmt.Sprintf("bytes 8-15/%d", len(ch.Data()))
+		if got := resp.Header.Get("Content-Range"); got != want {
+			t.Errorf("got content range %q, want %q", got, want)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		other := chunktesting.GenerateValidRandomChunk()
+		resp := request(t, client, http.MethodGet, "/bzz-chunk/"+other.Address().String(), nil, http.StatusNotFound)
+		defer resp.Body.Close()
+		if got := resp.Header.Get("Cache-Control"); got != "" {
+			t.Errorf("got cache control %q", got)
+		}
+	})
+}
+
+// acceptValidator validates all chunks.
+type acceptValidator struct{}
+
+func (acceptValidator) Validate(swarm.Chunk) bool { return true }
+
 func request(t *testing.T, client *http.Client, method string, resource string, body io.Reader, responseCode int) *http.Response {
 	t.Helper()
 
@@ -0,0 +1,374 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// bulkBatchSize is the maximal number of chunks that
+	// are stored or retrieved from the storer at once.
+	bulkBatchSize = 128
+	// bulkDownloadMaxChunks is the maximal number of
+	// chunks requested in a single bulk download.
+	bulkDownloadMaxChunks = 1 << 16
+	// chunkFrameLengthSize is the size of the data length
+	// prefix of chunks in the bulk download response.
+	chunkFrameLengthSize = 4
+)
+
+var chunkStreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  file.SpanSize + swarm.ChunkSize,
+	WriteBufferSize: swarm.ChunkSize,
+}
+
+// chunkStreamError terminates the chunk stream
+// with a close message with the code and the text.
+type chunkStreamError struct {
+	code int
+	text string
+}
+
+func (e *chunkStreamError) Error() string {
+	return e.text
+}
+
+// chunkStreamMessage is a single message read from the chunk stream.
+type chunkStreamMessage struct {
+	data []byte
+	err  error
+}
+
+// chunkStreamUpload holds the options from the upgrade
+// request headers that apply to all streamed chunks.
+type chunkStreamUpload struct {
+	tag     *tags.Tag
+	batchID []byte
+	pin     bool
+}
+
+// chunksUploadStreamHandler stores content addressed chunks received
+// over a WebSocket connection. Every binary message holds the span
+// prefixed data of a single chunk. Chunks are stored in batches and
+// each one is acknowledged, in the order it is received, by a binary
+// message with its address. The connection is closed with an error on
+// the first invalid chunk, after all preceding chunks are stored. Tag,
+// postage batch and pin headers of the upgrade request apply to all
+// chunks in the stream.
+func (s *server) chunksUploadStreamHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		u   chunkStreamUpload
+		err error
+	)
+	u.tag, err = s.requestTag(r)
+	if err != nil {
+		s.Logger.Debugf("bzz-chunks: get tag: %v", err)
+		s.Logger.Error("bzz-chunks: get tag error")
+		respondTagError(w, err)
+		return
+	}
+	if s.Stamper != nil {
+		u.batchID, err = requestPostageBatchID(r)
+		if err != nil {
+			s.Logger.Debugf("bzz-chunks: parse postage batch id %q: %v", r.Header.Get(SwarmPostageBatchIdHeader), err)
+			s.Logger.Error("bzz-chunks: invalid postage batch id")
+			jsonhttp.BadRequest(w, "invalid postage batch id")
+			return
+		}
+	}
+	u.pin = requestPin(r)
+	if u.pin && !s.allowed(r, auth.ScopePin) {
+		jsonhttp.Forbidden(w, "pinning not allowed")
+		return
+	}
+
+	conn, err := chunkStreamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already responded with an error
+		s.Logger.Debugf("bzz-chunks: upgrade connection: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(file.SpanSize + swarm.ChunkSize)
+
+	ctx := r.Context()
+	if u.tag != nil {
+		ctx = sctx.SetTag(ctx, u.tag.Uid)
+	}
+
+	quit := make(chan struct{})
+	defer close(quit)
+	messages := make(chan chunkStreamMessage, bulkBatchSize)
+	go readChunkStream(conn, messages, quit)
+
+	batch := make([]swarm.Chunk, 0, bulkBatchSize)
+	for {
+		chunks, streamErr := nextChunkBatch(messages, batch[:0])
+		if len(chunks) > 0 {
+			if err := s.storeChunkStreamBatch(ctx, u, chunks); err != nil {
+				streamErr = err
+			} else {
+				for _, ch := range chunks {
+					if err := conn.WriteMessage(websocket.BinaryMessage, ch.Address().Bytes()); err != nil {
+						s.Logger.Debugf("bzz-chunks: write acknowledgement: %v", err)
+						return
+					}
+				}
+			}
+		}
+		if streamErr == nil {
+			continue
+		}
+
+		var e *chunkStreamError
+		switch {
+		case errors.Is(streamErr, io.EOF):
+			// the stream is closed by the client
+		case errors.As(streamErr, &e):
+			msg := websocket.FormatCloseMessage(e.code, e.text)
+			if err := conn.WriteMessage(websocket.CloseMessage, msg); err != nil {
+				s.Logger.Debugf("bzz-chunks: write close message: %v", err)
+			}
+		default:
+			s.Logger.Debugf("bzz-chunks: read message: %v", streamErr)
+		}
+		return
+	}
+}
+
+// readChunkStream sends messages read from the connection until the
+// first error, which is io.EOF if the client closed the stream.
+func readChunkStream(conn *websocket.Conn, messages chan<- chunkStreamMessage, quit <-chan struct{}) {
+	for {
+		var m chunkStreamMessage
+		typ, data, err := conn.ReadMessage()
+		switch {
+		case err != nil:
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				err = io.EOF
+			}
+			m.err = err
+		case typ != websocket.BinaryMessage:
+			m.err = &chunkStreamError{
+				code: websocket.CloseUnsupportedData,
+				text: "binary message expected",
+			}
+		default:
+			m.data = data
+		}
+
+		select {
+		case messages <- m:
+		case <-quit:
+			return
+		}
+		if m.err != nil {
+			return
+		}
+	}
+}
+
+// nextChunkBatch waits for a message and appends chunks from it and
+// from messages that are already received to the batch, up to the
+// batch size. The error that terminates the stream is returned
+// together with the chunks that precede it.
+func nextChunkBatch(messages <-chan chunkStreamMessage, batch []swarm.Chunk) ([]swarm.Chunk, error) {
+	m := <-messages
+	for {
+		if m.err != nil {
+			return batch, m.err
+		}
+		address, err := file.ContentAddress(m.data)
+		if err != nil {
+			return batch, &chunkStreamError{
+				code: websocket.CloseInvalidFramePayloadData,
+				text: "invalid chunk data",
+			}
+		}
+		batch = append(batch, swarm.NewChunk(address, m.data))
+		if len(batch) >= bulkBatchSize {
+			return batch, nil
+		}
+
+		select {
+		case m = <-messages:
+		default:
+			return batch, nil
+		}
+	}
+}
+
+// storeChunkStreamBatch binds chunks to the tag, stamps them with the
+// postage batch, and stores and optionally pins them. The pin scope is
+// checked before the stream is accepted, so that chunks are stamped
+// only right before they are stored. Stamps of chunks that are not
+// stored are released.
+func (s *server) storeChunkStreamBatch(ctx context.Context, u chunkStreamUpload, chunks []swarm.Chunk) error {
+	for i, ch := range chunks {
+		if u.tag != nil {
+			ch = ch.WithTagID(u.tag.Uid)
+		}
+		if s.Stamper != nil {
+			stamp, err := s.Stamper.Stamp(u.batchID, ch.Address())
+			if err != nil {
+				s.Logger.Debugf("bzz-chunks: stamp chunk: %v, addr %s", err, ch.Address())
+				s.releaseStamps(chunks[:i]...)
+				status, message := stampErrorStatus(err)
+				s.Logger.Errorf("bzz-chunks: %s", message)
+				code := websocket.ClosePolicyViolation
+				if status == http.StatusInternalServerError {
+					code = websocket.CloseInternalServerErr
+				}
+				return &chunkStreamError{code: code, text: message}
+			}
+			ch = ch.WithStamp(stamp)
+		}
+		chunks[i] = ch
+	}
+
+	if _, err := s.Storer.Put(ctx, storage.ModePutUpload, chunks...); err != nil {
+		s.Logger.Debugf("bzz-chunks: chunk write error: %v", err)
+		s.Logger.Error("bzz-chunks: chunk write error")
+		s.releaseStamps(chunks...)
+		return &chunkStreamError{
+			code: websocket.CloseInternalServerErr,
+			text: "chunk write error",
+		}
+	}
+
+	if u.pin {
+		addrs := make([]swarm.Address, len(chunks))
+		for i, ch := range chunks {
+			addrs[i] = ch.Address()
+		}
+		if err := s.Storer.Set(ctx, storage.ModeSetPin, addrs...); err != nil {
+			s.Logger.Debugf("bzz-chunks: pin chunks error: %v", err)
+			s.Logger.Error("bzz-chunks: pin chunks error")
+			return &chunkStreamError{
+				code: websocket.CloseInternalServerErr,
+				text: "cannot pin chunks",
+			}
+		}
+	}
+
+	if u.tag != nil {
+		// chunks are already split by the client
+		u.tag.IncN(tags.StateSplit, len(chunks))
+	}
+	return nil
+}
+
+// chunksDownloadHandler streams chunks with addresses from the request
+// body, which holds concatenated binary addresses. Chunks are written in
+// the requested order, each one as the length of its data, a 4 byte big
+// endian integer, followed by the data. The length is zero for chunks
+// that are not found. The response is truncated if chunks can not be
+// read after the streaming has started.
+func (s *server) chunksDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	maxSize := int64(bulkDownloadMaxChunks * swarm.HashSize)
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		s.Logger.Debugf("bzz-chunks: read request body: %v", err)
+		s.Logger.Error("bzz-chunks: read request body")
+		jsonhttp.InternalServerError(w, "cannot read request")
+		return
+	}
+	if int64(len(body)) > maxSize {
+		jsonhttp.BadRequest(w, "too many chunk addresses")
+		return
+	}
+	if len(body) == 0 || len(body)%swarm.HashSize != 0 {
+		jsonhttp.BadRequest(w, "invalid chunk addresses")
+		return
+	}
+
+	addrs := make([]swarm.Address, 0, len(body)/swarm.HashSize)
+	for i := 0; i < len(body); i += swarm.HashSize {
+		addrs = append(addrs, swarm.NewAddress(body[i:i+swarm.HashSize]))
+	}
+
+	ctx := r.Context()
+	flusher, _ := w.(http.Flusher)
+	length := make([]byte, chunkFrameLengthSize)
+	for written := false; len(addrs) > 0; written = true {
+		n := bulkBatchSize
+		if n > len(addrs) {
+			n = len(addrs)
+		}
+		chunks, err := s.getChunkBatch(ctx, addrs[:n])
+		if err != nil {
+			s.Logger.Debugf("bzz-chunks: chunk read error: %v", err)
+			s.Logger.Error("bzz-chunks: chunk read error")
+			if !written {
+				jsonhttp.InternalServerError(w, "chunk read error")
+			}
+			return
+		}
+		if !written {
+			w.Header().Set("Content-Type", "application/octet-stream")
+		}
+
+		for _, ch := range chunks {
+			var data []byte
+			if ch != nil {
+				data = ch.Data()
+			}
+			binary.BigEndian.PutUint32(length, uint32(len(data)))
+			if _, err := w.Write(length); err != nil {
+				s.Logger.Debugf("bzz-chunks: write response: %v", err)
+				return
+			}
+			if _, err := w.Write(data); err != nil {
+				s.Logger.Debugf("bzz-chunks: write response: %v", err)
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		addrs = addrs[n:]
+	}
+}
+
+// getChunkBatch returns chunks with provided addresses, in the same
+// order, with nil values for chunks that are not found.
+func (s *server) getChunkBatch(ctx context.Context, addrs []swarm.Address) ([]swarm.Chunk, error) {
+	chunks, err := s.Storer.GetMulti(ctx, storage.ModeGetRequest, addrs...)
+	if err == nil {
+		return chunks, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	// some chunks are missing, get them one by one
+	chunks = make([]swarm.Chunk, len(addrs))
+	for i, addr := range addrs {
+		ch, err := s.Storer.Get(ctx, storage.ModeGetRequest, addr)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		chunks[i] = ch
+	}
+	return chunks, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/gorilla/websocket"
+)
+
+func TestChunksUploadStream(t *testing.T) {
+	storer := mock.NewStorer()
+	tagg := tags.NewTags()
+	ts := httptest.NewServer(api.New(api.Options{
+		Storer: storer,
+		Tags:   tagg,
+		Logger: logging.New(ioutil.Discard, 0),
+	}))
+	defer ts.Close()
+
+	tag, err := tagg.Create("", 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/bzz-chunks/upload"
+	header := http.Header{}
+	header.Set(api.SwarmTagHeader, strconv.FormatUint(uint64(tag.Uid), 10))
+	header.Set(api.SwarmPinHeader, "true")
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	chunks := make([]swarm.Chunk, 10)
+	for i := range chunks {
+		chunks[i] = chunktesting.GenerateValidRandomChunk()
+		if err := conn.WriteMessage(websocket.BinaryMessage, chunks[i].Data()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// chunks are acknowledged in order
+	for _, ch := range chunks {
+		typ, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != websocket.BinaryMessage {
+			t.Fatalf("got message type %v, want %v", typ, websocket.BinaryMessage)
+		}
+		if got := swarm.NewAddress(msg); !got.Equal(ch.Address()) {
+			t.Fatalf("got address %s, want %s", got, ch.Address())
+		}
+	}
+
+	for _, ch := range chunks {
+		got, err := storer.Get(context.Background(), storage.ModeGetPin, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.PinCounter() != 1 {
+			t.Errorf("chunk %s: got pin counter %v, want 1", ch.Address(), got.PinCounter())
+		}
+	}
+	if got := tag.Get(tags.StateSplit); got != int64(len(chunks)) {
+		t.Errorf("got split count %v, want %v", got, len(chunks))
+	}
+
+	t.Run("invalid chunk", func(t *testing.T) {
+		if err := conn.WriteMessage(websocket.BinaryMessage, []byte("short")); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err := conn.ReadMessage()
+		if !websocket.IsCloseError(err, websocket.CloseInvalidFramePayloadData) {
+			t.Fatalf("got error %v, want close error %v", err, websocket.CloseInvalidFramePayloadData)
+		}
+	})
+}
+
+func TestChunksDownload(t *testing.T) {
+	storer := mock.NewStorer()
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer: storer,
+	})
+	defer cleanup()
+
+	chunks := []swarm.Chunk{
+		chunktesting.GenerateValidRandomChunk(),
+		chunktesting.GenerateValidRandomChunk(),
+	}
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	missing := chunktesting.GenerateValidRandomChunk()
+
+	download := func(t *testing.T, addrs ...swarm.Address) *http.Response {
+		t.Helper()
+
+		var body bytes.Buffer
+		for _, addr := range addrs {
+			body.Write(addr.Bytes())
+		}
+		resp, err := client.Post("/bzz-chunks/download", "application/octet-stream", &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		resp := download(t, chunks[0].Address(), missing.Address(), chunks[1].Address())
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+		for i, want := range [][]byte{chunks[0].Data(), nil, chunks[1].Data()} {
+			length := make([]byte, 4)
+			if _, err := io.ReadFull(resp.Body, length); err != nil {
+				t.Fatal(err)
+			}
+			data := make([]byte, binary.BigEndian.Uint32(length))
+			if _, err := io.ReadFull(resp.Body, data); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("chunk %v: got data %x, want %x", i, data, want)
+			}
+		}
+		if n, _ := io.Copy(ioutil.Discard, resp.Body); n != 0 {
+			t.Errorf("got %v unexpected bytes", n)
+		}
+	})
+
+	t.Run("invalid addresses", func(t *testing.T) {
+		resp := download(t, swarm.MustParseHexAddress("aabbcc"))
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("got status %v, want %v", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+}
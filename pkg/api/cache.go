@@ -0,0 +1,64 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/gorilla/handlers"
+)
+
+// immutableCacheControl allows caches to keep the content addressed
+// data for a year without revalidation.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// setImmutableContentHeaders sets caching headers for content that is
+// identified by the strong entity tag.
+func setImmutableContentHeaders(w http.ResponseWriter, etag string) {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", immutableCacheControl)
+}
+
+// etagMatches returns true if the If-None-Match request header contains
+// the entity tag, using the weak comparison that the header requires.
+func etagMatches(r *http.Request, etag string) bool {
+	v := r.Header.Get("If-None-Match")
+	if v == "" {
+		return false
+	}
+	for _, t := range strings.Split(v, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(t), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// isContentRequest returns true if the request
+// retrieves content addressed data.
+func isContentRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	return strings.HasPrefix(r.URL.Path, "/bzz-chunk/") ||
+		strings.HasPrefix(r.URL.Path, "/bzz/") ||
+		sctx.GetHost(r.Context()) != ""
+}
+
+// compressHandler compresses responses, except for content addressed
+// data, which is served with exact sizes and byte ranges that
+// compression would change, and which does not compress well.
+func compressHandler(h http.Handler) http.Handler {
+	compressed := handlers.CompressHandler(h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isContentRequest(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		compressed.ServeHTTP(w, r)
+	})
+}
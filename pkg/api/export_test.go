@@ -4,4 +4,10 @@
 
 package api
 
-type PingpongResponse = pingpongResponse
+import "time"
+
+func SetTagEventsInterval(d time.Duration) (reset func()) {
+	current := tagEventsInterval
+	tagEventsInterval = d
+	return func() { tagEventsInterval = current }
+}
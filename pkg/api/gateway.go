@@ -0,0 +1,179 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/ethersphere/bee/pkg/gateway"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// gatewayHandler rejects requests that are not allowed by the gateway
+// and stops allowed ones once they transfer more bytes than the gateway
+// limits allow.
+func (s *server) gatewayHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := requestIP(r)
+		write := isWriteRequest(r)
+
+		retryAfter, err := s.Gateway.Allow(ip, write)
+		if err != nil {
+			s.Logger.Debugf("gateway: client %s: %v", ip, err)
+			switch {
+			case errors.Is(err, gateway.ErrWriteDenied):
+				jsonhttp.Forbidden(w, err)
+			case errors.Is(err, gateway.ErrTooManyReqs),
+				errors.Is(err, gateway.ErrTooManyBytes),
+				errors.Is(err, gateway.ErrQuotaExceeded):
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+				jsonhttp.TooManyRequests(w, err)
+			default:
+				s.Logger.Error("gateway: allow request")
+				jsonhttp.InternalServerError(w, nil)
+			}
+			return
+		}
+
+		t := &gatewayTransfer{gateway: s.Gateway, ip: ip, write: write}
+		if r.Body != nil {
+			r.Body = &countingReadCloser{ReadCloser: r.Body, t: t}
+		}
+		h.ServeHTTP(&countingResponseWriter{ResponseWriter: w, t: t}, r)
+		if write {
+			s.Gateway.Flush(ip)
+		}
+	})
+}
+
+// isWriteRequest returns true if the request stores
+// or changes data on the node.
+func isWriteRequest(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/bzz-chunks/upload":
+		return true
+	case "/bzz-chunks/download":
+		return false
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// requestIP returns the IP address of the client.
+func requestIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// gatewayTransfer records bytes transferred by a request in the
+// gateway as they are transferred, including ones over a hijacked
+// connection.
+type gatewayTransfer struct {
+	gateway *gateway.Gateway
+	ip      net.IP
+	write   bool
+}
+
+func (t *gatewayTransfer) received(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	return t.gateway.Transfer(t.ip, t.write, int64(n), 0)
+}
+
+func (t *gatewayTransfer) sent(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	return t.gateway.Transfer(t.ip, t.write, 0, int64(n))
+}
+
+// countingReadCloser fails reads of the request body
+// once the gateway limits are exceeded.
+type countingReadCloser struct {
+	io.ReadCloser
+	t *gatewayTransfer
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if terr := r.t.received(n); terr != nil {
+		return n, terr
+	}
+	return n, err
+}
+
+type countingResponseWriter struct {
+	http.ResponseWriter
+	t *gatewayTransfer
+}
+
+func (w *countingResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	if terr := w.t.sent(n); terr != nil {
+		return n, terr
+	}
+	return n, err
+}
+
+func (w *countingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *countingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijacking not supported")
+	}
+	conn, rw, err := h.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn = &countingConn{Conn: conn, t: w.t}
+	// already buffered bytes are read before the counting connection
+	buffered := io.LimitReader(rw.Reader, int64(rw.Reader.Buffered()))
+	rw = bufio.NewReadWriter(
+		bufio.NewReaderSize(io.MultiReader(buffered, conn), rw.Reader.Size()),
+		bufio.NewWriterSize(conn, rw.Writer.Size()),
+	)
+	return conn, rw, nil
+}
+
+// countingConn fails reads and writes of a hijacked
+// connection once the gateway limits are exceeded.
+type countingConn struct {
+	net.Conn
+	t *gatewayTransfer
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if terr := c.t.received(n); terr != nil {
+		return n, terr
+	}
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if terr := c.t.sent(n); terr != nil {
+		return n, terr
+	}
+	return n, err
+}
@@ -0,0 +1,254 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/gateway"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/resolver"
+	mockstate "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/websocket"
+)
+
+func TestGateway(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	chunk := chunktesting.GenerateValidRandomChunk()
+	resource := "/bzz-chunk/" + chunk.Address().String()
+
+	t.Run("writes denied", func(t *testing.T) {
+		client, cleanup := newTestServer(t, testServerOptions{
+			Storer:  mock.NewStorer(),
+			Gateway: gateway.New(mockstate.NewStateStore(), gateway.Options{}, logger),
+		})
+		defer cleanup()
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource, bytes.NewReader(chunk.Data()), http.StatusForbidden, jsonhttp.StatusResponse{
+			Message: gateway.ErrWriteDenied.Error(),
+			Code:    http.StatusForbidden,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource, nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "chunk not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("request rate", func(t *testing.T) {
+		client, cleanup := newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Gateway: gateway.New(mockstate.NewStateStore(), gateway.Options{
+				RequestRate:  1.0 / 60,
+				RequestBurst: 1,
+			}, logger),
+		})
+		defer cleanup()
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource, nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "chunk not found",
+			Code:    http.StatusNotFound,
+		})
+
+		resp, err := client.Get(resource)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("got status %v, want %v", resp.StatusCode, http.StatusTooManyRequests)
+		}
+		retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if retryAfter < 1 || retryAfter > 60 {
+			t.Errorf("got retry after %v, want between 1 and 60", retryAfter)
+		}
+	})
+
+	t.Run("host resolution", func(t *testing.T) {
+		names := &countingResolver{}
+		client, cleanup := newTestServer(t, testServerOptions{
+			Storer:   mock.NewStorer(),
+			Resolver: names,
+			Gateway: gateway.New(mockstate.NewStateStore(), gateway.Options{
+				RequestRate:  1.0 / 60,
+				RequestBurst: 1,
+			}, logger),
+		})
+		defer cleanup()
+
+		get := func(t *testing.T, host string) int {
+			t.Helper()
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Host = host
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		get(t, "first.eth")
+		if n := names.count(); n != 1 {
+			t.Fatalf("got %v resolved names, want 1", n)
+		}
+
+		// a rate limited client can not make the node resolve names
+		if code := get(t, "second.eth"); code != http.StatusTooManyRequests {
+			t.Fatalf("got status %v, want %v", code, http.StatusTooManyRequests)
+		}
+		if n := names.count(); n != 1 {
+			t.Errorf("got %v resolved names, want 1", n)
+		}
+	})
+
+	// newQuotaServer returns a server that allows a
+	// single chunk to be uploaded over a websocket
+	newQuotaServer := func(t *testing.T, storer storage.Storer) (url string, cleanup func()) {
+		t.Helper()
+
+		allowlist, err := gateway.ParseNetworks([]string{"127.0.0.1", "::1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ts := httptest.NewServer(api.New(api.Options{
+			Storer: storer,
+			Gateway: gateway.New(mockstate.NewStateStore(), gateway.Options{
+				WriteAllowlist: allowlist,
+				// websocket frames have up to 14 bytes of headers
+				DailyUploadQuota: uint64(len(chunk.Data())) + 100,
+			}, logger),
+			Logger: logger,
+		}))
+		return ts.URL, ts.Close
+	}
+	dialUpload := func(t *testing.T, url string) *websocket.Conn {
+		t.Helper()
+
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/bzz-chunks/upload", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+
+	t.Run("upload quota", func(t *testing.T) {
+		storer := mock.NewStorer()
+		url, cleanup := newQuotaServer(t, storer)
+		defer cleanup()
+
+		// bytes uploaded over a websocket connection are counted
+		conn := dialUpload(t, url)
+		if err := conn.WriteMessage(websocket.BinaryMessage, chunk.Data()); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := conn.ReadMessage(); err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			t.Fatalf("got error %v, want normal close", err)
+		}
+		conn.Close()
+
+		// the request body is stopped once the quota is exceeded
+		other := chunktesting.GenerateValidRandomChunk()
+		resp, err := http.Post(url+"/bzz-chunk/"+other.Address().String(), "application/octet-stream", bytes.NewReader(other.Data()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Fatal("upload over the quota succeeded")
+		}
+		if has, err := storer.Has(context.Background(), other.Address()); err != nil || has {
+			t.Fatalf("got chunk stored %v, error %v, want not stored", has, err)
+		}
+
+		// requests are rejected once the quota is used up
+		resp, err = http.Post(url+"/bzz-chunk/"+other.Address().String(), "application/octet-stream", bytes.NewReader(other.Data()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("got status %v, want %v", resp.StatusCode, http.StatusTooManyRequests)
+		}
+		if resp.Header.Get("Retry-After") == "" {
+			t.Error("retry after header not set")
+		}
+	})
+
+	t.Run("upload quota stream", func(t *testing.T) {
+		storer := mock.NewStorer()
+		url, cleanup := newQuotaServer(t, storer)
+		defer cleanup()
+
+		// a single stream can not go past the quota
+		conn := dialUpload(t, url)
+		defer conn.Close()
+		if err := conn.WriteMessage(websocket.BinaryMessage, chunk.Data()); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := conn.ReadMessage(); err != nil {
+			t.Fatal(err)
+		}
+		other := chunktesting.GenerateValidRandomChunk()
+		if err := conn.WriteMessage(websocket.BinaryMessage, other.Data()); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := conn.ReadMessage(); err == nil {
+			t.Fatal("chunk over the quota acknowledged")
+		}
+		if has, err := storer.Has(context.Background(), other.Address()); err != nil || has {
+			t.Fatalf("got chunk stored %v, error %v, want not stored", has, err)
+		}
+	})
+}
+
+// countingResolver counts names that it is asked to resolve,
+// and does not know any of them.
+type countingResolver struct {
+	mu    sync.Mutex
+	names int
+}
+
+func (r *countingResolver) Resolve(_ context.Context, _ string) (swarm.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.names++
+	return swarm.ZeroAddress, resolver.ErrNotFound
+}
+
+func (r *countingResolver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.names
+}
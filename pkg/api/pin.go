@@ -0,0 +1,289 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// SwarmPinHeader is the HTTP header that requests uploaded
+	// chunks to be pinned if its value is "true".
+	SwarmPinHeader = "Swarm-Pin"
+
+	defaultPinnedChunksLimit = 100
+	maxPinnedChunksLimit     = 1000
+)
+
+type PinnedChunk struct {
+	Address    swarm.Address `json:"address"`
+	PinCounter uint64        `json:"pinCounter"`
+}
+
+type ListPinnedChunksResponse struct {
+	Chunks []PinnedChunk `json:"chunks"`
+}
+
+type PinFileResponse struct {
+	Address swarm.Address `json:"address"`
+	Chunks  int           `json:"chunks"`
+}
+
+// requestPin returns true if the request asks for uploaded content
+// to be pinned.
+func requestPin(r *http.Request) bool {
+	return strings.ToLower(r.Header.Get(SwarmPinHeader)) == "true"
+}
+
+// pinChunkHandler pins a locally stored chunk.
+func (s *server) pinChunkHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("pin chunk: parse chunk address: %v", err)
+		s.Logger.Error("pin chunk: parse chunk address")
+		jsonhttp.BadRequest(w, "invalid chunk address")
+		return
+	}
+
+	has, err := s.Storer.Has(r.Context(), addr)
+	if err != nil {
+		s.Logger.Debugf("pin chunk: has chunk %s: %v", addr, err)
+		s.Logger.Error("pin chunk: has chunk")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+	if !has {
+		jsonhttp.NotFound(w, "chunk not found")
+		return
+	}
+
+	if err := s.Storer.Set(r.Context(), storage.ModeSetPin, addr); err != nil {
+		s.Logger.Debugf("pin chunk: pin chunk %s: %v", addr, err)
+		s.Logger.Error("pin chunk: pin chunk")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+// unpinChunkHandler decrements the pin counter of a pinned chunk.
+func (s *server) unpinChunkHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("unpin chunk: parse chunk address: %v", err)
+		s.Logger.Error("unpin chunk: parse chunk address")
+		jsonhttp.BadRequest(w, "invalid chunk address")
+		return
+	}
+
+	if _, err := s.Storer.Get(r.Context(), storage.ModeGetPin, addr); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			jsonhttp.NotFound(w, "chunk is not pinned")
+			return
+		}
+		s.Logger.Debugf("unpin chunk: get pinned chunk %s: %v", addr, err)
+		s.Logger.Error("unpin chunk: get pinned chunk")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	if err := s.Storer.Set(r.Context(), storage.ModeSetUnpin, addr); err != nil {
+		s.Logger.Debugf("unpin chunk: unpin chunk %s: %v", addr, err)
+		s.Logger.Error("unpin chunk: unpin chunk")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+// getPinnedChunkHandler returns the pin counter of a pinned chunk.
+func (s *server) getPinnedChunkHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("pinned chunk: parse chunk address: %v", err)
+		s.Logger.Error("pinned chunk: parse chunk address")
+		jsonhttp.BadRequest(w, "invalid chunk address")
+		return
+	}
+
+	ch, err := s.Storer.Get(r.Context(), storage.ModeGetPin, addr)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			jsonhttp.NotFound(w, "chunk is not pinned")
+			return
+		}
+		s.Logger.Debugf("pinned chunk: get pinned chunk %s: %v", addr, err)
+		s.Logger.Error("pinned chunk: get pinned chunk")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, PinnedChunk{
+		Address:    addr,
+		PinCounter: ch.PinCounter(),
+	})
+}
+
+// listPinnedChunksHandler lists pinned chunks ordered by their addresses.
+// Query parameter cursor sets the address after which the listing starts
+// and limit sets the maximal number of returned chunks.
+func (s *server) listPinnedChunksHandler(w http.ResponseWriter, r *http.Request) {
+	cursor := swarm.ZeroAddress
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		a, err := swarm.ParseHexAddress(v)
+		if err != nil {
+			s.Logger.Debugf("list pinned chunks: parse cursor %q: %v", v, err)
+			s.Logger.Error("list pinned chunks: parse cursor")
+			jsonhttp.BadRequest(w, "invalid cursor")
+			return
+		}
+		cursor = a
+	}
+
+	limit := defaultPinnedChunksLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 || l > maxPinnedChunksLimit {
+			s.Logger.Debugf("list pinned chunks: parse limit %q: %v", v, err)
+			s.Logger.Error("list pinned chunks: parse limit")
+			jsonhttp.BadRequest(w, "invalid limit")
+			return
+		}
+		limit = l
+	}
+
+	pinned, err := s.Storer.PinnedChunks(r.Context(), cursor, limit)
+	if err != nil {
+		s.Logger.Debugf("list pinned chunks: %v", err)
+		s.Logger.Error("list pinned chunks")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	chunks := make([]PinnedChunk, 0, len(pinned))
+	for _, p := range pinned {
+		chunks = append(chunks, PinnedChunk{
+			Address:    p.Address,
+			PinCounter: p.PinCounter,
+		})
+	}
+	jsonhttp.OK(w, ListPinnedChunksResponse{
+		Chunks: chunks,
+	})
+}
+
+// pinFileHandler pins all chunks of the chunk tree with the root reference.
+func (s *server) pinFileHandler(w http.ResponseWriter, r *http.Request) {
+	s.setFilePin(w, r, storage.ModeSetPin)
+}
+
+// unpinFileHandler unpins all chunks of the chunk tree with the root reference.
+func (s *server) unpinFileHandler(w http.ResponseWriter, r *http.Request) {
+	s.setFilePin(w, r, storage.ModeSetUnpin)
+}
+
+// setFilePin walks the chunk tree with the root reference from the request
+// and sets all its chunks with the provided pinning mode. Chunks that are
+// referenced multiple times in the tree are set only once, and only chunks
+// that are pinned are unpinned. The response holds the number of set
+// chunks.
+func (s *server) setFilePin(w http.ResponseWriter, r *http.Request, mode storage.ModeSet) {
+	ctx := r.Context()
+
+	ref, err := swarm.ParseHexAddress(mux.Vars(r)["reference"])
+	if err != nil {
+		s.Logger.Debugf("pin file: parse reference: %v", err)
+		s.Logger.Error("pin file: parse reference")
+		jsonhttp.BadRequest(w, "invalid reference")
+		return
+	}
+
+	if mode == storage.ModeSetUnpin {
+		if _, err := s.Storer.Get(ctx, storage.ModeGetPin, ref); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				jsonhttp.NotFound(w, "file is not pinned")
+				return
+			}
+			s.Logger.Debugf("pin file: get pinned chunk %s: %v", ref, err)
+			s.Logger.Error("pin file: get pinned chunk")
+			jsonhttp.InternalServerError(w, nil)
+			return
+		}
+	}
+
+	var addrs []swarm.Address
+	seen := make(map[string]struct{})
+	err = file.Walk(ctx, s.Storer, ref, func(ch swarm.Chunk) error {
+		if _, ok := seen[ch.Address().String()]; ok {
+			return nil
+		}
+		seen[ch.Address().String()] = struct{}{}
+		addrs = append(addrs, ch.Address())
+		return nil
+	})
+	if err != nil {
+		s.Logger.Debugf("pin file: walk %s: %v", ref, err)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			s.Logger.Error("pin file: chunk not found")
+			jsonhttp.NotFound(w, "chunk not found")
+		case errors.Is(err, file.ErrInvalidChunkData):
+			s.Logger.Error("pin file: invalid chunk data")
+			jsonhttp.BadRequest(w, "invalid chunk data")
+		default:
+			s.Logger.Error("pin file: walk chunks")
+			jsonhttp.InternalServerError(w, nil)
+		}
+		return
+	}
+
+	if mode == storage.ModeSetUnpin {
+		// chunks of subtrees that are shared with other files
+		// may be already unpinned or pinned separately
+		pinned := addrs[:0]
+		for _, addr := range addrs {
+			if _, err := s.Storer.Get(ctx, storage.ModeGetPin, addr); err != nil {
+				if errors.Is(err, storage.ErrNotFound) {
+					continue
+				}
+				s.Logger.Debugf("pin file: get pinned chunk %s: %v", addr, err)
+				s.Logger.Error("pin file: get pinned chunk")
+				jsonhttp.InternalServerError(w, nil)
+				return
+			}
+			pinned = append(pinned, addr)
+		}
+		addrs = pinned
+	}
+
+	if err := s.Storer.Set(ctx, mode, addrs...); err != nil {
+		s.Logger.Debugf("pin file: set %s %s: %v", mode, ref, err)
+		if errors.Is(err, storage.ErrNotFound) {
+			// chunks are unpinned by a concurrent request
+			s.Logger.Error("pin file: chunks are not pinned")
+			jsonhttp.Conflict(w, "chunks are not pinned")
+			return
+		}
+		s.Logger.Error("pin file: set chunks")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, PinFileResponse{
+		Address: ref,
+		Chunks:  len(addrs),
+	})
+}
@@ -0,0 +1,208 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestPinChunkHandler pins and unpins a single chunk and checks its
+// pin counter.
+func TestPinChunkHandler(t *testing.T) {
+	resource := func(addr swarm.Address) string {
+		return "/pin/chunks/" + addr.String()
+	}
+
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(),
+	})
+	defer cleanup()
+
+	ch := chunktesting.GenerateValidRandomChunk()
+	missing := chunktesting.GenerateValidRandomChunk()
+
+	t.Run("upload with pin header", func(t *testing.T) {
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, "/bzz-chunk/"+ch.Address().String(), bytes.NewReader(ch.Data()), http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		}, http.Header{
+			api.SwarmPinHeader: []string{"true"},
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource(ch.Address()), nil, http.StatusOK, api.PinnedChunk{
+			Address:    ch.Address(),
+			PinCounter: 1,
+		})
+	})
+
+	t.Run("pin", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource(ch.Address()), nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource(ch.Address()), nil, http.StatusOK, api.PinnedChunk{
+			Address:    ch.Address(),
+			PinCounter: 2,
+		})
+	})
+
+	t.Run("list", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/pin/chunks", nil, http.StatusOK, api.ListPinnedChunksResponse{
+			Chunks: []api.PinnedChunk{
+				{
+					Address:    ch.Address(),
+					PinCounter: 2,
+				},
+			},
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/pin/chunks?cursor="+ch.Address().String(), nil, http.StatusOK, api.ListPinnedChunksResponse{
+			Chunks: []api.PinnedChunk{},
+		})
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/pin/chunks?limit=0", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid limit",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("unpin", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			jsonhttptest.ResponseDirect(t, client, http.MethodDelete, resource(ch.Address()), nil, http.StatusOK, jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusOK),
+				Code:    http.StatusOK,
+			})
+		}
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, resource(ch.Address()), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "chunk is not pinned",
+			Code:    http.StatusNotFound,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource(ch.Address()), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "chunk is not pinned",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("pin missing chunk", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource(missing.Address()), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "chunk not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/pin/chunks/invalid-address", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid chunk address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
+
+// TestPinFileHandler pins and unpins all chunks of a chunk tree.
+func TestPinFileHandler(t *testing.T) {
+	resource := func(addr swarm.Address) string {
+		return "/pin/files/" + addr.String()
+	}
+
+	storer := mock.NewStorer()
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer: storer,
+	})
+	defer cleanup()
+
+	// the root chunk references the first leaf chunk twice
+	leaves := []swarm.Chunk{
+		newTestTreeChunk(t, storer, swarm.ChunkSize, bytes.Repeat([]byte{1}, swarm.ChunkSize)),
+		newTestTreeChunk(t, storer, swarm.ChunkSize, bytes.Repeat([]byte{2}, swarm.ChunkSize)),
+	}
+	var refs []byte
+	for _, l := range []swarm.Chunk{leaves[0], leaves[1], leaves[0]} {
+		refs = append(refs, l.Address().Bytes()...)
+	}
+	root := newTestTreeChunk(t, storer, 3*swarm.ChunkSize, refs)
+
+	t.Run("pin", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource(root.Address()), nil, http.StatusOK, api.PinFileResponse{
+			Address: root.Address(),
+			Chunks:  3,
+		})
+		for _, ch := range append(leaves, root) {
+			jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/pin/chunks/"+ch.Address().String(), nil, http.StatusOK, api.PinnedChunk{
+				Address:    ch.Address(),
+				PinCounter: 1,
+			})
+		}
+	})
+
+	t.Run("unpin", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, resource(root.Address()), nil, http.StatusOK, api.PinFileResponse{
+			Address: root.Address(),
+			Chunks:  3,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/pin/chunks", nil, http.StatusOK, api.ListPinnedChunksResponse{
+			Chunks: []api.PinnedChunk{},
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, resource(root.Address()), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "file is not pinned",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("unpin partially pinned", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource(root.Address()), nil, http.StatusOK, api.PinFileResponse{
+			Address: root.Address(),
+			Chunks:  3,
+		})
+		// the leaf is shared with another file that is unpinned
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, "/pin/chunks/"+leaves[1].Address().String(), nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		})
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, resource(root.Address()), nil, http.StatusOK, api.PinFileResponse{
+			Address: root.Address(),
+			Chunks:  2,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/pin/chunks", nil, http.StatusOK, api.ListPinnedChunksResponse{
+			Chunks: []api.PinnedChunk{},
+		})
+	})
+
+	t.Run("missing chunk", func(t *testing.T) {
+		missing := newTestTreeChunk(t, storer, 2*swarm.ChunkSize, make([]byte, 2*swarm.HashSize))
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource(missing.Address()), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "chunk not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+}
+
+// newTestTreeChunk stores a chunk with the provided span and payload,
+// addressed by a random address.
+func newTestTreeChunk(t *testing.T, storer storage.Storer, span uint64, payload []byte) swarm.Chunk {
+	t.Helper()
+
+	data := make([]byte, file.SpanSize+len(payload))
+	binary.LittleEndian.PutUint64(data, span)
+	copy(data[file.SpanSize:], payload)
+	ch := swarm.NewChunk(chunktesting.GenerateTestRandomChunk().Address(), data)
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
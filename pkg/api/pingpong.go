@@ -14,7 +14,7 @@ import (
 	"github.com/gorilla/mux"
 )
 
-type pingpongResponse struct {
+type PingpongResponse struct {
 	RTT string `json:"rtt"`
 }
 
@@ -47,7 +47,7 @@ func (s *server) pingpongHandler(w http.ResponseWriter, r *http.Request) {
 	s.metrics.PingRequestCount.Inc()
 
 	logger.Infof("pingpong succeeded to peer %s", peerID)
-	jsonhttp.OK(w, pingpongResponse{
+	jsonhttp.OK(w, PingpongResponse{
 		RTT: rtt.String(),
 	})
 }
@@ -8,9 +8,9 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/ethersphere/bee/pkg/auth"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/logging"
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"resenje.org/web"
@@ -20,6 +20,13 @@ func (s *server) setupRouting() {
 	router := mux.NewRouter()
 	router.NotFoundHandler = http.HandlerFunc(jsonhttp.NotFoundHandler)
 
+	if s.Resolver != nil {
+		router.MatcherFunc(hostMatcher).Handler(jsonhttp.MethodHandler{
+			"GET":  s.restricted(auth.ScopeRead, s.bzzHandler),
+			"HEAD": s.restricted(auth.ScopeRead, s.bzzHandler),
+		})
+	}
+
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "Ethereum Swarm Bee")
 	})
@@ -28,20 +35,90 @@ func (s *server) setupRouting() {
 		fmt.Fprintln(w, "User-agent: *\nDisallow: /")
 	})
 
+	if s.Auth != nil {
+		router.Handle("/auth", jsonhttp.MethodHandler{
+			"POST": http.HandlerFunc(s.authHandler),
+		})
+	}
+
 	router.Handle("/pingpong/{peer-id}", jsonhttp.MethodHandler{
-		"POST": http.HandlerFunc(s.pingpongHandler),
+		"POST": s.restricted(auth.ScopeRead, s.pingpongHandler),
+	})
+
+	router.Handle("/bzz/{name}", jsonhttp.MethodHandler{
+		"GET":  s.restricted(auth.ScopeRead, s.bzzHandler),
+		"HEAD": s.restricted(auth.ScopeRead, s.bzzHandler),
+	})
+
+	router.Handle("/bzz/{name}/{path:.*}", jsonhttp.MethodHandler{
+		"GET":  s.restricted(auth.ScopeRead, s.bzzHandler),
+		"HEAD": s.restricted(auth.ScopeRead, s.bzzHandler),
+	})
+
+	router.Handle("/bzz-chunk", jsonhttp.MethodHandler{
+		"POST": s.restricted(auth.ScopeUpload, s.chunkUploadContentHandler),
 	})
 
 	router.Handle("/bzz-chunk/{addr}", jsonhttp.MethodHandler{
-		"GET":  http.HandlerFunc(s.chunkGetHandler),
-		"POST": http.HandlerFunc(s.chunkUploadHandler),
+		"GET":  s.restricted(auth.ScopeRead, s.chunkGetHandler),
+		"HEAD": s.restricted(auth.ScopeRead, s.chunkGetHandler),
+		"POST": s.restricted(auth.ScopeUpload, s.chunkUploadHandler),
+	})
+
+	router.Handle("/bzz-chunks/upload", jsonhttp.MethodHandler{
+		"GET": s.restricted(auth.ScopeUpload, s.chunksUploadStreamHandler),
+	})
+
+	router.Handle("/bzz-chunks/download", jsonhttp.MethodHandler{
+		"POST": s.restricted(auth.ScopeRead, s.chunksDownloadHandler),
+	})
+
+	router.Handle("/tags", jsonhttp.MethodHandler{
+		"GET":  s.restricted(auth.ScopeTags, s.listTagsHandler),
+		"POST": s.restricted(auth.ScopeTags, s.createTagHandler),
+	})
+
+	router.Handle("/tags/{uid}", jsonhttp.MethodHandler{
+		"GET":    s.restricted(auth.ScopeTags, s.getTagHandler),
+		"DELETE": s.restricted(auth.ScopeTags, s.deleteTagHandler),
+	})
+
+	router.Handle("/tags/{uid}/events", jsonhttp.MethodHandler{
+		"GET": s.restricted(auth.ScopeTags, s.tagEventsHandler),
+	})
+
+	router.Handle("/pin/chunks", jsonhttp.MethodHandler{
+		"GET": s.restricted(auth.ScopePin, s.listPinnedChunksHandler),
+	})
+
+	router.Handle("/pin/chunks/{address}", jsonhttp.MethodHandler{
+		"GET":    s.restricted(auth.ScopePin, s.getPinnedChunkHandler),
+		"POST":   s.restricted(auth.ScopePin, s.pinChunkHandler),
+		"DELETE": s.restricted(auth.ScopePin, s.unpinChunkHandler),
+	})
+
+	router.Handle("/pin/files/{reference}", jsonhttp.MethodHandler{
+		"POST":   s.restricted(auth.ScopePin, s.pinFileHandler),
+		"DELETE": s.restricted(auth.ScopePin, s.unpinFileHandler),
 	})
 
-	s.Handler = web.ChainHandlers(
+	chain := []func(http.Handler) http.Handler{
+		logging.NewHTTPRequestIDHandler(),
 		logging.NewHTTPAccessLogHandler(s.Logger, logrus.InfoLevel, "api access"),
-		handlers.CompressHandler,
-		// todo: add recovery handler
+		logging.NewHTTPRecoveryHandler(s.Logger),
+	}
+	if s.Gateway != nil {
+		// bytes are counted as they are transferred, after compression
+		chain = append(chain, s.gatewayHandler)
+	}
+	if s.Resolver != nil {
+		// names are resolved only for requests allowed by the gateway
+		chain = append(chain, s.hostHandler)
+	}
+	chain = append(chain,
+		compressHandler,
 		s.pageviewMetricsHandler,
 		web.FinalHandler(router),
 	)
+	s.Handler = web.ChainHandlers(chain...)
 }
@@ -0,0 +1,42 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	client, cleanup := newTestServer(t, testServerOptions{})
+	defer cleanup()
+
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set(logging.RequestIDHeader, "some-request")
+	resp, err := client.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get(logging.RequestIDHeader); got != "some-request" {
+		t.Errorf("got request id %q, want %q", got, "some-request")
+	}
+
+	resp, err = client.Get("/robots.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get(logging.RequestIDHeader) == "" {
+		t.Error("request id not generated")
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// SwarmTagHeader is the HTTP header that holds the uid of the tag
+	// to which uploaded chunks are bound.
+	SwarmTagHeader = "Swarm-Tag"
+)
+
+// tagEventsInterval is the period in which tag counters
+// are checked for changes to be streamed as events.
+var tagEventsInterval = 500 * time.Millisecond
+
+var errInvalidTag = errors.New("invalid tag")
+
+type TagRequest struct {
+	Name      string `json:"name"`
+	Total     int64  `json:"total"`
+	Anonymous bool   `json:"anonymous"`
+}
+
+type TagResponse struct {
+	Uid       uint32        `json:"uid"`
+	Name      string        `json:"name"`
+	Anonymous bool          `json:"anonymous"`
+	Address   swarm.Address `json:"address"`
+	StartedAt time.Time     `json:"startedAt"`
+	Total     int64         `json:"total"`
+	Split     int64         `json:"split"`
+	Seen      int64         `json:"seen"`
+	Stored    int64         `json:"stored"`
+	Sent      int64         `json:"sent"`
+	Synced    int64         `json:"synced"`
+	ETA       *time.Time    `json:"eta,omitempty"`
+}
+
+type ListTagsResponse struct {
+	Tags []TagResponse `json:"tags"`
+}
+
+// newTagResponse returns the current counters of the tag. The ETA is
+// estimated for sent chunks of anonymous tags, which are only pull
+// synced, and for synced chunks of other tags, if it is available.
+func newTagResponse(t *tags.Tag) TagResponse {
+	r := TagResponse{
+		Uid:       t.Uid,
+		Name:      t.Name,
+		Anonymous: t.Anonymous,
+		Address:   t.Address,
+		StartedAt: t.StartedAt,
+		Total:     t.TotalCounter(),
+		Split:     t.Get(tags.StateSplit),
+		Seen:      t.Get(tags.StateSeen),
+		Stored:    t.Get(tags.StateStored),
+		Sent:      t.Get(tags.StateSent),
+		Synced:    t.Get(tags.StateSynced),
+	}
+	if eta, err := t.ETA(tagDoneState(t)); err == nil {
+		r.ETA = &eta
+	}
+	return r
+}
+
+// tagDoneState returns the state in which all chunks
+// of the tag are when the upload is complete.
+func tagDoneState(t *tags.Tag) tags.State {
+	if t.Anonymous {
+		return tags.StateSent
+	}
+	return tags.StateSynced
+}
+
+// requestTag returns the tag with the uid from the Swarm-Tag header,
+// or nil if the header is not set.
+func (s *server) requestTag(r *http.Request) (*tags.Tag, error) {
+	v := r.Header.Get(SwarmTagHeader)
+	if v == "" {
+		return nil, nil
+	}
+	uid, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return nil, errInvalidTag
+	}
+	return s.Tags.Get(uint32(uid))
+}
+
+// tagFromPath returns the tag with the uid from the request path.
+func (s *server) tagFromPath(r *http.Request) (*tags.Tag, error) {
+	uid, err := strconv.ParseUint(mux.Vars(r)["uid"], 10, 32)
+	if err != nil {
+		return nil, errInvalidTag
+	}
+	return s.Tags.Get(uint32(uid))
+}
+
+// respondTagError writes the response for errors of
+// requestTag and tagFromPath functions.
+func respondTagError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errInvalidTag):
+		jsonhttp.BadRequest(w, "invalid tag")
+	case errors.Is(err, tags.TagNotFoundErr):
+		jsonhttp.NotFound(w, "tag not found")
+	default:
+		jsonhttp.InternalServerError(w, nil)
+	}
+}
+
+// createTagHandler creates a new tag with optional
+// name, total chunks count and anonymity.
+func (s *server) createTagHandler(w http.ResponseWriter, r *http.Request) {
+	var req TagRequest
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.Logger.Debugf("create tag: read request body: %v", err)
+		s.Logger.Error("create tag: read request body")
+		jsonhttp.InternalServerError(w, "cannot read request")
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.Logger.Debugf("create tag: unmarshal request: %v", err)
+			s.Logger.Error("create tag: unmarshal request")
+			jsonhttp.BadRequest(w, "invalid request")
+			return
+		}
+	}
+	if req.Total < 0 {
+		jsonhttp.BadRequest(w, "invalid total")
+		return
+	}
+
+	t, err := s.Tags.Create(req.Name, req.Total, req.Anonymous)
+	if err != nil {
+		s.Logger.Debugf("create tag: %v", err)
+		s.Logger.Error("create tag")
+		jsonhttp.InternalServerError(w, "cannot create tag")
+		return
+	}
+
+	jsonhttp.Created(w, newTagResponse(t))
+}
+
+// listTagsHandler returns all tags ordered by their uids.
+func (s *server) listTagsHandler(w http.ResponseWriter, r *http.Request) {
+	all := s.Tags.All()
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Uid < all[j].Uid
+	})
+
+	resp := ListTagsResponse{
+		Tags: make([]TagResponse, 0, len(all)),
+	}
+	for _, t := range all {
+		resp.Tags = append(resp.Tags, newTagResponse(t))
+	}
+
+	jsonhttp.OK(w, resp)
+}
+
+// getTagHandler returns counters of a single tag.
+func (s *server) getTagHandler(w http.ResponseWriter, r *http.Request) {
+	t, err := s.tagFromPath(r)
+	if err != nil {
+		s.Logger.Debugf("get tag: %v", err)
+		respondTagError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, newTagResponse(t))
+}
+
+// deleteTagHandler removes the tag. Already uploaded
+// chunks are not affected.
+func (s *server) deleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	t, err := s.tagFromPath(r)
+	if err != nil {
+		s.Logger.Debugf("delete tag: %v", err)
+		respondTagError(w, err)
+		return
+	}
+
+	s.Tags.Delete(t.Uid)
+	t.FinishRootSpan()
+
+	jsonhttp.OK(w, nil)
+}
+
+// tagEventsHandler streams changes of tag counters as Server-Sent
+// Events. An event is sent immediately, and then on every change,
+// until the upload is complete, the tag is deleted, or the client
+// disconnects.
+func (s *server) tagEventsHandler(w http.ResponseWriter, r *http.Request) {
+	t, err := s.tagFromPath(r)
+	if err != nil {
+		s.Logger.Debugf("tag events: %v", err)
+		respondTagError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.Logger.Error("tag events: streaming not supported")
+		jsonhttp.InternalServerError(w, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(tagEventsInterval)
+	defer ticker.Stop()
+
+	var last TagResponse
+	for first := true; ; first = false {
+		if _, err := s.Tags.Get(t.Uid); err != nil {
+			// tag is deleted
+			return
+		}
+		resp := newTagResponse(t)
+		done := t.Done(tagDoneState(t))
+		if first || tagCountersChanged(last, resp) {
+			if err := writeTagEvent(w, resp); err != nil {
+				s.Logger.Debugf("tag events: write event: %v", err)
+				return
+			}
+			flusher.Flush()
+			last = resp
+		}
+		if done {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// tagCountersChanged reports if any counter differs in tag responses.
+func tagCountersChanged(a, b TagResponse) bool {
+	return a.Total != b.Total ||
+		a.Split != b.Split ||
+		a.Seen != b.Seen ||
+		a.Stored != b.Stored ||
+		a.Sent != b.Sent ||
+		a.Synced != b.Synced ||
+		!a.Address.Equal(b.Address)
+}
+
+// writeTagEvent writes the tag as a Server-Sent Event.
+func writeTagEvent(w io.Writer, resp TagResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: tag\ndata: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,203 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func TestTags(t *testing.T) {
+	tagg := tags.NewTags()
+	storer, err := localstore.New("", make([]byte, 32), &localstore.Options{
+		Tags: tagg,
+	}, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storer.Close()
+
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer: storer,
+		Tags:   tagg,
+	})
+	defer cleanup()
+
+	resource := func(uid uint32) string {
+		return "/tags/" + strconv.FormatUint(uint64(uid), 10)
+	}
+
+	var created api.TagResponse
+	t.Run("create", func(t *testing.T) {
+		body, err := json.Marshal(api.TagRequest{
+			Name:  "upload",
+			Total: 2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/tags", bytes.NewReader(body), http.StatusCreated, &created)
+		if created.Name != "upload" || created.Total != 2 {
+			t.Fatalf("got tag %+v", created)
+		}
+		if _, err := tagg.Get(created.Uid); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		ch := chunktesting.GenerateValidRandomChunk()
+		req, err := http.NewRequest(http.MethodPost, "/bzz-chunk/"+ch.Address().String(), bytes.NewReader(ch.Data()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(api.SwarmTagHeader, strconv.FormatUint(uint64(created.Uid), 10))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %v", resp.Status)
+		}
+		if got := resp.Header.Get(api.SwarmTagHeader); got != strconv.FormatUint(uint64(created.Uid), 10) {
+			t.Errorf("got tag header %q", got)
+		}
+
+		var got api.TagResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodGet, resource(created.Uid), nil, http.StatusOK, &got)
+		if got.Split != 1 || got.Stored != 1 || got.Total != 2 {
+			t.Errorf("got tag counters %+v", got)
+		}
+	})
+
+	t.Run("upload unknown tag", func(t *testing.T) {
+		ch := chunktesting.GenerateValidRandomChunk()
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, "/bzz-chunk/"+ch.Address().String(), bytes.NewReader(ch.Data()), http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "tag not found",
+			Code:    http.StatusNotFound,
+		}, http.Header{
+			api.SwarmTagHeader: []string{"1"},
+		})
+		jsonhttptest.ResponseDirectWithHeaders(t, client, http.MethodPost, "/bzz-chunk/"+ch.Address().String(), bytes.NewReader(ch.Data()), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid tag",
+			Code:    http.StatusBadRequest,
+		}, http.Header{
+			api.SwarmTagHeader: []string{"tag"},
+		})
+	})
+
+	t.Run("list", func(t *testing.T) {
+		var got api.ListTagsResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodGet, "/tags", nil, http.StatusOK, &got)
+		if len(got.Tags) != 1 || got.Tags[0].Uid != created.Uid {
+			t.Errorf("got tags %+v", got.Tags)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, resource(created.Uid), nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource(created.Uid), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "tag not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("invalid uid", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/tags/tag", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid tag",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
+
+func TestTagEvents(t *testing.T) {
+	defer api.SetTagEventsInterval(10 * time.Millisecond)()
+
+	tagg := tags.NewTags()
+	client, cleanup := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(),
+		Tags:   tagg,
+	})
+	defer cleanup()
+
+	tag, err := tagg.Create("upload", 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/tags/"+strconv.FormatUint(uint64(tag.Uid), 10)+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("got content type %q", got)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	nextEvent := func() (got api.TagResponse) {
+		t.Helper()
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &got); err != nil {
+				t.Fatal(err)
+			}
+			return got
+		}
+		t.Fatalf("no event: %v", scanner.Err())
+		return got
+	}
+
+	if got := nextEvent(); got.Uid != tag.Uid || got.Stored != 0 {
+		t.Fatalf("got event %+v", got)
+	}
+
+	tag.IncN(tags.StateStored, 2)
+	if got := nextEvent(); got.Stored != 2 || got.Synced != 0 {
+		t.Fatalf("got event %+v", got)
+	}
+
+	// the stream ends when all chunks are synced
+	tag.IncN(tags.StateSynced, 2)
+	got := nextEvent()
+	for got.Synced != 2 {
+		got = nextEvent()
+	}
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			t.Errorf("got data after the upload is complete: %q", line)
+		}
+	}
+}
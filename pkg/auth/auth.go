@@ -0,0 +1,222 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package auth issues and verifies expiring bearer tokens which grant
+// access to scopes of the HTTP API. Tokens are issued in exchange for
+// the admin secret, which is derived from the node key, together with
+// the key that signs tokens, so that tokens remain valid across node
+// restarts.
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+)
+
+// Scope is a group of API endpoints that a token grants access to.
+type Scope string
+
+const (
+	ScopeRead   Scope = "read"
+	ScopeUpload Scope = "upload"
+	ScopePin    Scope = "pin"
+	ScopeTags   Scope = "tags"
+)
+
+// Scopes are all known scopes.
+var Scopes = []Scope{ScopeRead, ScopeUpload, ScopePin, ScopeTags}
+
+const (
+	adminSecretLabel = "bee api admin secret"
+	signingKeyLabel  = "bee api token signing key"
+
+	// DefaultExpiry is the token validity if it is not requested.
+	DefaultExpiry = time.Hour
+	// DefaultMaxExpiry is the longest token validity if it is not set.
+	DefaultMaxExpiry = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidSecret = errors.New("invalid secret")
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrTokenExpired  = errors.New("token expired")
+	ErrUnknownScope  = errors.New("unknown scope")
+	ErrInvalidExpiry = errors.New("invalid expiry")
+)
+
+// Options are optional parameters of the Authenticator.
+type Options struct {
+	// PublicScopes are granted to all requests,
+	// with or without a token.
+	PublicScopes []Scope
+	// MaxExpiry is the longest validity of issued tokens.
+	MaxExpiry time.Duration
+}
+
+// Authenticator issues and verifies tokens.
+type Authenticator struct {
+	secret     []byte
+	signingKey []byte
+	public     map[Scope]struct{}
+	maxExpiry  time.Duration
+}
+
+// New constructs a new Authenticator with the admin secret
+// and the signing key derived from the private key.
+func New(key *ecdsa.PrivateKey, o *Options) (*Authenticator, error) {
+	if o == nil {
+		o = new(Options)
+	}
+	if o.MaxExpiry == 0 {
+		o.MaxExpiry = DefaultMaxExpiry
+	}
+	a := &Authenticator{
+		secret:     []byte(AdminSecret(key)),
+		signingKey: deriveKey(key, signingKeyLabel),
+		public:     make(map[Scope]struct{}),
+		maxExpiry:  o.MaxExpiry,
+	}
+	for _, s := range o.PublicScopes {
+		if !knownScope(s) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownScope, s)
+		}
+		a.public[s] = struct{}{}
+	}
+	return a, nil
+}
+
+// AdminSecret returns the hex encoded secret derived from the private
+// key, which is exchanged for tokens.
+func AdminSecret(key *ecdsa.PrivateKey) string {
+	return hex.EncodeToString(deriveKey(key, adminSecretLabel))
+}
+
+// deriveKey returns the HMAC of the label keyed by the private key.
+func deriveKey(key *ecdsa.PrivateKey, label string) []byte {
+	mac := hmac.New(sha256.New, crypto.EncodeSecp256k1PrivateKey(key))
+	_, _ = mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// ParseScopes returns scopes with provided names.
+func ParseScopes(names []string) (scopes []Scope, err error) {
+	for _, n := range names {
+		s := Scope(strings.TrimSpace(n))
+		if !knownScope(s) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownScope, n)
+		}
+		scopes = append(scopes, s)
+	}
+	return scopes, nil
+}
+
+func knownScope(s Scope) bool {
+	for _, k := range Scopes {
+		if s == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims are the scopes granted by a token and its expiration time.
+type Claims struct {
+	Scopes  []Scope   `json:"scopes"`
+	Expires time.Time `json:"expires"`
+}
+
+// Allows returns true if the scope is granted.
+func (c *Claims) Allows(scope Scope) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Public returns true if the scope is granted without a token.
+func (a *Authenticator) Public(scope Scope) bool {
+	_, ok := a.public[scope]
+	return ok
+}
+
+// Authorize returns a token with scopes that is valid for the expiry
+// duration, or the default expiry if it is zero, if the secret is the
+// admin secret.
+func (a *Authenticator) Authorize(secret string, scopes []Scope, expiry time.Duration) (token string, expires time.Time, err error) {
+	if subtle.ConstantTimeCompare([]byte(secret), a.secret) != 1 {
+		return "", time.Time{}, ErrInvalidSecret
+	}
+	if expiry == 0 {
+		expiry = DefaultExpiry
+	}
+	if expiry < 0 || expiry > a.maxExpiry {
+		return "", time.Time{}, ErrInvalidExpiry
+	}
+	for _, s := range scopes {
+		if !knownScope(s) {
+			return "", time.Time{}, fmt.Errorf("%w: %s", ErrUnknownScope, s)
+		}
+	}
+
+	claims := Claims{
+		Scopes:  scopes,
+		Expires: time.Now().Add(expiry).UTC(),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("marshal claims: %w", err)
+	}
+	enc := base64.RawURLEncoding
+	token = enc.EncodeToString(payload) + "." + enc.EncodeToString(a.sign(payload))
+	return token, claims.Expires, nil
+}
+
+// Verify returns claims of the token if it is signed
+// by the authenticator and not expired.
+func (a *Authenticator) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidToken
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	signature, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal(signature, a.sign(payload)) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !time.Now().Before(claims.Expires) {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+func (a *Authenticator) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, a.signingKey)
+	_, _ = mac.Write(payload)
+	return mac.Sum(nil)
+}
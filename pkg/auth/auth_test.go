@@ -0,0 +1,125 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/crypto"
+)
+
+func TestAuthenticator(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := auth.New(key, &auth.Options{
+		PublicScopes: []auth.Scope{auth.ScopeRead},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := auth.AdminSecret(key)
+
+	if !a.Public(auth.ScopeRead) || a.Public(auth.ScopeUpload) {
+		t.Error("only the read scope should be public")
+	}
+
+	token, expires, err := a.Authorize(secret, []auth.Scope{auth.ScopeUpload, auth.ScopeTags}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(expires); d <= 0 || d > auth.DefaultExpiry {
+		t.Errorf("got expiry in %v, want at most %v", d, auth.DefaultExpiry)
+	}
+
+	claims, err := a.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for scope, want := range map[auth.Scope]bool{
+		auth.ScopeUpload: true,
+		auth.ScopeTags:   true,
+		auth.ScopePin:    false,
+		auth.ScopeRead:   false,
+	} {
+		if got := claims.Allows(scope); got != want {
+			t.Errorf("scope %s: got allowed %v, want %v", scope, got, want)
+		}
+	}
+
+	t.Run("restart", func(t *testing.T) {
+		// tokens are valid with the same key
+		restarted, err := auth.New(key, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := restarted.Verify(token); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		otherKey, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := auth.New(otherKey, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := other.Verify(token); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("got error %v, want %v", err, auth.ErrInvalidToken)
+		}
+		if _, _, err := other.Authorize(secret, nil, 0); !errors.Is(err, auth.ErrInvalidSecret) {
+			t.Errorf("got error %v, want %v", err, auth.ErrInvalidSecret)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		if _, err := a.Verify("x" + token); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("got error %v, want %v", err, auth.ErrInvalidToken)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		token, _, err := a.Authorize(secret, []auth.Scope{auth.ScopePin}, time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if _, err := a.Verify(token); !errors.Is(err, auth.ErrTokenExpired) {
+			t.Errorf("got error %v, want %v", err, auth.ErrTokenExpired)
+		}
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		if _, _, err := a.Authorize("secret", nil, 0); !errors.Is(err, auth.ErrInvalidSecret) {
+			t.Errorf("got error %v, want %v", err, auth.ErrInvalidSecret)
+		}
+		if _, _, err := a.Authorize(secret, []auth.Scope{"admin"}, 0); !errors.Is(err, auth.ErrUnknownScope) {
+			t.Errorf("got error %v, want %v", err, auth.ErrUnknownScope)
+		}
+		if _, _, err := a.Authorize(secret, nil, auth.DefaultMaxExpiry+time.Second); !errors.Is(err, auth.ErrInvalidExpiry) {
+			t.Errorf("got error %v, want %v", err, auth.ErrInvalidExpiry)
+		}
+	})
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := auth.ParseScopes([]string{"read", " pin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scopes) != 2 || scopes[0] != auth.ScopeRead || scopes[1] != auth.ScopePin {
+		t.Errorf("got scopes %v", scopes)
+	}
+	if _, err := auth.ParseScopes([]string{"write"}); !errors.Is(err, auth.ErrUnknownScope) {
+		t.Errorf("got error %v, want %v", err, auth.ErrUnknownScope)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Client makes requests to the HTTP API.
+type Client struct {
+	*base
+}
+
+// New constructs a new Client of the HTTP API on the address, which is
+// a URL, a TCP address or a Unix domain socket path prefixed with
+// listener.UnixPrefix.
+func New(addr string, o *Options) (*Client, error) {
+	b, err := newBase(addr, o)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{base: b}, nil
+}
+
+// UploadOptions are optional parameters of uploads.
+type UploadOptions struct {
+	// Tag is the uid of the tag to which chunks are bound.
+	Tag uint32
+	// Pin pins uploaded chunks.
+	Pin bool
+	// PostageBatchID is the postage batch used to stamp chunks.
+	PostageBatchID []byte
+}
+
+func (o *UploadOptions) header() http.Header {
+	h := make(http.Header)
+	if o == nil {
+		return h
+	}
+	if o.Tag != 0 {
+		h.Set(api.SwarmTagHeader, strconv.FormatUint(uint64(o.Tag), 10))
+	}
+	if o.Pin {
+		h.Set(api.SwarmPinHeader, "true")
+	}
+	if o.PostageBatchID != nil {
+		h.Set(api.SwarmPostageBatchIdHeader, hex.EncodeToString(o.PostageBatchID))
+	}
+	return h
+}
+
+// Auth exchanges the admin secret for a token with the requested scopes.
+func (c *Client) Auth(ctx context.Context, r api.AuthRequest) (resp *api.AuthResponse, err error) {
+	resp = new(api.AuthResponse)
+	if err := c.requestJSON(ctx, http.MethodPost, "/auth", r, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Pingpong pings the connected peer with the overlay address.
+func (c *Client) Pingpong(ctx context.Context, peer swarm.Address) (resp *api.PingpongResponse, err error) {
+	resp = new(api.PingpongResponse)
+	if err := c.requestJSON(ctx, http.MethodPost, "/pingpong/"+peer.String(), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UploadChunk uploads the chunk data, which is prefixed with the span,
+// with the address that is validated by the node.
+func (c *Client) UploadChunk(ctx context.Context, addr swarm.Address, data []byte, o *UploadOptions) error {
+	resp, err := c.request(ctx, http.MethodPost, "/bzz-chunk/"+addr.String(), bytes.NewReader(data), o.header())
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, nil)
+}
+
+// UploadChunkContent uploads the chunk data, which is prefixed with the
+// span, and returns its content address computed by the node.
+func (c *Client) UploadChunkContent(ctx context.Context, data []byte, o *UploadOptions) (resp *api.ChunkAddressResponse, err error) {
+	r, err := c.request(ctx, http.MethodPost, "/bzz-chunk", bytes.NewReader(data), o.header())
+	if err != nil {
+		return nil, err
+	}
+	resp = new(api.ChunkAddressResponse)
+	if err := decodeResponse(r, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DownloadChunk returns the data of the chunk with the address.
+func (c *Client) DownloadChunk(ctx context.Context, addr swarm.Address) ([]byte, error) {
+	resp, err := c.request(ctx, http.MethodGet, "/bzz-chunk/"+addr.String(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Download returns the reader of the content with the hex encoded
+// reference or the name, and the size of the content. The reader must
+// be closed.
+func (c *Client) Download(ctx context.Context, name string) (r io.ReadCloser, size int64, err error) {
+	resp, err := c.request(ctx, http.MethodGet, "/bzz/"+url.PathEscape(name), nil, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// CreateTag creates a new tag.
+func (c *Client) CreateTag(ctx context.Context, r api.TagRequest) (resp *api.TagResponse, err error) {
+	resp = new(api.TagResponse)
+	if err := c.requestJSON(ctx, http.MethodPost, "/tags", r, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Tag returns the tag with the uid.
+func (c *Client) Tag(ctx context.Context, uid uint32) (resp *api.TagResponse, err error) {
+	resp = new(api.TagResponse)
+	if err := c.requestJSON(ctx, http.MethodGet, "/tags/"+strconv.FormatUint(uint64(uid), 10), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Tags returns all tags.
+func (c *Client) Tags(ctx context.Context) (resp *api.ListTagsResponse, err error) {
+	resp = new(api.ListTagsResponse)
+	if err := c.requestJSON(ctx, http.MethodGet, "/tags", nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteTag deletes the tag with the uid.
+func (c *Client) DeleteTag(ctx context.Context, uid uint32) error {
+	return c.requestJSON(ctx, http.MethodDelete, "/tags/"+strconv.FormatUint(uint64(uid), 10), nil, nil)
+}
+
+// PinChunk pins the chunk with the address.
+func (c *Client) PinChunk(ctx context.Context, addr swarm.Address) error {
+	return c.requestJSON(ctx, http.MethodPost, "/pin/chunks/"+addr.String(), nil, nil)
+}
+
+// UnpinChunk unpins the chunk with the address.
+func (c *Client) UnpinChunk(ctx context.Context, addr swarm.Address) error {
+	return c.requestJSON(ctx, http.MethodDelete, "/pin/chunks/"+addr.String(), nil, nil)
+}
+
+// PinnedChunk returns the pin counter of the chunk with the address.
+func (c *Client) PinnedChunk(ctx context.Context, addr swarm.Address) (resp *api.PinnedChunk, err error) {
+	resp = new(api.PinnedChunk)
+	if err := c.requestJSON(ctx, http.MethodGet, "/pin/chunks/"+addr.String(), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PinnedChunks returns all pinned chunks.
+func (c *Client) PinnedChunks(ctx context.Context) (resp *api.ListPinnedChunksResponse, err error) {
+	resp = new(api.ListPinnedChunksResponse)
+	if err := c.requestJSON(ctx, http.MethodGet, "/pin/chunks", nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PinFile pins all chunks of the chunk tree with the root reference.
+func (c *Client) PinFile(ctx context.Context, ref swarm.Address) (resp *api.PinFileResponse, err error) {
+	resp = new(api.PinFileResponse)
+	if err := c.requestJSON(ctx, http.MethodPost, "/pin/files/"+ref.String(), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UnpinFile unpins all chunks of the chunk tree with the root reference.
+func (c *Client) UnpinFile(ctx context.Context, ref swarm.Address) (resp *api.PinFileResponse, err error) {
+	resp = new(api.PinFileResponse)
+	if err := c.requestJSON(ctx, http.MethodDelete, "/pin/files/"+ref.String(), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package client provides clients of the HTTP API and the debug HTTP API
+// of a Bee node. Requests and responses are the types of the api and
+// debugapi packages, and error responses are returned as *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/listener"
+)
+
+// maxErrorSize limits the size of error
+// response bodies that are decoded.
+const maxErrorSize = 64 * 1024
+
+// Options are client parameters.
+type Options struct {
+	// HTTPClient makes requests to the node. It is used only for TCP
+	// addresses and http.DefaultClient is used if it is not set.
+	HTTPClient *http.Client
+	// Token is the bearer token sent in every request.
+	Token string
+}
+
+// Error is returned for responses with error status codes.
+type Error struct {
+	jsonhttp.StatusResponse
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// base makes requests to the HTTP server on the address.
+type base struct {
+	url        *url.URL
+	httpClient *http.Client
+	token      string
+}
+
+// newBase constructs a new base from the URL of the server, its TCP
+// address or the path to its Unix domain socket prefixed with
+// listener.UnixPrefix.
+func newBase(addr string, o *Options) (*base, error) {
+	if o == nil {
+		o = new(Options)
+	}
+	b := &base{
+		httpClient: o.HTTPClient,
+		token:      o.Token,
+	}
+	if b.httpClient == nil {
+		b.httpClient = http.DefaultClient
+	}
+
+	if path := strings.TrimPrefix(addr, listener.UnixPrefix); path != addr {
+		// the host is ignored on the socket
+		addr = "http://bee"
+		b.httpClient = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", path)
+				},
+			},
+		}
+	} else if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid address %q: unsupported scheme", addr)
+	}
+	if strings.HasPrefix(u.Host, ":") {
+		// addresses without a host are on the local machine
+		u.Host = "localhost" + u.Host
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	b.url = u
+	return b, nil
+}
+
+// request makes the request to the path and returns the response if
+// its status code is not an error, or *Error otherwise.
+func (b *base) request(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, b.url.String()+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	e := &Error{}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorSize))
+	if err != nil || json.Unmarshal(data, &e.StatusResponse) != nil || e.Message == "" {
+		// the response is not from the api, like from a proxy
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	e.Code = resp.StatusCode
+	return nil, e
+}
+
+// requestJSON makes the request with the JSON encoded
+// value in the body, if it is not nil, and decodes the
+// JSON response into the response value, if it is not
+// nil.
+func (b *base) requestJSON(ctx context.Context, method, path string, request, response interface{}) error {
+	var (
+		body   io.Reader
+		header http.Header
+	)
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		header = http.Header{"Content-Type": {"application/json"}}
+	}
+
+	resp, err := b.request(ctx, method, path, body, header)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, response)
+}
+
+// decodeResponse decodes the JSON response body into the
+// value, if it is not nil, and closes the body.
+func decodeResponse(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+
+	if v == nil {
+		_, err := io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/client"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	pingpongmock "github.com/ethersphere/bee/pkg/pingpong/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var logger = logging.New(ioutil.Discard, 0)
+
+func TestClient(t *testing.T) {
+	peer := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	ts := httptest.NewServer(api.New(api.Options{
+		Pingpong: pingpongmock.New(func(_ context.Context, addr swarm.Address, _ ...string) (time.Duration, error) {
+			if !addr.Equal(peer) {
+				return 0, p2p.ErrPeerNotFound
+			}
+			return time.Second, nil
+		}),
+		Storer: mock.NewStorer(),
+		Logger: logger,
+	}))
+	defer ts.Close()
+
+	c, err := client.New(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	t.Run("pingpong", func(t *testing.T) {
+		resp, err := c.Pingpong(ctx, peer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.RTT != time.Second.String() {
+			t.Errorf("got rtt %s, want %s", resp.RTT, time.Second)
+		}
+
+		_, err = c.Pingpong(ctx, swarm.MustParseHexAddress("aabbcc"))
+		assertError(t, err, http.StatusNotFound, "peer not found")
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		ch := chunktesting.GenerateValidRandomChunk()
+		resp, err := c.UploadChunkContent(ctx, ch.Data(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Reference.Equal(ch.Address()) {
+			t.Errorf("got reference %s, want %s", resp.Reference, ch.Address())
+		}
+
+		data, err := c.DownloadChunk(ctx, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, ch.Data()) {
+			t.Error("downloaded data does not match")
+		}
+
+		other := chunktesting.GenerateValidRandomChunk()
+		err = c.UploadChunk(ctx, other.Address(), ch.Data(), nil)
+		assertError(t, err, http.StatusBadRequest, "invalid chunk")
+
+		_, err = c.DownloadChunk(ctx, other.Address())
+		assertError(t, err, http.StatusNotFound, "chunk not found")
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		tag, err := c.CreateTag(ctx, api.TagRequest{Name: "upload"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ch := chunktesting.GenerateValidRandomChunk()
+		if err := c.UploadChunk(ctx, ch.Address(), ch.Data(), &client.UploadOptions{
+			Tag: tag.Uid,
+			Pin: true,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := c.Tag(ctx, tag.Uid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != "upload" || got.Split != 1 {
+			t.Errorf("got tag %+v", got)
+		}
+		list, err := c.Tags(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Tags) != 1 || list.Tags[0].Uid != tag.Uid {
+			t.Errorf("got tags %+v", list.Tags)
+		}
+		if err := c.DeleteTag(ctx, tag.Uid); err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.Tag(ctx, tag.Uid)
+		assertError(t, err, http.StatusNotFound, "tag not found")
+
+		pinned, err := c.PinnedChunk(ctx, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pinned.PinCounter != 1 {
+			t.Errorf("got pin counter %v, want 1", pinned.PinCounter)
+		}
+		if err := c.UnpinChunk(ctx, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("download", func(t *testing.T) {
+		ch := chunktesting.GenerateValidRandomChunk()
+		if err := c.UploadChunk(ctx, ch.Address(), ch.Data(), nil); err != nil {
+			t.Fatal(err)
+		}
+		r, size, err := c.Download(ctx, ch.Address().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, ch.Data()[8:]) || size != int64(len(data)) {
+			t.Error("downloaded data does not match")
+		}
+	})
+}
+
+func TestClient_auth(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.New(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(api.New(api.Options{
+		Storer: mock.NewStorer(),
+		Auth:   authenticator,
+		Logger: logger,
+	}))
+	defer ts.Close()
+
+	c, err := client.New(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	_, err = c.Tags(ctx)
+	assertError(t, err, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+
+	resp, err := c.Auth(ctx, api.AuthRequest{
+		Secret: auth.AdminSecret(key),
+		Scopes: []string{"tags"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = client.New(ts.URL, &client.Options{Token: resp.Token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Tags(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_errors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream failed", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	c, err := client.New(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Tags(context.Background())
+	assertError(t, err, http.StatusBadGateway, http.StatusText(http.StatusBadGateway))
+
+	if _, err := client.New("ftp://localhost", nil); err == nil {
+		t.Error("expected error for unsupported scheme")
+	}
+}
+
+func assertError(t *testing.T, err error, code int, message string) {
+	t.Helper()
+
+	var e *client.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("got error %v, want *client.Error", err)
+	}
+	if e.Code != code || e.Message != message {
+		t.Errorf("got error %v, want %d %s", e, code, message)
+	}
+}
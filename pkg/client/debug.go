@@ -0,0 +1,106 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// DebugClient makes requests to the debug HTTP API.
+type DebugClient struct {
+	*base
+}
+
+// NewDebug constructs a new DebugClient of the debug HTTP API on the
+// address, which is a URL, a TCP address or a Unix domain socket path
+// prefixed with listener.UnixPrefix.
+func NewDebug(addr string, o *Options) (*DebugClient, error) {
+	b, err := newBase(addr, o)
+	if err != nil {
+		return nil, err
+	}
+	return &DebugClient{base: b}, nil
+}
+
+// Health returns the status of the node.
+func (c *DebugClient) Health(ctx context.Context) (resp *debugapi.StatusResponse, err error) {
+	resp = new(debugapi.StatusResponse)
+	if err := c.requestJSON(ctx, http.MethodGet, "/health", nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Readiness returns the status of the node
+// when it is ready to serve requests.
+func (c *DebugClient) Readiness(ctx context.Context) (resp *debugapi.StatusResponse, err error) {
+	resp = new(debugapi.StatusResponse)
+	if err := c.requestJSON(ctx, http.MethodGet, "/readiness", nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Addresses returns the overlay and underlay addresses of the node.
+func (c *DebugClient) Addresses(ctx context.Context) (resp *debugapi.AddressesResponse, err error) {
+	resp = new(debugapi.AddressesResponse)
+	if err := c.requestJSON(ctx, http.MethodGet, "/addresses", nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Peers returns connected peers.
+func (c *DebugClient) Peers(ctx context.Context) (resp *debugapi.PeersResponse, err error) {
+	resp = new(debugapi.PeersResponse)
+	if err := c.requestJSON(ctx, http.MethodGet, "/peers", nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Connect connects to the peer with the underlay address.
+func (c *DebugClient) Connect(ctx context.Context, addr multiaddr.Multiaddr) (resp *debugapi.PeerConnectResponse, err error) {
+	resp = new(debugapi.PeerConnectResponse)
+	if err := c.requestJSON(ctx, http.MethodPost, "/connect"+addr.String(), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Disconnect disconnects from the peer with the overlay address.
+func (c *DebugClient) Disconnect(ctx context.Context, peer swarm.Address) error {
+	return c.requestJSON(ctx, http.MethodDelete, "/peers/"+peer.String(), nil, nil)
+}
+
+// Topology returns the connectivity state of the topology driver.
+func (c *DebugClient) Topology(ctx context.Context) (resp *topology.Snapshot, err error) {
+	resp = new(topology.Snapshot)
+	if err := c.requestJSON(ctx, http.MethodGet, "/topology", nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Chunk returns the state of the chunk in the local store.
+func (c *DebugClient) Chunk(ctx context.Context, addr swarm.Address) (resp *localstore.ChunkInfo, err error) {
+	resp = new(localstore.ChunkInfo)
+	if err := c.requestJSON(ctx, http.MethodGet, "/chunks/"+addr.String(), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RemoveChunk removes the chunk from the local store.
+func (c *DebugClient) RemoveChunk(ctx context.Context, addr swarm.Address) error {
+	return c.requestJSON(ctx, http.MethodDelete, "/chunks/"+addr.String(), nil, nil)
+}
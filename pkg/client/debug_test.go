@@ -0,0 +1,176 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/client"
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/listener"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/p2p"
+	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
+	statestoremock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+	topologymock "github.com/ethersphere/bee/pkg/topology/mock"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestDebugClient(t *testing.T) {
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	peer := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59a")
+	underlay, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/7070/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errConnect := errors.New("connect failed")
+	storer, err := localstore.New("", overlay.Bytes(), nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storer.Close()
+	chunk := chunktesting.GenerateValidRandomChunk()
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+	topologyDriver := topologymock.NewTopologyDriver()
+	topologyDriver.SetSnapshot(&topology.Snapshot{
+		BaseAddr:  overlay,
+		Depth:     2,
+		Connected: 1,
+	})
+
+	s := debugapi.New(debugapi.Options{
+		Overlay: overlay,
+		P2P: p2pmock.New(
+			p2pmock.WithAddressesFunc(func() ([]ma.Multiaddr, error) {
+				return []ma.Multiaddr{underlay}, nil
+			}),
+			p2pmock.WithPeersFunc(func() []p2p.Peer {
+				return []p2p.Peer{{Address: peer}}
+			}),
+			p2pmock.WithConnectFunc(func(_ context.Context, addr ma.Multiaddr) (swarm.Address, error) {
+				if !addr.Equal(underlay) {
+					return swarm.ZeroAddress, errConnect
+				}
+				return peer, nil
+			}),
+			p2pmock.WithDisconnectFunc(func(swarm.Address) error {
+				return p2p.ErrPeerNotFound
+			}),
+		),
+		Addressbook:    addressbook.New(statestoremock.NewStateStore()),
+		TopologyDriver: topologyDriver,
+		Storer:         storer,
+		Logger:         logger,
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c, err := client.NewDebug(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	health, err := c.Health(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != "ok" {
+		t.Errorf("got status %q, want ok", health.Status)
+	}
+
+	addresses, err := c.Addresses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !addresses.Overlay.Equal(overlay) || len(addresses.Underlay) != 1 || !addresses.Underlay[0].Equal(underlay) {
+		t.Errorf("got addresses %+v", addresses)
+	}
+
+	peers, err := c.Peers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers.Peers) != 1 || !peers.Peers[0].Address.Equal(peer) {
+		t.Errorf("got peers %+v", peers.Peers)
+	}
+
+	connected, err := c.Connect(ctx, underlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if connected.Address != peer.String() {
+		t.Errorf("got address %s, want %s", connected.Address, peer)
+	}
+	other, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/7071")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Connect(ctx, other)
+	assertError(t, err, http.StatusInternalServerError, errConnect.Error())
+
+	err = c.Disconnect(ctx, peer)
+	assertError(t, err, http.StatusBadRequest, "peer not found")
+
+	snapshot, err := c.Topology(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot.BaseAddr.Equal(overlay) || snapshot.Depth != 2 || snapshot.Connected != 1 {
+		t.Errorf("got topology %+v", snapshot)
+	}
+
+	info, err := c.Chunk(ctx, chunk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Address.Equal(chunk.Address()) || info.PushSynced || !info.PullSyncable {
+		t.Errorf("got chunk info %+v", info)
+	}
+	if err := c.RemoveChunk(ctx, chunk.Address()); err != nil {
+		t.Fatal(err)
+	}
+	err = c.RemoveChunk(ctx, chunk.Address())
+	assertError(t, err, http.StatusNotFound, "chunk not found")
+
+	t.Run("unix socket", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "bee-client")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		addr := listener.UnixPrefix + filepath.Join(dir, "debug.sock")
+		l, err := listener.New(addr, listener.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := &http.Server{Handler: s}
+		go func() { _ = server.Serve(l) }()
+		defer server.Close()
+
+		c, err := client.NewDebug(addr, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Health(ctx); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
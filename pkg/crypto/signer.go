@@ -0,0 +1,78 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec"
+	"golang.org/x/crypto/sha3"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Signer signs arbitrary data with a private key and exposes the
+// identities derived from its public key.
+type Signer interface {
+	// Sign signs the Keccak256 hash of data and returns a 65 byte
+	// recoverable signature.
+	Sign(data []byte) (signature []byte, err error)
+	// PublicKey returns the public key of the signer.
+	PublicKey() (*ecdsa.PublicKey, error)
+	// EthereumAddress returns the Ethereum address of the signer.
+	EthereumAddress() ([]byte, error)
+}
+
+type defaultSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewDefaultSigner constructs a Signer that uses the provided
+// secp256k1 private key.
+func NewDefaultSigner(key *ecdsa.PrivateKey) Signer {
+	return &defaultSigner{
+		key: key,
+	}
+}
+
+func (d *defaultSigner) Sign(data []byte) (signature []byte, err error) {
+	return btcec.SignCompact(btcec.S256(), (*btcec.PrivateKey)(d.key), keccak256(data), false)
+}
+
+func (d *defaultSigner) PublicKey() (*ecdsa.PublicKey, error) {
+	return &d.key.PublicKey, nil
+}
+
+func (d *defaultSigner) EthereumAddress() ([]byte, error) {
+	return NewEthereumAddress(d.key.PublicKey)
+}
+
+// Recover returns the public key that created the signature over data.
+func Recover(signature, data []byte) (*ecdsa.PublicKey, error) {
+	if len(signature) != 65 {
+		return nil, ErrInvalidSignature
+	}
+	p, _, err := btcec.RecoverCompact(btcec.S256(), signature, keccak256(data))
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return (*ecdsa.PublicKey)(p), nil
+}
+
+// NewEthereumAddress returns the 20 byte Ethereum address of a public key.
+func NewEthereumAddress(p ecdsa.PublicKey) ([]byte, error) {
+	if p.X == nil || p.Y == nil {
+		return nil, errors.New("invalid public key")
+	}
+	pubBytes := (*btcec.PublicKey)(&p).SerializeUncompressed()
+	return keccak256(pubBytes[1:])[12:], nil
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+)
+
+func TestDefaultSigner(t *testing.T) {
+	k, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(k)
+	data := []byte("swarm postage")
+
+	signature, err := signer.Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(signature); l != 65 {
+		t.Fatalf("got signature length %v, want %v", l, 65)
+	}
+
+	t.Run("recover", func(t *testing.T) {
+		pub, err := crypto.Recover(signature, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pub.X.Cmp(k.PublicKey.X) != 0 || pub.Y.Cmp(k.PublicKey.Y) != 0 {
+			t.Fatal("recovered public key does not match signer key")
+		}
+	})
+
+	t.Run("recover other data", func(t *testing.T) {
+		pub, err := crypto.Recover(signature, []byte("other data"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pub.X.Cmp(k.PublicKey.X) == 0 && pub.Y.Cmp(k.PublicKey.Y) == 0 {
+			t.Fatal("recovered signer key from signature over other data")
+		}
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		_, err := crypto.Recover(signature[1:], data)
+		if !errors.Is(err, crypto.ErrInvalidSignature) {
+			t.Fatalf("got error %v, want %v", err, crypto.ErrInvalidSignature)
+		}
+	})
+
+	t.Run("ethereum address", func(t *testing.T) {
+		a, err := signer.EthereumAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l := len(a); l != 20 {
+			t.Fatalf("got address length %v, want %v", l, 20)
+		}
+		want, err := crypto.NewEthereumAddress(k.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(a, want) {
+			t.Fatalf("got address %x, want %x", a, want)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+func (s *server) chunkInfoHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["address"]
+	address, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: parse chunk address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid chunk address")
+		return
+	}
+
+	info, err := s.Storer.DebugChunk(address)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			jsonhttp.NotFound(w, "chunk not found")
+			return
+		}
+		s.Logger.Debugf("debug api: inspect chunk %s: %v", addr, err)
+		s.Logger.Errorf("unable to inspect chunk %s", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, info)
+}
+
+func (s *server) chunkRemoveHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["address"]
+	address, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: parse chunk address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid chunk address")
+		return
+	}
+
+	has, err := s.Storer.Has(r.Context(), address)
+	if err != nil {
+		s.Logger.Debugf("debug api: remove chunk %s: has: %v", addr, err)
+		s.Logger.Errorf("unable to remove chunk %s", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+	if !has {
+		jsonhttp.NotFound(w, "chunk not found")
+		return
+	}
+
+	if err := s.Storer.Set(r.Context(), storage.ModeSetRemove, address); err != nil {
+		s.Logger.Debugf("debug api: remove chunk %s: %v", addr, err)
+		s.Logger.Errorf("unable to remove chunk %s", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+)
+
+func TestChunk(t *testing.T) {
+	storer, err := localstore.New("", make([]byte, 32), nil, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storer.Close()
+
+	chunk := chunktesting.GenerateValidRandomChunk()
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+	resource := "/chunks/" + chunk.Address().String()
+
+	testServer := newTestServer(t, testServerOptions{
+		Storer: storer,
+	})
+	defer testServer.Cleanup()
+
+	t.Run("info", func(t *testing.T) {
+		want, err := storer.DebugChunk(chunk.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, resource, nil, http.StatusOK, want)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chunks/invalid", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid chunk address",
+		})
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodDelete, resource, nil, http.StatusOK, jsonhttp.StatusResponse{
+			Code:    http.StatusOK,
+			Message: http.StatusText(http.StatusOK),
+		})
+
+		has, err := storer.Has(context.Background(), chunk.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if has {
+			t.Error("chunk is not removed")
+		}
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodDelete, resource, nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Code:    http.StatusNotFound,
+			Message: "chunk not found",
+		})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		other := chunktesting.GenerateValidRandomChunk()
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chunks/"+other.Address().String(), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Code:    http.StatusNotFound,
+			Message: "chunk not found",
+		})
+	})
+}
@@ -5,11 +5,14 @@
 package debugapi
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/prometheus/client_golang/prometheus"
@@ -32,9 +35,18 @@ type Options struct {
 	P2P            p2p.Service
 	Addressbook    addressbook.GetPutter
 	TopologyDriver topology.PeerAdder
+	Storer         Storer
 	Logger         logging.Logger
 }
 
+// Storer is the local chunk store that is inspected and
+// from which chunks are removed by the chunks endpoints.
+type Storer interface {
+	Has(ctx context.Context, addr swarm.Address) (yes bool, err error)
+	Set(ctx context.Context, mode storage.ModeSet, addrs ...swarm.Address) (err error)
+	DebugChunk(addr swarm.Address) (info *localstore.ChunkInfo, err error)
+}
+
 func New(o Options) Service {
 	s := &server{
 		Options:         o,
@@ -25,6 +25,7 @@ import (
 type testServerOptions struct {
 	Overlay swarm.Address
 	P2P     p2p.Service
+	Storer  debugapi.Storer
 }
 
 type testServer struct {
@@ -45,6 +46,7 @@ func newTestServer(t *testing.T, o testServerOptions) *testServer {
 		Logger:         logging.New(ioutil.Discard, 0),
 		Addressbook:    addressbook,
 		TopologyDriver: topologyDriver,
+		Storer:         o.Storer,
 	})
 	ts := httptest.NewServer(s)
 	cleanup := ts.Close
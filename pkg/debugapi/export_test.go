@@ -1,12 +0,0 @@
-// Copyright 2020 The Swarm Authors. All rights reserved.
-// Use of this source code is governed by a BSD-style
-// license that can be found in the LICENSE file.
-
-package debugapi
-
-type (
-	StatusResponse      = statusResponse
-	PeerConnectResponse = peerConnectResponse
-	PeersResponse       = peersResponse
-	AddressesResponse   = addressesResponse
-)
@@ -5,6 +5,7 @@
 package debugapi
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/ethersphere/bee/pkg/jsonhttp"
@@ -12,11 +13,34 @@ import (
 	"github.com/multiformats/go-multiaddr"
 )
 
-type addressesResponse struct {
+type AddressesResponse struct {
 	Overlay  swarm.Address         `json:"overlay"`
 	Underlay []multiaddr.Multiaddr `json:"underlay"`
 }
 
+// UnmarshalJSON decodes underlay addresses, as
+// multiaddr.Multiaddr is an interface.
+func (r *AddressesResponse) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Overlay  swarm.Address `json:"overlay"`
+		Underlay []string      `json:"underlay"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	var underlay []multiaddr.Multiaddr
+	for _, u := range v.Underlay {
+		addr, err := multiaddr.NewMultiaddr(u)
+		if err != nil {
+			return err
+		}
+		underlay = append(underlay, addr)
+	}
+	r.Overlay = v.Overlay
+	r.Underlay = underlay
+	return nil
+}
+
 func (s *server) addressesHandler(w http.ResponseWriter, r *http.Request) {
 	underlay, err := s.P2P.Addresses()
 	if err != nil {
@@ -24,7 +48,7 @@ func (s *server) addressesHandler(w http.ResponseWriter, r *http.Request) {
 		jsonhttp.InternalServerError(w, err)
 		return
 	}
-	jsonhttp.OK(w, addressesResponse{
+	jsonhttp.OK(w, AddressesResponse{
 		Overlay:  s.Overlay,
 		Underlay: underlay,
 	})
@@ -15,7 +15,7 @@ import (
 	"github.com/multiformats/go-multiaddr"
 )
 
-type peerConnectResponse struct {
+type PeerConnectResponse struct {
 	Address string `json:"address"`
 }
 
@@ -50,7 +50,7 @@ func (s *server) peerConnectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonhttp.OK(w, peerConnectResponse{
+	jsonhttp.OK(w, PeerConnectResponse{
 		Address: address.String(),
 	})
 }
@@ -78,12 +78,12 @@ func (s *server) peerDisconnectHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.OK(w, nil)
 }
 
-type peersResponse struct {
+type PeersResponse struct {
 	Peers []p2p.Peer `json:"peers"`
 }
 
 func (s *server) peersHandler(w http.ResponseWriter, r *http.Request) {
-	jsonhttp.OK(w, peersResponse{
+	jsonhttp.OK(w, PeersResponse{
 		Peers: s.P2P.Peers(),
 	})
 }
@@ -52,11 +52,19 @@ func (s *server) setupRouting() {
 	router.Handle("/peers/{address}", jsonhttp.MethodHandler{
 		"DELETE": http.HandlerFunc(s.peerDisconnectHandler),
 	})
+	router.Handle("/topology", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.topologyHandler),
+	})
+	router.Handle("/chunks/{address}", jsonhttp.MethodHandler{
+		"GET":    http.HandlerFunc(s.chunkInfoHandler),
+		"DELETE": http.HandlerFunc(s.chunkRemoveHandler),
+	})
 
 	baseRouter.Handle("/", web.ChainHandlers(
+		logging.NewHTTPRequestIDHandler(),
 		logging.NewHTTPAccessLogHandler(s.Logger, logrus.InfoLevel, "debug api access"),
+		logging.NewHTTPRecoveryHandler(s.Logger),
 		handlers.CompressHandler,
-		// todo: add recovery handler
 		web.NoCacheHeadersHandler,
 		web.FinalHandler(router),
 	))
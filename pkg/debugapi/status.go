@@ -10,12 +10,12 @@ import (
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 )
 
-type statusResponse struct {
+type StatusResponse struct {
 	Status string `json:"status"`
 }
 
 func (s *server) statusHandler(w http.ResponseWriter, r *http.Request) {
-	jsonhttp.OK(w, statusResponse{
+	jsonhttp.OK(w, StatusResponse{
 		Status: "ok",
 	})
 }
@@ -0,0 +1,28 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/topology"
+)
+
+func (s *server) topologyHandler(w http.ResponseWriter, r *http.Request) {
+	snapshotter, ok := s.TopologyDriver.(topology.Snapshotter)
+	if !ok {
+		jsonhttp.NotImplemented(w, "topology snapshot not supported")
+		return
+	}
+
+	snapshot := snapshotter.Snapshot()
+	if snapshot == nil {
+		jsonhttp.NotImplemented(w, "topology snapshot not available")
+		return
+	}
+
+	jsonhttp.OK(w, snapshot)
+}
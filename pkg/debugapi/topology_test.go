@@ -0,0 +1,52 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+)
+
+func TestTopology(t *testing.T) {
+	base := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	peer := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59d")
+	snapshot := &topology.Snapshot{
+		BaseAddr:  base,
+		Timestamp: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+		Depth:     1,
+		Connected: 1,
+		Known:     1,
+		Bins: []topology.BinSnapshot{
+			{PO: 0},
+			{PO: 1, Connected: 1, Known: 1},
+		},
+		Peers: []topology.PeerSnapshot{
+			{Address: peer, Proximity: uint8(swarm.Proximity(base.Bytes(), peer.Bytes())), Connected: true},
+		},
+	}
+
+	testServer := newTestServer(t, testServerOptions{
+		Overlay: base,
+	})
+	defer testServer.Cleanup()
+
+	t.Run("not available", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/topology", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "topology snapshot not available",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		testServer.TopologyDriver.SetSnapshot(snapshot)
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/topology", nil, http.StatusOK, snapshot)
+	})
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ io.ReaderAt = (*Reader)(nil)
+
+// Reader reads data of the chunk tree with the root address. Only
+// chunks that hold the requested data, and their ancestors, are
+// retrieved from the store.
+type Reader struct {
+	ctx   context.Context
+	store storage.Storer
+	root  swarm.Chunk
+	size  int64
+}
+
+// NewReader retrieves the root chunk with the address and constructs a
+// new Reader of its chunk tree. Chunks are retrieved with
+// storage.ModeGetRequest, using the context.
+func NewReader(ctx context.Context, s storage.Storer, root swarm.Address) (*Reader, error) {
+	ch, err := s.Get(ctx, storage.ModeGetRequest, root)
+	if err != nil {
+		return nil, fmt.Errorf("get chunk %s: %w", root, err)
+	}
+	span, err := chunkSpan(ch)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{
+		ctx:   ctx,
+		store: s,
+		root:  ch,
+		size:  int64(span),
+	}, nil
+}
+
+// Size returns the size of data in the chunk tree.
+func (r *Reader) Size() int64 {
+	return r.size
+}
+
+// ReadAt reads data of the chunk tree starting at the offset.
+func (r *Reader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if rest := r.size - off; int64(len(p)) > rest {
+		p = p[:rest]
+		err = io.EOF
+	}
+	m, readErr := r.readAt(r.root, p, uint64(off))
+	if readErr != nil {
+		return m, readErr
+	}
+	return m, err
+}
+
+// readAt reads data of the subtree of the chunk starting at the offset
+// in the subtree, until p is full or the subtree data ends.
+func (r *Reader) readAt(ch swarm.Chunk, p []byte, off uint64) (n int, err error) {
+	span, err := chunkSpan(ch)
+	if err != nil {
+		return 0, err
+	}
+	payload := ch.Data()[SpanSize:]
+	if span <= swarm.ChunkSize {
+		if uint64(len(payload)) != span {
+			return 0, fmt.Errorf("chunk %s: %w", ch.Address(), ErrInvalidChunkData)
+		}
+		if off >= span {
+			return 0, nil
+		}
+		return copy(p, payload[off:]), nil
+	}
+
+	// every child holds the data of a full subtree except the last one,
+	// the condition is the same as childSpan*swarm.Branches < span, but
+	// it does not overflow for spans that no chunk tree can have
+	childSpan := uint64(swarm.ChunkSize)
+	for childSpan <= (span-1)/swarm.Branches {
+		childSpan *= swarm.Branches
+	}
+	if uint64(len(payload)) != (span+childSpan-1)/childSpan*swarm.HashSize {
+		return 0, fmt.Errorf("chunk %s: %w", ch.Address(), ErrInvalidChunkData)
+	}
+
+	for i := off / childSpan; n < len(p) && i*childSpan < span; i++ {
+		if err := r.ctx.Err(); err != nil {
+			return n, err
+		}
+		ref := swarm.NewAddress(payload[i*swarm.HashSize : (i+1)*swarm.HashSize])
+		child, err := r.store.Get(r.ctx, storage.ModeGetRequest, ref)
+		if err != nil {
+			return n, fmt.Errorf("get chunk %s: %w", ref, err)
+		}
+		childOff := off + uint64(n) - i*childSpan
+		q := p[n:]
+		if rest := childSpan - childOff; uint64(len(q)) > rest {
+			q = q[:rest]
+		}
+		m, err := r.readAt(child, q, childOff)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		if m == 0 {
+			return n, fmt.Errorf("chunk %s: %w", ref, ErrInvalidChunkData)
+		}
+	}
+	return n, nil
+}
+
+func chunkSpan(ch swarm.Chunk) (uint64, error) {
+	data := ch.Data()
+	if len(data) < SpanSize {
+		return 0, fmt.Errorf("chunk %s: %w", ch.Address(), ErrInvalidChunkData)
+	}
+	return binary.LittleEndian.Uint64(data[:SpanSize]), nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package file_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestReader reads data of a three level chunk tree.
+func TestReader(t *testing.T) {
+	ctx := context.Background()
+	store := mock.NewStorer()
+
+	// one full subtree of intermediate chunks and two data chunks
+	size := swarm.Branches*swarm.ChunkSize + swarm.ChunkSize + 100
+	data := make([]byte, size)
+	rand.Read(data)
+	root := putTestTree(t, store, data)
+
+	r, err := file.NewReader(ctx, store, root.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Size() != int64(size) {
+		t.Fatalf("got size %v, want %v", r.Size(), size)
+	}
+
+	got, err := ioutil.ReadAll(io.NewSectionReader(r, 0, r.Size()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("data does not match")
+	}
+
+	for _, tc := range []struct {
+		off, length int
+		err         error
+	}{
+		{off: 10, length: 20},
+		{off: swarm.ChunkSize - 10, length: 20},
+		{off: swarm.Branches*swarm.ChunkSize - 10, length: swarm.ChunkSize + 20},
+		{off: size - 10, length: 20, err: io.EOF},
+		{off: size, length: 1, err: io.EOF},
+	} {
+		p := make([]byte, tc.length)
+		n, err := r.ReadAt(p, int64(tc.off))
+		if !errors.Is(err, tc.err) {
+			t.Errorf("offset %v: got error %v, want %v", tc.off, err, tc.err)
+		}
+		end := tc.off + tc.length
+		if end > size {
+			end = size
+		}
+		if !bytes.Equal(p[:n], data[tc.off:end]) {
+			t.Errorf("offset %v: data does not match", tc.off)
+		}
+	}
+
+	t.Run("missing chunk", func(t *testing.T) {
+		missing := putTestChunk(t, store, 2*swarm.ChunkSize, make([]byte, 2*swarm.HashSize))
+		r, err := file.NewReader(ctx, store, missing.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.ReadAt(make([]byte, 10), 0); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+
+	t.Run("invalid intermediate chunk", func(t *testing.T) {
+		invalid := putTestChunk(t, store, 2*swarm.ChunkSize, make([]byte, swarm.HashSize+1))
+		r, err := file.NewReader(ctx, store, invalid.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.ReadAt(make([]byte, 10), 0); !errors.Is(err, file.ErrInvalidChunkData) {
+			t.Fatalf("got error %v, want %v", err, file.ErrInvalidChunkData)
+		}
+	})
+
+	t.Run("overflowing span", func(t *testing.T) {
+		invalid := putTestChunk(t, store, 1<<62+1, make([]byte, swarm.HashSize))
+		r, err := file.NewReader(ctx, store, invalid.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.ReadAt(make([]byte, 10), 0); !errors.Is(err, file.ErrInvalidChunkData) {
+			t.Fatalf("got error %v, want %v", err, file.ErrInvalidChunkData)
+		}
+	})
+}
+
+// putTestTree stores the chunk tree of the data
+// and returns its root chunk.
+func putTestTree(t *testing.T, store storage.Storer, data []byte) swarm.Chunk {
+	t.Helper()
+
+	var chunks []swarm.Chunk
+	for i := 0; i < len(data); i += swarm.ChunkSize {
+		end := i + swarm.ChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunks = append(chunks, putTestChunk(t, store, uint64(end-i), data[i:end]))
+	}
+	span := uint64(swarm.ChunkSize)
+	for len(chunks) > 1 {
+		span *= swarm.Branches
+		var parents []swarm.Chunk
+		for i := 0; i < len(chunks); i += swarm.Branches {
+			end := i + swarm.Branches
+			if end > len(chunks) {
+				end = len(chunks)
+			}
+			var refs []byte
+			for _, ch := range chunks[i:end] {
+				refs = append(refs, ch.Address().Bytes()...)
+			}
+			parentSpan := span
+			if rest := uint64(len(data) - i/swarm.Branches*int(span)); rest < parentSpan {
+				parentSpan = rest
+			}
+			parents = append(parents, putTestChunk(t, store, parentSpan, refs))
+		}
+		chunks = parents
+	}
+	return chunks[0]
+}
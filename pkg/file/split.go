@@ -0,0 +1,115 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// PutFunc is called for every chunk of the chunk tree created by Split.
+type PutFunc func(ctx context.Context, ch swarm.Chunk) error
+
+// reference is a reference to a subtree with the size of its data.
+type reference struct {
+	addr swarm.Address
+	span uint64
+}
+
+// Split reads data from the reader until io.EOF, splits it into a chunk
+// tree, and returns the address of its root chunk. The putFn is called
+// for data chunks in the order in which they are read, and for
+// intermediate chunks after all data is read. Intermediate chunks hold
+// references to up to swarm.Branches subtrees, all of which except the
+// last one are full, and a subtree with a single reference is replaced
+// by the referenced subtree.
+func Split(ctx context.Context, r io.Reader, putFn PutFunc) (swarm.Address, error) {
+	var refs []reference
+	buf := make([]byte, swarm.ChunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if errors.Is(err, io.EOF) && len(refs) > 0 {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return swarm.ZeroAddress, err
+		}
+		ref, err := putChunk(ctx, uint64(n), buf[:n], putFn)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		refs = append(refs, ref)
+		if n < swarm.ChunkSize {
+			break
+		}
+	}
+
+	for len(refs) > 1 {
+		parents := make([]reference, 0, (len(refs)+swarm.Branches-1)/swarm.Branches)
+		for i := 0; i < len(refs); i += swarm.Branches {
+			end := i + swarm.Branches
+			if end > len(refs) {
+				end = len(refs)
+			}
+			if end-i == 1 {
+				parents = append(parents, refs[i])
+				continue
+			}
+			var span uint64
+			payload := make([]byte, 0, (end-i)*swarm.HashSize)
+			for _, ref := range refs[i:end] {
+				span += ref.span
+				payload = append(payload, ref.addr.Bytes()...)
+			}
+			ref, err := putChunk(ctx, span, payload, putFn)
+			if err != nil {
+				return swarm.ZeroAddress, err
+			}
+			parents = append(parents, ref)
+		}
+		refs = parents
+	}
+	return refs[0].addr, nil
+}
+
+// putChunk calls putFn with the content addressed
+// chunk of the payload prefixed with the span.
+func putChunk(ctx context.Context, span uint64, payload []byte, putFn PutFunc) (reference, error) {
+	data := make([]byte, SpanSize+len(payload))
+	binary.LittleEndian.PutUint64(data, span)
+	copy(data[SpanSize:], payload)
+	addr, err := ContentAddress(data)
+	if err != nil {
+		return reference{}, err
+	}
+	if err := putFn(ctx, swarm.NewChunk(addr, data)); err != nil {
+		return reference{}, err
+	}
+	return reference{addr: addr, span: span}, nil
+}
+
+// ChunkCount returns the number of chunks in the
+// chunk tree that Split creates for data of the size.
+func ChunkCount(size int64) int64 {
+	n := (size + swarm.ChunkSize - 1) / swarm.ChunkSize
+	if n == 0 {
+		n = 1
+	}
+	count := n
+	for n > 1 {
+		parents := (n + swarm.Branches - 1) / swarm.Branches
+		count += parents
+		if n%swarm.Branches == 1 {
+			// the last subtree is not wrapped
+			count--
+		}
+		n = parents
+	}
+	return count
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package file_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestSplit splits data of different sizes and reads it back.
+func TestSplit(t *testing.T) {
+	ctx := context.Background()
+
+	for _, size := range []int{
+		0,
+		100,
+		swarm.ChunkSize,
+		swarm.ChunkSize + 1,
+		swarm.Branches * swarm.ChunkSize,
+		swarm.Branches*swarm.ChunkSize + 100,
+		(swarm.Branches+2)*swarm.ChunkSize + 100,
+	} {
+		store := mock.NewStorer()
+		data := make([]byte, size)
+		rand.Read(data)
+
+		var count int
+		root, err := file.Split(ctx, bytes.NewReader(data), func(ctx context.Context, ch swarm.Chunk) error {
+			if !file.NewContentAddressValidator().Validate(ch) {
+				t.Fatalf("size %v: invalid chunk %s", size, ch.Address())
+			}
+			count++
+			_, err := store.Put(ctx, storage.ModePutUpload, ch)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := file.ChunkCount(int64(size)); int64(count) != want {
+			t.Errorf("size %v: got %v chunks, want %v", size, count, want)
+		}
+
+		r, err := file.NewReader(ctx, store, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(io.NewSectionReader(r, 0, r.Size()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("size %v: data does not match", size)
+		}
+
+		var walked int
+		if err := file.Walk(ctx, store, root, func(swarm.Chunk) error {
+			walked++
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if walked != count {
+			t.Errorf("size %v: walked %v chunks, want %v", size, walked, count)
+		}
+	}
+}
@@ -8,41 +8,52 @@ import (
 	"hash"
 
 	"github.com/ethersphere/bee/pkg/swarm"
-	"github.com/ethersphere/bmt"
 	bmtlegacy "github.com/ethersphere/bmt/legacy"
 	"golang.org/x/crypto/sha3"
 )
 
 var _ swarm.ChunkValidator = (*ContentAddressValidator)(nil)
 
+// treePool is shared by hashers that compute content addresses,
+// which makes them safe for concurrent use. Trees have swarm.Branches
+// segments, which cover a whole chunk.
+var treePool = bmtlegacy.NewTreePool(hashFunc, swarm.Branches, bmtlegacy.PoolSize)
+
 func hashFunc() hash.Hash {
 	return sha3.NewLegacyKeccak256()
 }
 
-// ContentAddressValidator validates that the address of a given chunk 
-// is the content address of its contents
-type ContentAddressValidator struct {
-	hasher bmt.Hash
-	span   []byte
+// ContentAddress returns the BMT hash of the chunk data which is
+// prefixed with the span. It returns ErrInvalidChunkData if the data
+// is shorter than the span or longer than the span and the chunk size.
+func ContentAddress(data []byte) (swarm.Address, error) {
+	if len(data) < SpanSize || len(data) > SpanSize+swarm.ChunkSize {
+		return swarm.ZeroAddress, ErrInvalidChunkData
+	}
+	hasher := bmtlegacy.New(treePool)
+	hasher.Reset()
+	span := binary.LittleEndian.Uint64(data[:SpanSize])
+	hasher.SetSpan(int64(span))
+	if _, err := hasher.Write(data[SpanSize:]); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return swarm.NewAddress(hasher.Sum(nil)), nil
 }
 
+// ContentAddressValidator validates that the address of a given chunk
+// is the content address of its contents
+type ContentAddressValidator struct{}
+
 // New constructs a new ContentAddressValidator
 func NewContentAddressValidator() *ContentAddressValidator {
-	p := bmtlegacy.NewTreePool(hashFunc, swarm.SectionSize, bmtlegacy.PoolSize)
-	return &ContentAddressValidator{
-		hasher: bmtlegacy.New(p),
-		span:   make([]byte, 8),
-	}
+	return &ContentAddressValidator{}
 }
 
 // Validate performs the validation check
 func (v *ContentAddressValidator) Validate(ch swarm.Chunk) (valid bool) {
-	v.hasher.Reset()
-	data := ch.Data()
-	address := ch.Address()
-	span := binary.LittleEndian.Uint64(data[:8])
-	v.hasher.SetSpan(int64(span))
-	v.hasher.Write(data[8:])
-	s := v.hasher.Sum(nil)
-	return address.Equal(swarm.NewAddress(s))
+	address, err := ContentAddress(ch.Data())
+	if err != nil {
+		return false
+	}
+	return ch.Address().Equal(address)
 }
@@ -5,6 +5,7 @@ package file_test
 
 import (
 	"encoding/binary"
+	"errors"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/file"
@@ -19,7 +20,8 @@ func TestContentAddressValidator(t *testing.T) {
 	validator := file.NewContentAddressValidator()
 
 	// generate address from pre-generated hex of 'foo' from legacy bmt
-	bmtHashOfFoo := "b9d678ef39fa973b430795a1f04e0f2541b47c996fd300552a1e8bfb5824325f"
+	// with swarm.Branches segments, the tree of a 4096 byte chunk
+	bmtHashOfFoo := "2387e8e7d8a48c2a9339c97c1dc3461a9a7aa07e994c5cb8b38fd7c1b3e6ea48"
 	address := swarm.MustParseHexAddress(bmtHashOfFoo)
 
 	// set up a chunk object with correct expected length prefix
@@ -40,3 +42,30 @@ func TestContentAddressValidator(t *testing.T) {
 		t.Fatalf("data '%s' should not have validated to hash '%x'", ch.Data(), ch.Address())
 	}
 }
+
+// TestContentAddress checks that addresses are computed from the whole
+// chunk data and that invalid data lengths are rejected.
+func TestContentAddress(t *testing.T) {
+	data := make([]byte, file.SpanSize+swarm.ChunkSize)
+	binary.LittleEndian.PutUint64(data, swarm.ChunkSize)
+	first, err := file.ContentAddress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// change the last byte of the payload
+	data[len(data)-1] = 1
+	second, err := file.ContentAddress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Equal(second) {
+		t.Fatalf("got equal addresses %s for different data", first)
+	}
+
+	for _, size := range []int{0, file.SpanSize - 1, file.SpanSize + swarm.ChunkSize + 1} {
+		if _, err := file.ContentAddress(make([]byte, size)); !errors.Is(err, file.ErrInvalidChunkData) {
+			t.Errorf("size %v: got error %v, want %v", size, err, file.ErrInvalidChunkData)
+		}
+	}
+}
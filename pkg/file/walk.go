@@ -0,0 +1,66 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// SpanSize is the size of the little endian encoded span
+// that prefixes the data of every chunk.
+const SpanSize = 8
+
+// ErrInvalidChunkData is returned when the chunk data is too short
+// or intermediate chunk data is not a list of references.
+var ErrInvalidChunkData = errors.New("invalid chunk data")
+
+// WalkFunc is called for every chunk of the chunk tree visited by Walk.
+type WalkFunc func(ch swarm.Chunk) error
+
+// Walk calls walkFn for every chunk of the chunk tree with the provided root
+// address, in depth-first order, starting with the root chunk. Chunks are
+// considered intermediate if their span is larger than the chunk size, in
+// which case their payload is treated as a list of child references.
+func Walk(ctx context.Context, s storage.Storer, root swarm.Address, walkFn WalkFunc) error {
+	ch, err := s.Get(ctx, storage.ModeGetLookup, root)
+	if err != nil {
+		return fmt.Errorf("get chunk %s: %w", root, err)
+	}
+	if err := walkFn(ch); err != nil {
+		return err
+	}
+
+	data := ch.Data()
+	if len(data) < SpanSize {
+		return fmt.Errorf("chunk %s: %w", root, ErrInvalidChunkData)
+	}
+	span := binary.LittleEndian.Uint64(data[:SpanSize])
+	if span <= swarm.ChunkSize {
+		return nil
+	}
+
+	refs := data[SpanSize:]
+	if len(refs)%swarm.HashSize != 0 {
+		return fmt.Errorf("chunk %s: %w", root, ErrInvalidChunkData)
+	}
+	for i := 0; i < len(refs); i += swarm.HashSize {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		ref := swarm.NewAddress(refs[i : i+swarm.HashSize])
+		if err := Walk(ctx, s, ref, walkFn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package file_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestWalk checks that all chunks of a two level chunk tree are visited
+// in depth-first order.
+func TestWalk(t *testing.T) {
+	ctx := context.Background()
+	store := mock.NewStorer()
+
+	want := make([]swarm.Address, 1)
+	var refs []byte
+	for i := 0; i < 3; i++ {
+		leaf := putTestChunk(t, store, swarm.ChunkSize, make([]byte, swarm.ChunkSize))
+		refs = append(refs, leaf.Address().Bytes()...)
+		want = append(want, leaf.Address())
+	}
+	root := putTestChunk(t, store, 3*swarm.ChunkSize, refs)
+	want[0] = root.Address()
+
+	var got []swarm.Address
+	err := file.Walk(ctx, store, root.Address(), func(ch swarm.Chunk) error {
+		got = append(got, ch.Address())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v chunks, want %v", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("chunk %v: got %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	t.Run("missing chunk", func(t *testing.T) {
+		missing := putTestChunk(t, store, 2*swarm.ChunkSize, make([]byte, 2*swarm.HashSize))
+		err := file.Walk(ctx, store, missing.Address(), func(swarm.Chunk) error { return nil })
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+
+	t.Run("invalid intermediate chunk", func(t *testing.T) {
+		invalid := putTestChunk(t, store, 2*swarm.ChunkSize, make([]byte, swarm.HashSize+1))
+		err := file.Walk(ctx, store, invalid.Address(), func(swarm.Chunk) error { return nil })
+		if !errors.Is(err, file.ErrInvalidChunkData) {
+			t.Fatalf("got error %v, want %v", err, file.ErrInvalidChunkData)
+		}
+	})
+}
+
+// putTestChunk stores a chunk with the provided span and payload under
+// a random address.
+func putTestChunk(t *testing.T, store storage.Storer, span uint64, payload []byte) swarm.Chunk {
+	t.Helper()
+
+	data := make([]byte, file.SpanSize+len(payload))
+	binary.LittleEndian.PutUint64(data, span)
+	copy(data[file.SpanSize:], payload)
+	addr := make([]byte, swarm.HashSize)
+	rand.Read(addr)
+	ch := swarm.NewChunk(swarm.NewAddress(addr), data)
+	if _, err := store.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
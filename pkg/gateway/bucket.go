@@ -0,0 +1,58 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gateway
+
+import "time"
+
+// bucket is a token bucket that is refilled at the rate up to the
+// burst. Tokens can be taken even if there are not enough of them,
+// leaving the bucket in debt until it is refilled, which allows
+// limiting bytes that are counted only after they are transferred.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate, burst float64, now time.Time) *bucket {
+	return &bucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if d := now.Sub(b.last); d > 0 {
+		b.tokens += d.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// wait returns the duration until n tokens are available.
+func (b *bucket) wait(now time.Time, n float64) time.Duration {
+	b.refill(now)
+	if b.tokens >= n || b.rate <= 0 {
+		return 0
+	}
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
+
+// take removes n tokens from the bucket.
+func (b *bucket) take(now time.Time, n float64) {
+	b.refill(now)
+	b.tokens -= n
+}
+
+// full returns true if the bucket is refilled up to the burst.
+func (b *bucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
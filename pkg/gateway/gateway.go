@@ -0,0 +1,345 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gateway limits access of clients to a publicly exposed API. It
+// denies writes from clients that are not allowed to write, limits the
+// rate of requests and transferred bytes of every client with token
+// buckets, and enforces daily upload quotas. Quotas are counted in memory
+// and kept in the state store.
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	quotaKeyPrefix = "gateway_quota_"
+	dayFormat      = "20060102"
+
+	// sweepInterval is the period after which buckets of
+	// idle clients are removed, counted quotas are written
+	// to the state store and old quotas deleted.
+	sweepInterval = time.Minute
+)
+
+var (
+	ErrWriteDenied   = errors.New("writes are not allowed")
+	ErrTooManyReqs   = errors.New("too many requests")
+	ErrTooManyBytes  = errors.New("bandwidth limit exceeded")
+	ErrQuotaExceeded = errors.New("daily upload quota exceeded")
+)
+
+// Options are limits of the Gateway. Zero values disable the limits.
+type Options struct {
+	// WriteAllowlist contains networks of clients that are allowed
+	// to write, all writes are denied if it is empty.
+	WriteAllowlist []*net.IPNet
+	// RequestRate is the number of requests per second and
+	// RequestBurst the number of requests above the rate.
+	RequestRate  float64
+	RequestBurst int
+	// ByteRate is the number of transferred bytes per second
+	// and ByteBurst the number of bytes above the rate.
+	ByteRate  float64
+	ByteBurst int64
+	// DailyUploadQuota is the number of bytes a client can
+	// upload in a day, starting at midnight UTC.
+	DailyUploadQuota uint64
+}
+
+// Gateway tracks and limits requests of clients.
+type Gateway struct {
+	store   storage.StateStorer
+	options Options
+	logger  logging.Logger
+	metrics metrics
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	uploads   map[string]*upload
+	lastSweep time.Time
+	day       string
+}
+
+// client holds token buckets of a single client.
+type client struct {
+	requests *bucket
+	bytes    *bucket
+}
+
+// upload counts bytes uploaded by a single client in a day,
+// dirty is set if they are not written to the state store.
+type upload struct {
+	day   string
+	bytes uint64
+	dirty bool
+}
+
+type quota struct {
+	Bytes uint64 `json:"bytes"`
+}
+
+// New constructs a new Gateway that keeps quotas in the store.
+func New(store storage.StateStorer, o Options, logger logging.Logger) *Gateway {
+	if o.RequestBurst < 1 {
+		o.RequestBurst = 1
+	}
+	if o.ByteBurst < 1 {
+		o.ByteBurst = 1
+	}
+	return &Gateway{
+		store:   store,
+		options: o,
+		logger:  logger,
+		metrics: newMetrics(),
+		clients: make(map[string]*client),
+		uploads: make(map[string]*upload),
+	}
+}
+
+// ParseNetworks returns networks from IP addresses and
+// CIDR notations.
+func ParseNetworks(values []string) (networks []*net.IPNet, err error) {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip address %q", v)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+// Allow returns an error if the client request is not allowed, and
+// the duration after which it may be retried if it is rate limited.
+// Tokens of the request bucket are taken only for allowed requests.
+func (g *Gateway) Allow(ip net.IP, write bool) (retryAfter time.Duration, err error) {
+	if write && !g.writeAllowed(ip) {
+		g.metrics.WriteDeniedCount.Inc()
+		return 0, ErrWriteDenied
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.sweep(now)
+	c := g.client(ip)
+
+	if g.options.ByteRate > 0 {
+		if d := c.bytes.wait(now, 1); d > 0 {
+			g.metrics.BytesLimitedCount.Inc()
+			return d, ErrTooManyBytes
+		}
+	}
+	if write && g.options.DailyUploadQuota > 0 {
+		u, err := g.upload(ip, now)
+		if err != nil {
+			return 0, err
+		}
+		if u.bytes >= g.options.DailyUploadQuota {
+			g.metrics.QuotaExceededCount.Inc()
+			return nextDay(now).Sub(now), ErrQuotaExceeded
+		}
+	}
+	if g.options.RequestRate > 0 {
+		if d := c.requests.wait(now, 1); d > 0 {
+			g.metrics.RequestsLimitedCount.Inc()
+			return d, ErrTooManyReqs
+		}
+		c.requests.take(now, 1)
+	}
+	return 0, nil
+}
+
+// Transfer records bytes that are received and sent by an allowed
+// client request while it is served. It takes them from the byte
+// bucket, and it counts received bytes of write requests in the daily
+// upload quota at once, so that long or concurrent requests of the
+// client can not go past it. It returns ErrTooManyBytes or
+// ErrQuotaExceeded when a limit is exceeded and the request must not
+// transfer more bytes.
+func (g *Gateway) Transfer(ip net.IP, write bool, received, sent int64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.sweep(now)
+
+	if g.options.ByteRate > 0 && received+sent > 0 {
+		b := g.client(ip).bytes
+		if b.take(now, float64(received+sent)); b.tokens < 0 {
+			g.metrics.BytesLimitedCount.Inc()
+			return ErrTooManyBytes
+		}
+	}
+	if write && g.options.DailyUploadQuota > 0 && received > 0 {
+		u, err := g.upload(ip, now)
+		if err != nil {
+			g.logger.Debugf("gateway: count upload quota of %s: %v", ip, err)
+			g.logger.Error("gateway: count upload quota")
+			return nil
+		}
+		u.bytes += uint64(received)
+		u.dirty = true
+		if u.bytes > g.options.DailyUploadQuota {
+			g.metrics.QuotaExceededCount.Inc()
+			return ErrQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// Flush writes the counted upload quota of the client to the state
+// store. It is called when a request of the client is served, quotas
+// of requests that are still served are written periodically.
+func (g *Gateway) Flush(ip net.IP) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if u, ok := g.uploads[ip.String()]; ok {
+		g.flush(ip.String(), u)
+	}
+}
+
+func (g *Gateway) writeAllowed(ip net.IP) bool {
+	for _, n := range g.options.WriteAllowlist {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// client returns buckets of the client, creating them if needed.
+// It must be called with the lock held.
+func (g *Gateway) client(ip net.IP) *client {
+	key := ip.String()
+	c, ok := g.clients[key]
+	if !ok {
+		now := time.Now()
+		c = &client{
+			requests: newBucket(g.options.RequestRate, float64(g.options.RequestBurst), now),
+			bytes:    newBucket(g.options.ByteRate, float64(g.options.ByteBurst), now),
+		}
+		g.clients[key] = c
+		g.metrics.ClientCount.Set(float64(len(g.clients)))
+	}
+	return c
+}
+
+// upload returns the counted upload quota of the client for the
+// current day, reading it from the state store if needed.
+// It must be called with the lock held.
+func (g *Gateway) upload(ip net.IP, now time.Time) (*upload, error) {
+	key := ip.String()
+	day := now.UTC().Format(dayFormat)
+	if u, ok := g.uploads[key]; ok && u.day == day {
+		return u, nil
+	}
+	var q quota
+	if err := g.store.Get(quotaKey(day, key), &q); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("get upload quota: %w", err)
+	}
+	u := &upload{day: day, bytes: q.Bytes}
+	g.uploads[key] = u
+	return u, nil
+}
+
+// flush writes the upload quota of the client if it is dirty.
+// It must be called with the lock held.
+func (g *Gateway) flush(key string, u *upload) {
+	if !u.dirty {
+		return
+	}
+	if err := g.store.Put(quotaKey(u.day, key), quota{Bytes: u.bytes}); err != nil {
+		g.logger.Debugf("gateway: put upload quota of %s: %v", key, err)
+		g.logger.Error("gateway: put upload quota")
+		return
+	}
+	u.dirty = false
+}
+
+func quotaKey(day, ip string) string {
+	return quotaKeyPrefix + day + "_" + ip
+}
+
+// sweep removes clients with full buckets, as they are equal
+// to new ones, writes counted upload quotas to the state store
+// and deletes quotas of previous days.
+// It must be called with the lock held.
+func (g *Gateway) sweep(now time.Time) {
+	if now.Sub(g.lastSweep) < sweepInterval {
+		return
+	}
+	g.lastSweep = now
+
+	for key, c := range g.clients {
+		if c.requests.full(now) && c.bytes.full(now) {
+			delete(g.clients, key)
+		}
+	}
+	g.metrics.ClientCount.Set(float64(len(g.clients)))
+
+	day := now.UTC().Format(dayFormat)
+	for key, u := range g.uploads {
+		if u.day == day {
+			g.flush(key, u)
+		}
+		// written quotas are read again when they are needed
+		if !u.dirty || u.day != day {
+			delete(g.uploads, key)
+		}
+	}
+
+	if day == g.day {
+		return
+	}
+	var old []string
+	if err := g.store.Iterate(quotaKeyPrefix, func(key, _ []byte) (bool, error) {
+		if !strings.HasPrefix(string(key), quotaKeyPrefix+day) {
+			old = append(old, string(key))
+		}
+		return false, nil
+	}); err != nil {
+		g.logger.Debugf("gateway: iterate upload quotas: %v", err)
+		g.logger.Error("gateway: iterate upload quotas")
+		return
+	}
+	for _, key := range old {
+		if err := g.store.Delete(key); err != nil {
+			g.logger.Debugf("gateway: delete upload quota %s: %v", key, err)
+			g.logger.Error("gateway: delete upload quota")
+			return
+		}
+	}
+	g.day = day
+}
+
+// nextDay returns the next midnight in UTC.
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
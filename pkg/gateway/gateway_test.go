@@ -0,0 +1,198 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gateway_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/gateway"
+	"github.com/ethersphere/bee/pkg/logging"
+	mockstate "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+var logger = logging.New(ioutil.Discard, 0)
+
+func TestGateway_writes(t *testing.T) {
+	allowlist, err := gateway.ParseNetworks([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := gateway.New(mockstate.NewStateStore(), gateway.Options{
+		WriteAllowlist: allowlist,
+	}, logger)
+
+	for ip, want := range map[string]error{
+		"10.1.2.3":    nil,
+		"192.168.1.1": nil,
+		"192.168.1.2": gateway.ErrWriteDenied,
+		"::1":         gateway.ErrWriteDenied,
+	} {
+		if _, err := g.Allow(net.ParseIP(ip), true); !errors.Is(err, want) {
+			t.Errorf("%s: got error %v, want %v", ip, err, want)
+		}
+		// reads are always allowed
+		if _, err := g.Allow(net.ParseIP(ip), false); err != nil {
+			t.Errorf("%s: got read error %v", ip, err)
+		}
+	}
+
+	if _, err := gateway.ParseNetworks([]string{"invalid"}); err == nil {
+		t.Error("expected error for invalid network")
+	}
+}
+
+func TestGateway_requestRate(t *testing.T) {
+	g := gateway.New(mockstate.NewStateStore(), gateway.Options{
+		RequestRate:  1.0 / 3600,
+		RequestBurst: 2,
+	}, logger)
+
+	ip := net.ParseIP("1.2.3.4")
+	for i := 0; i < 2; i++ {
+		if _, err := g.Allow(ip, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	retryAfter, err := g.Allow(ip, false)
+	if !errors.Is(err, gateway.ErrTooManyReqs) {
+		t.Fatalf("got error %v, want %v", err, gateway.ErrTooManyReqs)
+	}
+	if retryAfter <= 0 || retryAfter > time.Hour {
+		t.Errorf("got retry after %v, want at most an hour", retryAfter)
+	}
+
+	// other clients are not limited
+	if _, err := g.Allow(net.ParseIP("1.2.3.5"), false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGateway_byteRate(t *testing.T) {
+	g := gateway.New(mockstate.NewStateStore(), gateway.Options{
+		ByteRate:  1,
+		ByteBurst: 100,
+	}, logger)
+
+	ip := net.ParseIP("1.2.3.4")
+	if _, err := g.Allow(ip, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Transfer(ip, false, 10, 80); err != nil {
+		t.Fatal(err)
+	}
+	// the request is stopped once the bucket is empty
+	if err := g.Transfer(ip, false, 0, 910); !errors.Is(err, gateway.ErrTooManyBytes) {
+		t.Fatalf("got error %v, want %v", err, gateway.ErrTooManyBytes)
+	}
+
+	retryAfter, err := g.Allow(ip, false)
+	if !errors.Is(err, gateway.ErrTooManyBytes) {
+		t.Fatalf("got error %v, want %v", err, gateway.ErrTooManyBytes)
+	}
+	if retryAfter < 900*time.Second {
+		t.Errorf("got retry after %v, want at least 900s", retryAfter)
+	}
+}
+
+func TestGateway_uploadQuota(t *testing.T) {
+	store := mockstate.NewStateStore()
+	allowlist, err := gateway.ParseNetworks([]string{"1.2.3.4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := gateway.Options{
+		WriteAllowlist:   allowlist,
+		DailyUploadQuota: 100,
+	}
+	g := gateway.New(store, o, logger)
+
+	ip := net.ParseIP("1.2.3.4")
+	if _, err := g.Allow(ip, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Transfer(ip, true, 100, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// quotas are counted in memory until they are flushed
+	if n := storedQuotas(t, store); n != 0 {
+		t.Fatalf("got %v stored quotas before flush, want none", n)
+	}
+	g.Flush(ip)
+	if n := storedQuotas(t, store); n != 1 {
+		t.Fatalf("got %v stored quotas after flush, want 1", n)
+	}
+
+	// quotas are kept in the state store
+	g = gateway.New(store, o, logger)
+	retryAfter, err := g.Allow(ip, true)
+	if !errors.Is(err, gateway.ErrQuotaExceeded) {
+		t.Fatalf("got error %v, want %v", err, gateway.ErrQuotaExceeded)
+	}
+	if retryAfter <= 0 || retryAfter > 24*time.Hour {
+		t.Errorf("got retry after %v, want at most a day", retryAfter)
+	}
+
+	// reads are not affected by the quota
+	if _, err := g.Allow(ip, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGateway_uploadQuotaTransfer(t *testing.T) {
+	allowlist, err := gateway.ParseNetworks([]string{"1.2.3.4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := gateway.New(mockstate.NewStateStore(), gateway.Options{
+		WriteAllowlist:   allowlist,
+		DailyUploadQuota: 100,
+	}, logger)
+
+	// concurrent requests are allowed before they upload
+	ip := net.ParseIP("1.2.3.4")
+	for i := 0; i < 2; i++ {
+		if _, err := g.Allow(ip, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// received bytes are added to the quota as they
+	// arrive, so that both requests can not use all of it
+	if err := g.Transfer(ip, true, 60, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Transfer(ip, true, 30, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Transfer(ip, true, 30, 0); !errors.Is(err, gateway.ErrQuotaExceeded) {
+		t.Fatalf("got error %v, want %v", err, gateway.ErrQuotaExceeded)
+	}
+	if err := g.Transfer(ip, true, 1, 0); !errors.Is(err, gateway.ErrQuotaExceeded) {
+		t.Fatalf("got error %v, want %v", err, gateway.ErrQuotaExceeded)
+	}
+
+	// bytes of reads are not added to the quota
+	if err := g.Transfer(ip, false, 1000, 1000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func storedQuotas(t *testing.T, store storage.StateStorer) (n int) {
+	t.Helper()
+
+	if err := store.Iterate("gateway_quota_", func(_, _ []byte) (bool, error) {
+		n++
+		return false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gateway
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	WriteDeniedCount     prometheus.Counter
+	RequestsLimitedCount prometheus.Counter
+	BytesLimitedCount    prometheus.Counter
+	QuotaExceededCount   prometheus.Counter
+	ClientCount          prometheus.Gauge
+}
+
+func newMetrics() metrics {
+	subsystem := "gateway"
+
+	return metrics{
+		WriteDeniedCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "write_denied_count",
+			Help:      "Number of rejected write requests from clients that are not allowed to write.",
+		}),
+		RequestsLimitedCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "requests_limited_count",
+			Help:      "Number of requests rejected by the request rate limit.",
+		}),
+		BytesLimitedCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "bytes_limited_count",
+			Help:      "Number of requests rejected by the bandwidth limit.",
+		}),
+		QuotaExceededCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "quota_exceeded_count",
+			Help:      "Number of write requests rejected by the daily upload quota.",
+		}),
+		ClientCount: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "client_count",
+			Help:      "Number of clients with tracked rate limits.",
+		}),
+	}
+}
+
+func (g *Gateway) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(g.metrics)
+}
@@ -16,7 +16,13 @@ import (
 func ResponseDirect(t *testing.T, client *http.Client, method, url string, body io.Reader, responseCode int, response interface{}) {
 	t.Helper()
 
-	resp := request(t, client, method, url, body, responseCode)
+	ResponseDirectWithHeaders(t, client, method, url, body, responseCode, response, nil)
+}
+
+func ResponseDirectWithHeaders(t *testing.T, client *http.Client, method, url string, body io.Reader, responseCode int, response interface{}, headers http.Header) {
+	t.Helper()
+
+	resp := request(t, client, method, url, body, responseCode, headers)
 	defer resp.Body.Close()
 
 	got, err := ioutil.ReadAll(resp.Body)
@@ -38,7 +44,7 @@ func ResponseDirect(t *testing.T, client *http.Client, method, url string, body
 func ResponseUnmarshal(t *testing.T, client *http.Client, method, url string, body io.Reader, responseCode int, response interface{}) {
 	t.Helper()
 
-	resp := request(t, client, method, url, body, responseCode)
+	resp := request(t, client, method, url, body, responseCode, nil)
 	defer resp.Body.Close()
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
@@ -46,13 +52,16 @@ func ResponseUnmarshal(t *testing.T, client *http.Client, method, url string, bo
 	}
 }
 
-func request(t *testing.T, client *http.Client, method, url string, body io.Reader, responseCode int) *http.Response {
+func request(t *testing.T, client *http.Client, method, url string, body io.Reader, responseCode int, headers http.Header) *http.Response {
 	t.Helper()
 
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		t.Fatal(err)
 	}
+	for k, v := range headers {
+		req.Header[k] = v
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		t.Fatal(err)
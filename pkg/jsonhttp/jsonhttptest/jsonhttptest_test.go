@@ -21,10 +21,11 @@ func TestResponse(t *testing.T) {
 	}
 	message := "text"
 
-	wantMethod, wantPath, wantBody := http.MethodPatch, "/testing", "request body"
-	var gotMethod, gotPath, gotBody string
+	wantMethod, wantPath, wantBody, wantHeader := http.MethodPatch, "/testing", "request body", "header value"
+	var gotMethod, gotPath, gotBody, gotHeader string
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		gotMethod = r.Method
+		gotHeader = r.Header.Get("X-Test")
 		gotPath = r.URL.Path
 		b, err := ioutil.ReadAll(r.Body)
 		if err != nil {
@@ -56,6 +57,18 @@ func TestResponse(t *testing.T) {
 		}
 	})
 
+	t.Run("direct with headers", func(t *testing.T) {
+		jsonhttptest.ResponseDirectWithHeaders(t, c, wantMethod, s.URL+wantPath, strings.NewReader(wantBody), http.StatusCreated, response{
+			Message: message,
+		}, http.Header{
+			"X-Test": []string{wantHeader},
+		})
+
+		if gotHeader != wantHeader {
+			t.Errorf("got header %s, want %s", gotHeader, wantHeader)
+		}
+	})
+
 	t.Run("unmarshal", func(t *testing.T) {
 		var r response
 		jsonhttptest.ResponseUnmarshal(t, c, wantMethod, s.URL+wantPath, strings.NewReader(wantBody), http.StatusCreated, &r)
@@ -0,0 +1,159 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package listener provides network listeners for HTTP servers, on TCP
+// addresses or Unix domain sockets, optionally with TLS certificates
+// that can be reloaded while the listener is serving.
+package listener
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// UnixPrefix is the prefix of addresses of Unix domain sockets, which
+// is followed by the path of the socket file.
+const UnixPrefix = "unix:"
+
+// DefaultSocketMode are permissions of socket files
+// when they are not set in Options.
+const DefaultSocketMode os.FileMode = 0600
+
+// Options are listener parameters.
+type Options struct {
+	// TLSCertFile and TLSKeyFile are paths to PEM encoded files with
+	// the certificate chain and the private key. Connections are not
+	// encrypted if they are not set.
+	TLSCertFile string
+	TLSKeyFile  string
+	// SocketMode are permissions of the socket file
+	// if the listener is on a Unix domain socket.
+	SocketMode os.FileMode
+}
+
+// Listener accepts connections on a TCP address or a Unix
+// domain socket, encrypted with TLS if it is configured.
+type Listener struct {
+	net.Listener
+	certificate *certificate // nil if TLS is not used
+}
+
+// New constructs a new Listener on the address, which is a TCP
+// address or a path to the Unix domain socket prefixed with UnixPrefix.
+func New(addr string, o Options) (*Listener, error) {
+	var cert *certificate
+	if o.TLSCertFile != "" || o.TLSKeyFile != "" {
+		if o.TLSCertFile == "" || o.TLSKeyFile == "" {
+			return nil, errors.New("both tls certificate and key files are required")
+		}
+		cert = &certificate{
+			certFile: o.TLSCertFile,
+			keyFile:  o.TLSKeyFile,
+		}
+		if err := cert.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		l   net.Listener
+		err error
+	)
+	if path := strings.TrimPrefix(addr, UnixPrefix); path != addr {
+		l, err = listenUnix(path, o.SocketMode)
+	} else {
+		l, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cert != nil {
+		l = tls.NewListener(l, &tls.Config{
+			GetCertificate: cert.get,
+			MinVersion:     tls.VersionTLS12,
+		})
+	}
+	return &Listener{
+		Listener:    l,
+		certificate: cert,
+	}, nil
+}
+
+// TLS returns true if connections are encrypted.
+func (l *Listener) TLS() bool {
+	return l.certificate != nil
+}
+
+// Reload loads the certificate and the key from their files again.
+// New connections use them only if both files are valid. It does
+// nothing if TLS is not used.
+func (l *Listener) Reload() error {
+	if l.certificate == nil {
+		return nil
+	}
+	return l.certificate.load()
+}
+
+// listenUnix listens on the Unix domain socket, replacing the socket
+// file that is left by a listener which was not closed.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if mode == 0 {
+		mode = DefaultSocketMode
+	}
+	if fi, err := os.Stat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and it is not a socket", path)
+		}
+		if c, err := net.Dial("unix", path); err == nil {
+			c.Close()
+			return nil, fmt.Errorf("%s is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("set socket permissions: %w", err)
+	}
+	return l, nil
+}
+
+// certificate holds the loaded certificate
+// that is presented to clients.
+type certificate struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func (c *certificate) load() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("load tls certificate: %w", err)
+	}
+	c.mu.Lock()
+	c.cert = &cert
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *certificate) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
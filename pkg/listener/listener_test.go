@@ -0,0 +1,208 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package listener_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/listener"
+)
+
+func TestListener_tls(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeCertificate(t, certFile, keyFile, 1)
+
+	l, err := listener.New("127.0.0.1:0", listener.Options{
+		TLSCertFile: certFile,
+		TLSKeyFile:  keyFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.TLS() {
+		t.Fatal("tls not used")
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})}
+	go func() { _ = server.Serve(l) }()
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+	}
+	serial := func(t *testing.T) int64 {
+		t.Helper()
+
+		resp, err := client.Get("https://" + l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	if got := serial(t); got != 1 {
+		t.Fatalf("got certificate %v, want 1", got)
+	}
+
+	// invalid files are not loaded
+	if err := ioutil.WriteFile(keyFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Reload(); err == nil {
+		t.Fatal("expected reload error")
+	}
+	if got := serial(t); got != 1 {
+		t.Fatalf("got certificate %v, want 1", got)
+	}
+
+	writeCertificate(t, certFile, keyFile, 2)
+	if err := l.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := serial(t); got != 2 {
+		t.Fatalf("got certificate %v, want 2", got)
+	}
+
+	if _, err := listener.New("127.0.0.1:0", listener.Options{TLSCertFile: certFile}); err == nil {
+		t.Fatal("expected error without key file")
+	}
+}
+
+func TestListener_unix(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "api.sock")
+	l, err := listener.New(listener.UnixPrefix+path, listener.Options{
+		SocketMode: 0660,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		t.Errorf("got file mode %v, want socket", fi.Mode())
+	}
+	if perm := fi.Mode().Perm(); perm != 0660 {
+		t.Errorf("got permissions %v, want %v", perm, os.FileMode(0660))
+	}
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})}
+	go func() { _ = server.Serve(l) }()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+	resp, err := client.Get("http://bee/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot {
+		t.Errorf("got status %v, want %v", resp.StatusCode, http.StatusTeapot)
+	}
+
+	// sockets in use are not replaced
+	if _, err := listener.New(listener.UnixPrefix+path, listener.Options{}); err == nil {
+		t.Fatal("expected error for socket in use")
+	}
+	server.Close()
+
+	// sockets that are left behind are replaced
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+	l, err = listener.New(listener.UnixPrefix+path, listener.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	// other files are not replaced
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listener.New(listener.UnixPrefix+file, listener.Options{}); err == nil {
+		t.Fatal("expected error for regular file")
+	}
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "bee-listener")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// writeCertificate writes a self-signed certificate
+// with the serial number and its key to the files.
+func writeCertificate(t *testing.T, certFile, keyFile string, serial int64) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "bee"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
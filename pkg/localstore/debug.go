@@ -0,0 +1,151 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ChunkInfo is the state of a chunk in the database. Timestamps are
+// unix times in nanoseconds, as they are kept in the indexes, and are
+// zero if they are not known.
+type ChunkInfo struct {
+	Address swarm.Address `json:"address"`
+	// Indexes are names of indexes that hold the chunk.
+	Indexes         []string `json:"indexes"`
+	StoreTimestamp  int64    `json:"storeTimestamp"`
+	AccessTimestamp int64    `json:"accessTimestamp"`
+	BinID           uint64   `json:"binID"`
+	// Bin is the proximity order of the chunk to the base key.
+	Bin        uint8  `json:"bin"`
+	PinCounter uint64 `json:"pinCounter"`
+	// Tag is the uid of the tag from the push or the pull index.
+	Tag uint32 `json:"tag"`
+	// PushSynced is true if the chunk is not in the push index, either
+	// because it is synced or because it was not uploaded to this node.
+	PushSynced bool `json:"pushSynced"`
+	// PullSyncable is true if the chunk is in the pull index and is
+	// offered to peers that sync the bin.
+	PullSyncable bool `json:"pullSyncable"`
+	// GCEligible is true if the chunk is in the gc index and not
+	// excluded from garbage collection by pinning. Eligible chunks
+	// in the reserve are collected only when it exceeds its capacity.
+	GCEligible bool `json:"gcEligible"`
+	Reserve    bool `json:"reserve"`
+}
+
+// DebugChunk returns the state of the chunk in all indexes, so that it
+// can be found out why it is missing or not synced. Indexes with keys
+// that depend on the store timestamp or the bin id are checked only if
+// the chunk is in the retrieval data index. It returns
+// storage.ErrNotFound if no index holds the chunk.
+func (db *DB) DebugChunk(addr swarm.Address) (info *ChunkInfo, err error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	po := db.po(addr)
+	info = &ChunkInfo{
+		Address:    addr,
+		Indexes:    make([]string, 0),
+		Bin:        po,
+		PushSynced: true,
+		Reserve:    po >= db.ReserveRadius(),
+	}
+	item := addressToItem(addr)
+
+	// get returns the item from the index and records
+	// that the index holds it, if it is found
+	get := func(name string, index shed.Index, item shed.Item) (i shed.Item, ok bool, err error) {
+		i, err = index.Get(item)
+		if err != nil {
+			if errors.Is(err, shed.ErrNotFound) {
+				return i, false, nil
+			}
+			return i, false, err
+		}
+		info.Indexes = append(info.Indexes, name)
+		return i, true, nil
+	}
+
+	i, hasData, err := get("retrievalDataIndex", db.retrievalDataIndex, item)
+	if err != nil {
+		return nil, err
+	}
+	if hasData {
+		item.StoreTimestamp = i.StoreTimestamp
+		item.BinID = i.BinID
+		info.StoreTimestamp = i.StoreTimestamp
+		info.BinID = i.BinID
+	}
+	i, hasAccess, err := get("retrievalAccessIndex", db.retrievalAccessIndex, item)
+	if err != nil {
+		return nil, err
+	}
+	if hasAccess {
+		item.AccessTimestamp = i.AccessTimestamp
+		info.AccessTimestamp = i.AccessTimestamp
+	}
+	i, ok, err := get("pinIndex", db.pinIndex, item)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		info.PinCounter = i.PinCounter
+	}
+	excluded, err := db.gcExcludeIndex.Has(item)
+	if err != nil {
+		return nil, err
+	}
+	if excluded {
+		info.Indexes = append(info.Indexes, "gcExcludeIndex")
+	}
+	if _, _, err := get("postageIndex", db.postageIndex, item); err != nil {
+		return nil, err
+	}
+
+	if hasData {
+		i, ok, err := get("pushIndex", db.pushIndex, item)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			info.PushSynced = false
+			info.Tag = i.Tag
+		}
+		// bin ids are unique in bins, but the address
+		// is checked for an inconsistent database
+		i, err = db.pullIndex.Get(item)
+		switch {
+		case err == nil && swarm.NewAddress(i.Address).Equal(addr):
+			info.Indexes = append(info.Indexes, "pullIndex")
+			info.PullSyncable = true
+			if info.Tag == 0 {
+				info.Tag = i.Tag
+			}
+		case err != nil && !errors.Is(err, shed.ErrNotFound):
+			return nil, err
+		}
+
+		inGC, err := db.gcIndex.Has(item)
+		if err != nil {
+			return nil, err
+		}
+		if inGC {
+			info.Indexes = append(info.Indexes, "gcIndex")
+			info.GCEligible = !excluded
+		}
+	}
+
+	if len(info.Indexes) == 0 {
+		return nil, storage.ErrNotFound
+	}
+	sort.Strings(info.Indexes)
+	return info, nil
+}
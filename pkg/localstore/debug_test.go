@@ -0,0 +1,125 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+// TestDebugChunk validates the reported chunk state
+// through the upload, sync, pin and remove modes.
+func TestDebugChunk(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, &Options{Tags: tags.NewTags()})
+	defer cleanupFunc()
+
+	tag, err := db.tags.Create("test", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := generateTestRandomChunk().WithTagID(tag.Uid)
+	ctx := context.Background()
+
+	check := func(t *testing.T, want ChunkInfo) {
+		t.Helper()
+
+		info, err := db.DebugChunk(ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.Address.Equal(ch.Address()) {
+			t.Errorf("got address %s, want %s", info.Address, ch.Address())
+		}
+		if info.Bin != db.po(ch.Address()) {
+			t.Errorf("got bin %v, want %v", info.Bin, db.po(ch.Address()))
+		}
+		if fmt.Sprint(info.Indexes) != fmt.Sprint(want.Indexes) {
+			t.Errorf("got indexes %v, want %v", info.Indexes, want.Indexes)
+		}
+		if info.StoreTimestamp != want.StoreTimestamp || info.AccessTimestamp != want.AccessTimestamp {
+			t.Errorf("got timestamps %v and %v, want %v and %v", info.StoreTimestamp, info.AccessTimestamp, want.StoreTimestamp, want.AccessTimestamp)
+		}
+		if info.BinID != 1 {
+			t.Errorf("got bin id %v, want 1", info.BinID)
+		}
+		if info.Tag != tag.Uid {
+			t.Errorf("got tag %v, want %v", info.Tag, tag.Uid)
+		}
+		if info.PinCounter != want.PinCounter {
+			t.Errorf("got pin counter %v, want %v", info.PinCounter, want.PinCounter)
+		}
+		if info.PushSynced != want.PushSynced || info.PullSyncable != want.PullSyncable || info.GCEligible != want.GCEligible {
+			t.Errorf("got push synced %v, pull syncable %v and gc eligible %v, want %v, %v and %v",
+				info.PushSynced, info.PullSyncable, info.GCEligible, want.PushSynced, want.PullSyncable, want.GCEligible)
+		}
+	}
+
+	uploadTimestamp := int64(1000)
+	defer setNow(func() int64 { return uploadTimestamp })()
+	if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		check(t, ChunkInfo{
+			Indexes:        []string{"pullIndex", "pushIndex", "retrievalDataIndex"},
+			StoreTimestamp: uploadTimestamp,
+			PullSyncable:   true,
+		})
+	})
+
+	syncTimestamp := int64(2000)
+	defer setNow(func() int64 { return syncTimestamp })()
+	if err := db.Set(ctx, storage.ModeSetSyncPush, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("push synced", func(t *testing.T) {
+		check(t, ChunkInfo{
+			Indexes:         []string{"gcIndex", "pullIndex", "retrievalAccessIndex", "retrievalDataIndex"},
+			StoreTimestamp:  uploadTimestamp,
+			AccessTimestamp: syncTimestamp,
+			PushSynced:      true,
+			PullSyncable:    true,
+			GCEligible:      true,
+		})
+	})
+
+	if err := db.Set(ctx, storage.ModeSetPin, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("pinned", func(t *testing.T) {
+		check(t, ChunkInfo{
+			Indexes:         []string{"gcExcludeIndex", "gcIndex", "pinIndex", "pullIndex", "retrievalAccessIndex", "retrievalDataIndex"},
+			StoreTimestamp:  uploadTimestamp,
+			AccessTimestamp: syncTimestamp,
+			PinCounter:      1,
+			PushSynced:      true,
+			PullSyncable:    true,
+		})
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		ch := generateTestRandomChunk()
+		if _, err := db.Put(ctx, storage.ModePutSync, ch); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.DebugChunk(ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(ctx, storage.ModeSetRemove, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.DebugChunk(ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+}
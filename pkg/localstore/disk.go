@@ -0,0 +1,78 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+var (
+	// ErrInsufficientDiskSpace is returned by Put for chunks received
+	// from the network if free disk space is below the watermark.
+	ErrInsufficientDiskSpace = errors.New("insufficient disk space")
+
+	errDiskSpaceUnsupported = errors.New("free disk space is not supported on this platform")
+
+	// diskSpaceCheckInterval is the time for which the measured
+	// free disk space is reused by subsequent Put calls.
+	diskSpaceCheckInterval = 5 * time.Second
+
+	// freeDiskSpace returns the number of bytes available
+	// to the process on the file system of the path.
+	// It is a variable to be replaced in tests.
+	freeDiskSpace = diskFree
+)
+
+// diskWatermark refuses writes when free disk
+// space is lower than the configured limit.
+type diskWatermark struct {
+	path  string
+	limit uint64
+
+	mu          sync.Mutex
+	free        uint64
+	checked     time.Time
+	unsupported bool
+}
+
+// checkDiskSpace returns ErrInsufficientDiskSpace for modes of chunks received
+// from the network if free disk space is below the limit. Locally
+// uploaded chunks are never refused.
+func (db *DB) checkDiskSpace(mode storage.ModePut) (err error) {
+	w := db.diskWatermark
+	if w == nil || (mode != storage.ModePutSync && mode != storage.ModePutRequest) {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.unsupported {
+		return nil
+	}
+	if time.Since(w.checked) >= diskSpaceCheckInterval {
+		free, err := freeDiskSpace(w.path)
+		if err != nil {
+			if errors.Is(err, errDiskSpaceUnsupported) {
+				db.logger.Warningf("localstore free disk space watermark disabled: %v", err)
+				w.unsupported = true
+				return nil
+			}
+			return err
+		}
+		w.free = free
+		w.checked = time.Now()
+		db.metrics.FreeDiskBytes.Set(float64(free))
+	}
+	if w.free < w.limit {
+		db.metrics.ModePutRefusedDiskSpace.Inc()
+		return ErrInsufficientDiskSpace
+	}
+	return nil
+}
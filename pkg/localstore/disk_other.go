@@ -0,0 +1,14 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package localstore
+
+// diskFree is not supported on this platform and
+// the free disk space watermark is disabled.
+func diskFree(path string) (free uint64, err error) {
+	return 0, errDiskSpaceUnsupported
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// TestDB_freeDiskWatermark validates that chunks from the network
+// are refused when free disk space is below the watermark.
+func TestDB_freeDiskWatermark(t *testing.T) {
+	defer func(f func(string) (uint64, error)) { freeDiskSpace = f }(freeDiskSpace)
+	defer func(i time.Duration) { diskSpaceCheckInterval = i }(diskSpaceCheckInterval)
+	diskSpaceCheckInterval = 0
+
+	var free uint64
+	freeDiskSpace = func(string) (uint64, error) {
+		return free, nil
+	}
+
+	db, cleanupFunc := newTestDB(t, &Options{
+		FreeDiskWatermark: 1000,
+	})
+	defer cleanupFunc()
+
+	for _, tc := range []struct {
+		free    uint64
+		mode    storage.ModePut
+		wantErr error
+	}{
+		{free: 500, mode: storage.ModePutSync, wantErr: ErrInsufficientDiskSpace},
+		{free: 500, mode: storage.ModePutRequest, wantErr: ErrInsufficientDiskSpace},
+		{free: 500, mode: storage.ModePutUpload},
+		{free: 1000, mode: storage.ModePutSync},
+		{free: 2000, mode: storage.ModePutRequest},
+	} {
+		free = tc.free
+		_, err := db.Put(context.Background(), tc.mode, generateTestRandomChunk())
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("free %v, mode %v: got error %v, want %v", tc.free, tc.mode, err, tc.wantErr)
+		}
+	}
+
+	t.Run("in memory", func(t *testing.T) {
+		free = 0
+		db, err := New("", nil, &Options{
+			FreeDiskWatermark: 1000,
+		}, db.logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		if _, err := db.Put(context.Background(), storage.ModePutSync, generateTestRandomChunk()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
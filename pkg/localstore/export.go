@@ -20,11 +20,13 @@ import (
 	"archive/tar"
 	"context"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"sync"
 
+	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -34,30 +36,97 @@ const (
 	// filename in tar archive that holds the information
 	// about exported data format version
 	exportVersionFilename = ".swarm-export-version"
+	// filename in tar archive that holds the json encoded
+	// last bin IDs at the time of the export
+	exportCursorFilename = ".swarm-export-cursor"
 	// current export format version
 	currentExportVersion = "1"
 )
 
+// ErrExportCursorNotFound is returned by ReadExportCursor if
+// the archive does not contain the export cursor.
+var ErrExportCursorNotFound = errors.New("export cursor not found")
+
+// ExportOptions holds optional parameters for Export.
+type ExportOptions struct {
+	// Bins, if not empty, limits the export to chunks
+	// in these proximity order bins.
+	Bins []uint8
+	// Since, if not nil, limits the export to chunks in the pull
+	// index with bin IDs greater than the value for their bin.
+	// It is usually the cursor of a previous export, returned
+	// by ReadExportCursor. Chunks stored only by retrieval
+	// requests are not in the pull index and are not exported.
+	Since map[uint8]uint64
+	// References, if not empty, limits the export to chunks of
+	// the chunk trees with these root addresses.
+	References []swarm.Address
+	// Progress, if set, is called with the number of
+	// exported chunks after every chunk is written.
+	Progress func(count int64)
+}
+
 // Export writes a tar structured data to the writer of
-// all chunks in the retrieval data index. It returns the
-// number of chunks exported.
-func (db *DB) Export(w io.Writer) (count int64, err error) {
-	tw := tar.NewWriter(w)
-	defer tw.Close()
+// all chunks in the retrieval data index, filtered by
+// export options. The last bin IDs of all bins at the
+// start of the export are written to the archive as the
+// export cursor. It returns the number of chunks exported.
+func (db *DB) Export(w io.Writer, o *ExportOptions) (count int64, err error) {
+	if o == nil {
+		o = new(ExportOptions)
+	}
 
-	if err := tw.WriteHeader(&tar.Header{
-		Name: exportVersionFilename,
-		Mode: 0644,
-		Size: int64(len(currentExportVersion)),
-	}); err != nil {
-		return 0, err
+	cursor := make(map[uint8]uint64)
+	for bin := uint8(0); bin <= swarm.MaxPO; bin++ {
+		id, err := db.binIDs.Get(uint64(bin))
+		if err != nil {
+			return 0, fmt.Errorf("get bin id: %w", err)
+		}
+		if id > 0 {
+			cursor[bin] = id
+		}
 	}
-	if _, err := tw.Write([]byte(currentExportVersion)); err != nil {
+	cursorData, err := json.Marshal(cursor)
+	if err != nil {
 		return 0, err
 	}
 
-	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+	tw := tar.NewWriter(w)
+	defer tw.Close()
 
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{name: exportVersionFilename, data: []byte(currentExportVersion)},
+		{name: exportCursorFilename, data: cursorData},
+	} {
+		if err := tw.WriteHeader(&tar.Header{
+			Name: f.name,
+			Mode: 0644,
+			Size: int64(len(f.data)),
+		}); err != nil {
+			return 0, err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return 0, err
+		}
+	}
+
+	bins := make(map[uint8]struct{})
+	for _, bin := range o.Bins {
+		bins[bin] = struct{}{}
+	}
+	// inBins returns true if the bin is not excluded by the Bins option
+	inBins := func(bin uint8) bool {
+		if len(bins) == 0 {
+			return true
+		}
+		_, ok := bins[bin]
+		return ok
+	}
+
+	write := func(item shed.Item) error {
 		hdr := &tar.Header{
 			Name: hex.EncodeToString(item.Address),
 			Mode: 0644,
@@ -65,136 +134,233 @@ func (db *DB) Export(w io.Writer) (count int64, err error) {
 		}
 
 		if err := tw.WriteHeader(hdr); err != nil {
-			return false, err
+			return err
 		}
 		if _, err := tw.Write(item.Data); err != nil {
-			return false, err
+			return err
 		}
 		count++
-		return false, nil
-	}, nil)
+		if o.Progress != nil {
+			o.Progress(count)
+		}
+		return nil
+	}
+
+	switch {
+	case len(o.References) > 0:
+		seen := make(map[string]struct{})
+		for _, ref := range o.References {
+			err := file.Walk(context.Background(), db, ref, func(ch swarm.Chunk) error {
+				if _, ok := seen[ch.Address().String()]; ok {
+					return nil
+				}
+				seen[ch.Address().String()] = struct{}{}
+				item, err := db.retrievalDataIndex.Get(addressToItem(ch.Address()))
+				if err != nil {
+					return err
+				}
+				po := db.po(ch.Address())
+				if !inBins(po) {
+					return nil
+				}
+				if o.Since != nil && item.BinID <= o.Since[po] {
+					return nil
+				}
+				return write(item)
+			})
+			if err != nil {
+				return count, fmt.Errorf("reference %s: %w", ref, err)
+			}
+		}
+
+	case o.Since != nil:
+		for bin := uint8(0); bin <= swarm.MaxPO; bin++ {
+			if !inBins(bin) {
+				continue
+			}
+			last, ok := cursor[bin]
+			if !ok || last <= o.Since[bin] {
+				continue
+			}
+			err = db.pullIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+				if item.BinID > last {
+					return true, nil
+				}
+				dataItem, err := db.retrievalDataIndex.Get(item)
+				if err != nil {
+					if errors.Is(err, shed.ErrNotFound) {
+						// the chunk is removed in the meantime
+						return false, nil
+					}
+					return true, err
+				}
+				return false, write(dataItem)
+			}, &shed.IterateOptions{
+				StartFrom: &shed.Item{
+					Address: db.addressInBin(bin).Bytes(),
+					BinID:   o.Since[bin],
+				},
+				SkipStartFromItem: true,
+				Prefix:            []byte{bin},
+			})
+			if err != nil {
+				return count, err
+			}
+		}
+
+	default:
+		err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if !inBins(db.po(swarm.NewAddress(item.Address))) {
+				return false, nil
+			}
+			return false, write(item)
+		}, nil)
+	}
 
 	return count, err
 }
 
+// ReadExportCursor reads the export cursor from the tar structured
+// data written by Export. The returned cursor can be used as the
+// Since export option to continue the export from the end of the
+// read archive.
+func ReadExportCursor(r io.Reader) (cursor map[uint8]uint64, err error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil, ErrExportCursorNotFound
+			}
+			return nil, err
+		}
+		if hdr.Name != exportCursorFilename {
+			continue
+		}
+		if err := json.NewDecoder(tr).Decode(&cursor); err != nil {
+			return nil, fmt.Errorf("decode export cursor: %w", err)
+		}
+		return cursor, nil
+	}
+}
+
+// ImportOptions holds optional parameters for Import.
+type ImportOptions struct {
+	// Validator, if set, validates every chunk before it is
+	// stored. Import fails on the first invalid chunk.
+	Validator swarm.ChunkValidator
+	// Pin sets all imported chunks to be pinned.
+	Pin bool
+	// Progress, if set, is called with the number of
+	// imported chunks after every stored batch of chunks.
+	Progress func(count int64)
+}
+
+// importBatchSize is the maximal number of chunks
+// that Import stores with a single Put call.
+var importBatchSize = 100
+
 // Import reads a tar structured data from the reader and
 // stores chunks in the database. It returns the number of
 // chunks imported.
-func (db *DB) Import(r io.Reader, legacy bool) (count int64, err error) {
+func (db *DB) Import(r io.Reader, o *ImportOptions) (count int64, err error) {
+	if o == nil {
+		o = new(ImportOptions)
+	}
+
+	ctx := context.Background()
 	tr := tar.NewReader(r)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errC := make(chan error)
-	doneC := make(chan struct{})
-	tokenPool := make(chan struct{}, 100)
-	var wg sync.WaitGroup
-	go func() {
-		var (
-			firstFile = true
-
-			// if exportVersionFilename file is not present
-			// assume current version
-			version = currentExportVersion
-		)
-		for {
-			hdr, err := tr.Next()
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
-			}
-			if firstFile {
-				firstFile = false
-				if hdr.Name == exportVersionFilename {
-					data, err := ioutil.ReadAll(tr)
-					if err != nil {
-						select {
-						case errC <- err:
-						case <-ctx.Done():
-						}
-					}
-					version = string(data)
-					continue
-				}
+	batch := make([]swarm.Chunk, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := db.Put(ctx, storage.ModePutUpload, batch...); err != nil {
+			return fmt.Errorf("put chunks: %w", err)
+		}
+		if o.Pin {
+			addrs := make([]swarm.Address, 0, len(batch))
+			for _, ch := range batch {
+				addrs = append(addrs, ch.Address())
 			}
-
-			if len(hdr.Name) != 64 {
-				db.logger.Warningf("ignoring non-chunk file, name : %s", hdr.Name)
-				continue
+			if err := db.Set(ctx, storage.ModeSetPin, addrs...); err != nil {
+				return fmt.Errorf("pin chunks: %w", err)
 			}
+		}
+		count += int64(len(batch))
+		batch = batch[:0]
+		if o.Progress != nil {
+			o.Progress(count)
+		}
+		return nil
+	}
 
-			keybytes, err := hex.DecodeString(hdr.Name)
-			if err != nil {
-				db.logger.Warningf("ignoring invalid chunk file. name : %s , Error : %s", hdr.Name, err)
-				continue
-			}
+	var (
+		firstFile = true
 
-			data, err := ioutil.ReadAll(tr)
-			if err != nil {
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
+		// if exportVersionFilename file is not present
+		// assume current version
+		version = currentExportVersion
+	)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
 			}
-			key := swarm.NewAddress(keybytes)
-
-			var ch swarm.Chunk
-			switch version {
-			case currentExportVersion:
-				ch = swarm.NewChunk(key, data)
-			default:
-				select {
-				case errC <- fmt.Errorf("unsupported export data version %q", version):
-				case <-ctx.Done():
+			return count, err
+		}
+		if hdr.Name == exportCursorFilename {
+			continue
+		}
+		if firstFile {
+			firstFile = false
+			if hdr.Name == exportVersionFilename {
+				data, err := ioutil.ReadAll(tr)
+				if err != nil {
+					return count, err
 				}
+				version = string(data)
+				continue
 			}
-			tokenPool <- struct{}{}
-			wg.Add(1)
-
-			go func() {
-				_, err := db.Put(ctx, storage.ModePutUpload, ch)
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-					wg.Done()
-					<-tokenPool
-				default:
-					_, err := db.Put(ctx, storage.ModePutUpload, ch)
-					if err != nil {
-						errC <- err
-					}
-					wg.Done()
-					<-tokenPool
-				}
-			}()
+		}
 
-			count++
+		if len(hdr.Name) != 64 {
+			db.logger.Warningf("ignoring non-chunk file, name : %s", hdr.Name)
+			continue
 		}
-		wg.Wait()
-		close(doneC)
-	}()
 
-	// wait for all chunks to be stored
-	for {
-		select {
-		case err := <-errC:
-			if err != nil {
-				return count, err
-			}
-		case <-ctx.Done():
-			return count, ctx.Err()
+		keybytes, err := hex.DecodeString(hdr.Name)
+		if err != nil {
+			db.logger.Warningf("ignoring invalid chunk file. name : %s , Error : %s", hdr.Name, err)
+			continue
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return count, err
+		}
+		key := swarm.NewAddress(keybytes)
+
+		var ch swarm.Chunk
+		switch version {
+		case currentExportVersion:
+			ch = swarm.NewChunk(key, data)
 		default:
-			select {
-			case <-doneC:
-				return count, nil
-			default:
+			return count, fmt.Errorf("unsupported export data version %q", version)
+		}
+
+		if o.Validator != nil && !o.Validator.Validate(ch) {
+			return count, fmt.Errorf("chunk %s: %w", key, storage.ErrInvalidChunk)
+		}
+
+		batch = append(batch, ch)
+		if len(batch) >= importBatchSize {
+			if err := flush(); err != nil {
+				return count, err
 			}
 		}
 	}
+	return count, flush()
 }
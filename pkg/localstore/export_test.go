@@ -17,10 +17,17 @@
 package localstore
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -47,7 +54,7 @@ func TestExportImport(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	c, err := db1.Export(&buf)
+	c, err := db1.Export(&buf, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -59,7 +66,7 @@ func TestExportImport(t *testing.T) {
 	db2, cleanup2 := newTestDB(t, nil)
 	defer cleanup2()
 
-	c, err = db2.Import(&buf, false)
+	c, err = db2.Import(&buf, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -79,3 +86,188 @@ func TestExportImport(t *testing.T) {
 		}
 	}
 }
+
+// TestImportOptions validates that imported chunks are
+// validated, pinned and that the progress is reported.
+func TestImportOptions(t *testing.T) {
+	defer func(s int) { importBatchSize = s }(importBatchSize)
+	importBatchSize = 3
+
+	db1, cleanup1 := newTestDB(t, nil)
+	defer cleanup1()
+
+	chunks := generateTestRandomChunks(10)
+	if _, err := db1.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	var exportProgress int64
+	if _, err := db1.Export(&buf, &ExportOptions{
+		Progress: func(count int64) { exportProgress = count },
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if exportProgress != int64(len(chunks)) {
+		t.Errorf("got export progress %v, want %v", exportProgress, len(chunks))
+	}
+	archive := buf.Bytes()
+
+	t.Run("pin", func(t *testing.T) {
+		db2, cleanup2 := newTestDB(t, nil)
+		defer cleanup2()
+
+		var progress []int64
+		c, err := db2.Import(bytes.NewReader(archive), &ImportOptions{
+			Pin:      true,
+			Progress: func(count int64) { progress = append(progress, count) },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != int64(len(chunks)) {
+			t.Errorf("got import count %v, want %v", c, len(chunks))
+		}
+		wantProgress := []int64{3, 6, 9, 10}
+		if fmt.Sprint(progress) != fmt.Sprint(wantProgress) {
+			t.Errorf("got progress %v, want %v", progress, wantProgress)
+		}
+		t.Run("pin index count", newItemsCountTest(db2.pinIndex, len(chunks)))
+	})
+
+	t.Run("invalid chunk", func(t *testing.T) {
+		db2, cleanup2 := newTestDB(t, nil)
+		defer cleanup2()
+
+		invalid := chunks[0].Address()
+		_, err := db2.Import(bytes.NewReader(archive), &ImportOptions{
+			Validator: chunkValidatorFunc(func(ch swarm.Chunk) bool {
+				return !ch.Address().Equal(invalid)
+			}),
+		})
+		if !errors.Is(err, storage.ErrInvalidChunk) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrInvalidChunk)
+		}
+		if _, err := db2.Get(context.Background(), storage.ModeGetLookup, invalid); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+}
+
+type chunkValidatorFunc func(ch swarm.Chunk) bool
+
+func (f chunkValidatorFunc) Validate(ch swarm.Chunk) bool {
+	return f(ch)
+}
+
+// TestExportFilters validates that exports are limited by
+// bins, the cursor of the previous export and references.
+func TestExportFilters(t *testing.T) {
+	db, cleanup := newTestDB(t, nil)
+	defer cleanup()
+
+	ctx := context.Background()
+	chunks := generateTestRandomChunks(20)
+	if _, err := db.Put(ctx, storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := db.Export(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := ReadExportCursor(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for bin := uint8(0); bin <= swarm.MaxPO; bin++ {
+		want, err := db.LastPullSubscriptionBinID(bin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cursor[bin] != want {
+			t.Errorf("bin %v: got cursor %v, want %v", bin, cursor[bin], want)
+		}
+	}
+
+	t.Run("since", func(t *testing.T) {
+		newChunks := generateTestRandomChunks(5)
+		if _, err := db.Put(ctx, storage.ModePutUpload, newChunks...); err != nil {
+			t.Fatal(err)
+		}
+		checkExport(t, db, &ExportOptions{Since: cursor}, newChunks)
+		chunks = append(chunks, newChunks...)
+	})
+
+	t.Run("bins", func(t *testing.T) {
+		bin := db.po(chunks[0].Address())
+		var want []swarm.Chunk
+		for _, ch := range chunks {
+			if db.po(ch.Address()) == bin {
+				want = append(want, ch)
+			}
+		}
+		checkExport(t, db, &ExportOptions{Bins: []uint8{bin}}, want)
+		checkExport(t, db, &ExportOptions{Bins: []uint8{bin}, Since: map[uint8]uint64{}}, want)
+	})
+
+	t.Run("references", func(t *testing.T) {
+		leaf := generateTestRandomChunk()
+		binary.LittleEndian.PutUint64(leaf.Data(), swarm.ChunkSize)
+		data := make([]byte, file.SpanSize+swarm.HashSize)
+		binary.LittleEndian.PutUint64(data, 2*swarm.ChunkSize)
+		copy(data[file.SpanSize:], leaf.Address().Bytes())
+		root := swarm.NewChunk(generateTestRandomChunk().Address(), data)
+		if _, err := db.Put(ctx, storage.ModePutUpload, leaf, root); err != nil {
+			t.Fatal(err)
+		}
+		checkExport(t, db, &ExportOptions{References: []swarm.Address{root.Address()}}, []swarm.Chunk{root, leaf})
+	})
+}
+
+// checkExport validates that the export with provided options
+// contains exactly the provided chunks.
+func checkExport(t *testing.T, db *DB, o *ExportOptions, want []swarm.Chunk) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	count, err := db.Export(&buf, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != int64(len(want)) {
+		t.Errorf("got export count %v, want %v", count, len(want))
+	}
+
+	got := make(map[string][]byte)
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name == exportVersionFilename || hdr.Name == exportCursorFilename {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[hdr.Name] = data
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v exported chunks, want %v", len(got), len(want))
+	}
+	for _, ch := range want {
+		data, ok := got[ch.Address().String()]
+		if !ok {
+			t.Fatalf("chunk %s not exported", ch.Address())
+		}
+		if !bytes.Equal(data, ch.Data()) {
+			t.Fatalf("chunk %s: got data %x, want %x", ch.Address(), data, ch.Data())
+		}
+	}
+}
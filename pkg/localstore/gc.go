@@ -20,8 +20,8 @@ import (
 	"errors"
 	"time"
 
-	"github.com/dgraph-io/badger/v2"
 	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 var (
@@ -35,7 +35,7 @@ var (
 	// garbage collection runs.
 	gcTargetRatio = 0.9
 	// gcBatchSize limits the number of chunks in a single
-	// badger transaction on garbage collection.
+	// batch on garbage collection.
 	gcBatchSize uint64 = 200
 )
 
@@ -85,8 +85,14 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		}
 	}()
 
-	batch := db.shed.GetBatch(true)
+	batch := db.shed.GetBatch()
 	target := db.gcTarget()
+	// number of bytes to be removed to reach the target
+	var bytesToCollect uint64
+	if stored, targetBytes := db.storedBytes(), db.gcTargetBytes(); db.capacityBytes > 0 && stored > targetBytes {
+		bytesToCollect = stored - targetBytes
+	}
+	var collectedBytes uint64
 
 	// protect database from changing idexes and gcSize
 	db.batchMu.Lock()
@@ -100,18 +106,18 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		return 0, true, err
 	}
 
-	gcSize, err := db.gcSize.Get()
+	sizes, err := db.gcSizes()
 	if err != nil {
 		return 0, true, err
 	}
 	db.metrics.GCSize.Inc()
 
-	done = true
-	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
-		if gcSize-collectedCount <= target {
-			return true, nil
-		}
+	gcSizeChange := make(gcSizeChanges)
+	radius := db.ReserveRadius()
+	var removed []swarm.Address
 
+	// collect removes the chunk from retrieval and other indexes
+	collect := func(item shed.Item) (stop bool, err error) {
 		db.metrics.GCStoreTimeStamps.Set(float64(item.StoreTimestamp))
 		db.metrics.GCStoreAccessTimeStamps.Set(float64(item.AccessTimestamp))
 
@@ -128,10 +134,17 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		if err != nil {
 			return true, nil
 		}
+		err = db.postageIndex.DeleteInBatch(batch, item)
+		if err != nil {
+			return true, nil
+		}
 		err = db.gcIndex.DeleteInBatch(batch, item)
 		if err != nil {
 			return true, nil
 		}
+		addr := swarm.NewAddress(item.Address)
+		gcSizeChange.add(db.po(addr), -1)
+		removed = append(removed, addr)
 		collectedCount++
 		if collectedCount >= gcBatchSize {
 			// bach size limit reached,
@@ -140,13 +153,81 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 			return true, nil
 		}
 		return false, nil
+	}
+
+	// countBytes adds the size of the chunk to collected bytes if
+	// bytes are collected, only the chunk data is accounted, as it
+	// is the most significant part of removed index items, collecting
+	// more rather than less, while the exact stored size is checked
+	// on the next run
+	countBytes := func(item shed.Item) error {
+		if bytesToCollect == 0 {
+			return nil
+		}
+		i, err := db.retrievalDataIndex.Get(item)
+		switch {
+		case err == nil:
+			collectedBytes += uint64(len(i.Address) + len(i.Data))
+		case !errors.Is(err, shed.ErrNotFound):
+			return err
+		}
+		return nil
+	}
+
+	done = true
+	// cache chunks are removed from the least recently accessed
+	var cacheSeen, cacheCollected uint64
+	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if cacheSeen >= sizes.cache {
+			// no more chunks in the cache
+			return true, nil
+		}
+		if db.po(swarm.NewAddress(item.Address)) >= radius {
+			// reserve chunk
+			return false, nil
+		}
+		cacheSeen++
+		overCount := db.capacity > 0 && sizes.cache-cacheCollected > target
+		overBytes := collectedBytes < bytesToCollect
+		if !overCount && !overBytes {
+			return true, nil
+		}
+		if err := countBytes(item); err != nil {
+			return true, err
+		}
+		cacheCollected++
+		return collect(item)
 	}, nil)
 	if err != nil {
 		return 0, false, err
 	}
+
+	// reserve chunks are removed only if the reserve is full, or if
+	// the database is over its capacity in bytes without the cache
+	reserveFull := sizes.reserve >= db.reserveCapacity
+	if done && (reserveFull || collectedBytes < bytesToCollect) {
+		reserveTarget := db.reserveTarget()
+		var reserveCollected uint64
+		err = db.collectReserveGarbage(radius, func(item shed.Item) (stop bool, err error) {
+			overCount := reserveFull && sizes.reserve-reserveCollected > reserveTarget
+			overBytes := collectedBytes < bytesToCollect
+			if !overCount && !overBytes {
+				return true, nil
+			}
+			if err := countBytes(item); err != nil {
+				return true, err
+			}
+			reserveCollected++
+			return collect(item)
+		})
+		if err != nil {
+			return 0, false, err
+		}
+	}
 	db.metrics.GCCollectedCounter.Inc()
 
-	if err := db.gcSize.PutInBatch(batch, gcSize-collectedCount); err != nil {
+	sizes, err = db.putGCSizesInBatch(batch, gcSizeChange)
+	if err != nil {
 		return 0, false, err
 	}
 
@@ -155,6 +236,10 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		db.metrics.GCExcludeWriteBatchError.Inc()
 		return 0, false, err
 	}
+	db.metrics.ReserveSize.Set(float64(sizes.reserve))
+	db.metrics.CacheSize.Set(float64(sizes.cache))
+	db.updateSizeMetrics()
+	db.notifyRemoved(removed...)
 	return collectedCount, done, nil
 }
 
@@ -168,13 +253,18 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 		}
 	}()
 
-	batch := db.shed.GetBatch(true)
+	batch := db.shed.GetBatch()
 	excludedCount := 0
-	var gcSizeChange int64
+	gcSizeChange := make(gcSizeChanges)
 	err = db.gcExcludeIndex.Iterate(func(item shed.Item) (stop bool, err error) {
 		// Get access timestamp
 		retrievalAccessIndexItem, err := db.retrievalAccessIndex.Get(item)
 		if err != nil {
+			if errors.Is(err, shed.ErrNotFound) {
+				// chunks that are not yet synced have no access
+				// timestamp and can not be in the gcIndex
+				return false, nil
+			}
 			return false, err
 		}
 		item.AccessTimestamp = retrievalAccessIndexItem.AccessTimestamp
@@ -197,7 +287,7 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 				return false, nil
 			}
 			if _, err := db.gcIndex.Get(item); err == nil {
-				gcSizeChange--
+				gcSizeChange.add(db.po(swarm.NewAddress(item.Address)), -1)
 			}
 			excludedCount++
 			err = db.gcExcludeIndex.DeleteInBatch(batch, item)
@@ -234,6 +324,54 @@ func (db *DB) gcTarget() (target uint64) {
 	return uint64(float64(db.capacity) * gcTargetRatio)
 }
 
+// gcTargetBytes returns the number of stored bytes that garbage
+// collection leaves in the database, calculated from
+// db.capacityBytes and gcTargetRatio.
+func (db *DB) gcTargetBytes() (target uint64) {
+	return uint64(float64(db.capacityBytes) * gcTargetRatio)
+}
+
+// storedBytes returns the number of bytes stored in all indexes.
+// Retrieval data index item of a chunk is the most of its size.
+func (db *DB) storedBytes() (size uint64) {
+	for _, index := range db.indexes() {
+		size += index.Size()
+	}
+	return size
+}
+
+// updateSizeMetrics sets the index size metrics and
+// returns the number of bytes stored in all indexes.
+func (db *DB) updateSizeMetrics() (stored uint64) {
+	for name, index := range db.indexes() {
+		size := index.Size()
+		db.metrics.IndexSizeBytes.WithLabelValues(name).Set(float64(size))
+		stored += size
+	}
+	db.metrics.StoredBytes.Set(float64(stored))
+	return stored
+}
+
+// checkStoredBytes updates size metrics and triggers garbage
+// collection if the number of stored bytes reached the capacity.
+// The cache is collected first, and the reserve only if removing
+// the cache is not enough.
+func (db *DB) checkStoredBytes() {
+	stored := db.updateSizeMetrics()
+	if db.capacityBytes == 0 || stored < db.capacityBytes {
+		return
+	}
+	sizes, err := db.gcSizes()
+	if err != nil {
+		db.logger.Debugf("localstore check stored bytes: %v", err)
+		db.logger.Error("localstore check stored bytes")
+		return
+	}
+	if sizes.cache > 0 || sizes.reserve > 0 {
+		db.triggerGarbageCollection()
+	}
+}
+
 // triggerGarbageCollection signals collectGarbageWorker
 // to call collectGarbage.
 func (db *DB) triggerGarbageCollection() {
@@ -244,39 +382,20 @@ func (db *DB) triggerGarbageCollection() {
 	}
 }
 
-// incGCSizeInBatch changes gcSize field value
-// by change which can be negative. This function
-// must be called under batchMu lock.
-func (db *DB) incGCSizeInBatch(batch *badger.Txn, change int64) (err error) {
-	if change == 0 {
+// incGCSizeInBatch changes gcSize field value and gc sizes of bins
+// by changes which can be negative. This function must be called
+// under batchMu lock.
+func (db *DB) incGCSizeInBatch(batch shed.Batch, change gcSizeChanges) (err error) {
+	if len(change) == 0 {
 		return nil
 	}
-	gcSize, err := db.gcSize.Get()
-	if err != nil && !errors.Is(err, shed.ErrNotFound) {
-		return err
-	}
-
-	var new uint64
-	if change > 0 {
-		new = gcSize + uint64(change)
-	} else {
-		// 'change' is an int64 and is negative
-		// a conversion is needed with correct sign
-		c := uint64(-change)
-		if c > gcSize {
-			// protect uint64 undeflow
-			return nil
-		}
-		new = gcSize - c
-	}
-	if err := db.gcSize.PutInBatch(batch, new); err != nil {
+	sizes, err := db.putGCSizesInBatch(batch, change)
+	if err != nil {
 		return err
 	}
 
 	// trigger garbage collection if we reached the capacity
-	if new >= db.capacity {
-		db.triggerGarbageCollection()
-	}
+	db.checkGCSizes(sizes)
 	return nil
 }
 
@@ -136,6 +136,80 @@ func testDBCollectGarbageWorker(t *testing.T) {
 	})
 }
 
+// TestDB_collectGarbageWorker_capacityBytes tests garbage collection
+// runs triggered by the number of stored bytes.
+func TestDB_collectGarbageWorker_capacityBytes(t *testing.T) {
+	// measure the number of bytes that a synced chunk takes
+	db, cleanupFunc := newTestDB(t, nil)
+	ch := generateTestRandomChunk()
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+	chunkBytes := db.storedBytes()
+	cleanupFunc()
+
+	chunkCount := 150
+	capacityBytes := 100 * chunkBytes
+
+	db, cleanupFunc = newTestDB(t, &Options{
+		CapacityBytes: capacityBytes,
+	})
+	testHookCollectGarbageChan := make(chan uint64)
+	defer setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-db.close:
+		}
+	})()
+	defer cleanupFunc()
+
+	addrs := make([]swarm.Address, 0)
+	for i := 0; i < chunkCount; i++ {
+		ch := generateTestRandomChunk()
+
+		_, err := db.Put(context.Background(), storage.ModePutUpload, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		addrs = append(addrs, ch.Address())
+	}
+
+	var collected uint64
+	for collected == 0 || db.storedBytes() >= capacityBytes {
+		select {
+		case c := <-testHookCollectGarbageChan:
+			collected += c
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+	}
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("get the first synced chunk", func(t *testing.T) {
+		_, err := db.Get(context.Background(), storage.ModeGetRequest, addrs[0])
+		if err != storage.ErrNotFound {
+			t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+
+	t.Run("get most recent synced chunk", func(t *testing.T) {
+		_, err := db.Get(context.Background(), storage.ModeGetRequest, addrs[len(addrs)-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
 // Pin a file, upload chunks to go past the gc limit to trigger GC,
 // check if the pinned files are still around and removed from gcIndex
 func TestPinGC(t *testing.T) {
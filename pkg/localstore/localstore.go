@@ -19,12 +19,14 @@ package localstore
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"os"
 	"runtime/pprof"
 	"sync"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -43,6 +45,8 @@ var (
 var (
 	// Default value for Capacity DB option.
 	defaultCapacity uint64 = 5000000
+	// Default value for ReserveCapacity DB option.
+	defaultReserveCapacity uint64 = 4194304
 	// Limit the number of goroutines created by Getters
 	// that call updateGC function. Value 0 sets no limit.
 	maxParallelUpdateGC = 1000
@@ -85,12 +89,38 @@ type DB struct {
 	// pin files Index
 	pinIndex shed.Index
 
+	// postage stamps of stored chunks
+	postageIndex shed.Index
+
+	// validStamp is called before chunks received from the
+	// network are stored to validate their postage stamps
+	validStamp func(swarm.Chunk) error
+
 	// field that stores number of intems in gc index
 	gcSize shed.Uint64Field
-
-	// garbage collection is triggered when gcSize exceeds
-	// the capacity value
+	// number of items in gc index for every proximity order bin
+	gcBinSizes shed.Uint64Vector
+
+	// synced chunks with proximity order to the base key
+	// equal or greater than the radius are in the reserve,
+	// and other synced chunks are in the cache
+	radius uint32
+	// reserve garbage collection is triggered when the number
+	// of chunks in the reserve exceeds the reserveCapacity
+	reserveCapacity uint64
+
+	// cache garbage collection is triggered when the number of
+	// chunks in the cache exceeds the capacity value, if it is
+	// not zero
 	capacity uint64
+	// garbage collection is also triggered when the number
+	// of stored bytes exceeds the capacityBytes value,
+	// if it is not zero
+	capacityBytes uint64
+
+	// refuses chunks from the network when
+	// free disk space is low, nil if disabled
+	diskWatermark *diskWatermark
 
 	// triggers garbage collection event loop
 	collectGarbageTrigger chan struct{}
@@ -103,9 +133,18 @@ type DB struct {
 	// are done before closing the database
 	updateGCWG sync.WaitGroup
 
+	// called with addresses of chunks removed by
+	// garbage collection or ModeSetRemove
+	removeHandler   func(addrs ...swarm.Address)
+	removeHandlerMu sync.RWMutex
+
 	// baseKey is the overlay address
 	baseKey []byte
 
+	// path of the database directory, empty
+	// if data is kept only in memory
+	path string
+
 	batchMu sync.Mutex
 
 	// this channel is closed when close function is called
@@ -132,8 +171,24 @@ type DB struct {
 // Options struct holds optional parameters for configuring DB.
 type Options struct {
 	// Capacity is a limit that triggers garbage collection when
-	// number of items in gcIndex equals or exceeds it.
+	// number of synced chunks in the cache equals or exceeds it.
 	Capacity uint64
+	// CapacityBytes is a limit that triggers garbage collection
+	// when the number of bytes stored in all indexes equals or
+	// exceeds it. The cache is collected first, and the reserve
+	// if the cache is not large enough. If both Capacity and
+	// CapacityBytes are zero, the default Capacity is used.
+	CapacityBytes uint64
+	// ReserveCapacity is a limit that triggers garbage collection
+	// when number of synced chunks in the reserve equals or exceeds
+	// it. Chunks are in the reserve if their proximity order is not
+	// lower than the radius set by SetReserveRadius.
+	ReserveCapacity uint64
+	// FreeDiskWatermark is the amount of free disk space in bytes
+	// below which chunks stored with ModePutSync or ModePutRequest
+	// are refused with ErrInsufficientDiskSpace. Zero disables the
+	// check, as well as keeping the data only in memory.
+	FreeDiskWatermark uint64
 	// MetricsPrefix defines a prefix for metrics names.
 	MetricsPrefix string
 	Tags          *tags.Tags
@@ -141,11 +196,25 @@ type Options struct {
 	// to verify whether that chunk needs to be Set and added to
 	// garbage collection index too
 	PutToGCCheck func([]byte) bool
+	// ValidStamp is a function that validates postage stamps of
+	// chunks stored with ModePutSync or ModePutRequest. If it is
+	// nil, stamps are not validated.
+	ValidStamp func(swarm.Chunk) error
+	// SkipMigrations opens the database without running pending
+	// schema migrations, which can be run with the Migrate method.
+	// The database must not be used for storing chunks before
+	// all migrations are run.
+	SkipMigrations bool
+	// Backend is the name of the key-value store backend,
+	// shed.BackendBadger or shed.BackendLevelDB. BadgerDB
+	// is used if it is not set.
+	Backend string
 }
 
 // New returns a new DB.  All fields and indexes are initialized
 // and possible conflicts with schema from existing database is checked.
 // One goroutine for writing batches is created.
+// If the path is empty, all data is kept in memory only.
 func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB, err error) {
 	if o == nil {
 		// default options
@@ -159,9 +228,13 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	}
 
 	db = &DB{
-		capacity: o.Capacity,
-		baseKey:  baseKey,
-		tags:     o.Tags,
+		capacity:        o.Capacity,
+		capacityBytes:   o.CapacityBytes,
+		reserveCapacity: o.ReserveCapacity,
+		radius:          NoReserveRadius,
+		baseKey:         baseKey,
+		path:            path,
+		tags:            o.Tags,
 		// channel collectGarbageTrigger
 		// needs to be buffered with the size of 1
 		// to signal another event if it
@@ -170,17 +243,29 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		close:                    make(chan struct{}),
 		collectGarbageWorkerDone: make(chan struct{}),
 		putToGCCheck:             o.PutToGCCheck,
+		validStamp:               o.ValidStamp,
 		metrics:                  newMetrics(),
 		logger:                   logger,
 	}
-	if db.capacity == 0 {
+	if db.capacity == 0 && db.capacityBytes == 0 {
 		db.capacity = defaultCapacity
 	}
+	if db.reserveCapacity == 0 {
+		db.reserveCapacity = defaultReserveCapacity
+	}
+	if o.FreeDiskWatermark > 0 && path != "" {
+		db.diskWatermark = &diskWatermark{
+			path:  path,
+			limit: o.FreeDiskWatermark,
+		}
+	}
 	if maxParallelUpdateGC > 0 {
 		db.updateGCSem = make(chan struct{}, maxParallelUpdateGC)
 	}
 
-	db.shed, err = shed.NewDB(path, logger)
+	db.shed, err = shed.NewDB(path, &shed.Options{
+		Backend: o.Backend,
+	}, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -200,12 +285,6 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		// execute possible migrations
-		err = db.migrate(schemaName)
-		if err != nil {
-			return nil, err
-		}
 	}
 
 	// Persist gc size.
@@ -213,6 +292,10 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	if err != nil {
 		return nil, err
 	}
+	db.gcBinSizes, err = db.shed.NewUint64Vector("gc-bin-sizes")
+	if err != nil {
+		return nil, err
+	}
 
 	// Index storing actual chunk address, data and bin id.
 	db.retrievalDataIndex, err = db.shed.NewIndex("Address->StoreTimestamp|BinID|Data", shed.IndexFuncs{
@@ -402,8 +485,51 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		return nil, err
 	}
 
+	// Create a index structure for storing postage stamps of chunks
+	db.postageIndex, err = db.shed.NewIndex("Address->Stamp", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			return fields.Address, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Address = key
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return fields.Stamp, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.Stamp = value
+			return e, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if schemaName != "" && !o.SkipMigrations {
+		// execute possible migrations
+		if _, err := db.Migrate(nil); err != nil {
+			db.shed.Close()
+			return nil, err
+		}
+	}
+
+	db.metrics.CapacityBytes.Set(float64(db.capacityBytes))
+	db.metrics.ReserveRadius.Set(NoReserveRadius)
+	db.updateSizeMetrics()
+	sizes, err := db.gcSizes()
+	if err != nil {
+		db.shed.Close()
+		return nil, err
+	}
+	db.metrics.ReserveSize.Set(float64(sizes.reserve))
+	db.metrics.CacheSize.Set(float64(sizes.cache))
+
 	// start garbage collection worker
 	go db.collectGarbageWorker()
+	if db.capacityBytes > 0 && db.storedBytes() >= db.capacityBytes {
+		db.triggerGarbageCollection()
+	}
 	return db, nil
 }
 
@@ -436,6 +562,32 @@ func (db *DB) Close() (err error) {
 	return db.shed.Close()
 }
 
+// SetRemoveHandler sets the function that is called with addresses
+// of chunks after they are removed from the database by garbage
+// collection or ModeSetRemove. Stores that keep copies of chunks,
+// like a memory cache, use it to invalidate them. The handler is
+// called under the database lock and must not call the database.
+func (db *DB) SetRemoveHandler(h func(addrs ...swarm.Address)) {
+	db.removeHandlerMu.Lock()
+	defer db.removeHandlerMu.Unlock()
+
+	db.removeHandler = h
+}
+
+// notifyRemoved calls the remove handler, if it is set.
+func (db *DB) notifyRemoved(addrs ...swarm.Address) {
+	if len(addrs) == 0 {
+		return
+	}
+	db.removeHandlerMu.RLock()
+	h := db.removeHandler
+	db.removeHandlerMu.RUnlock()
+
+	if h != nil {
+		h(addrs...)
+	}
+}
+
 // po computes the proximity order between the address
 // and database base key.
 func (db *DB) po(addr swarm.Address) (bin uint8) {
@@ -446,15 +598,7 @@ func (db *DB) po(addr swarm.Address) (bin uint8) {
 // the returned map keys are the index name, values are the number of elements in the index
 func (db *DB) DebugIndices() (indexInfo map[string]int, err error) {
 	indexInfo = make(map[string]int)
-	for k, v := range map[string]shed.Index{
-		"retrievalDataIndex":   db.retrievalDataIndex,
-		"retrievalAccessIndex": db.retrievalAccessIndex,
-		"pushIndex":            db.pushIndex,
-		"pullIndex":            db.pullIndex,
-		"gcIndex":              db.gcIndex,
-		"gcExcludeIndex":       db.gcExcludeIndex,
-		"pinIndex":             db.pinIndex,
-	} {
+	for k, v := range db.indexes() {
 		indexSize, err := v.Count()
 		if err != nil {
 			return indexInfo, err
@@ -466,17 +610,58 @@ func (db *DB) DebugIndices() (indexInfo map[string]int, err error) {
 		return indexInfo, err
 	}
 	indexInfo["gcSize"] = int(val)
+	sizes, err := db.gcSizes()
+	if err != nil {
+		return indexInfo, err
+	}
+	indexInfo["reserveSize"] = int(sizes.reserve)
+	indexInfo["cacheSize"] = int(sizes.cache)
 
 	return indexInfo, err
 }
 
+// indexes returns all chunk indexes by their names.
+func (db *DB) indexes() map[string]shed.Index {
+	return map[string]shed.Index{
+		"retrievalDataIndex":   db.retrievalDataIndex,
+		"retrievalAccessIndex": db.retrievalAccessIndex,
+		"pushIndex":            db.pushIndex,
+		"pullIndex":            db.pullIndex,
+		"gcIndex":              db.gcIndex,
+		"gcExcludeIndex":       db.gcExcludeIndex,
+		"pinIndex":             db.pinIndex,
+		"postageIndex":         db.postageIndex,
+	}
+}
+
 // chunkToItem creates new Item with data provided by the Chunk.
-func chunkToItem(ch swarm.Chunk) shed.Item {
-	return shed.Item{
+func chunkToItem(ch swarm.Chunk) (shed.Item, error) {
+	item := shed.Item{
 		Address: ch.Address().Bytes(),
 		Data:    ch.Data(),
 		Tag:     ch.TagID(),
 	}
+	if s := ch.Stamp(); s != nil {
+		stamp, err := s.MarshalBinary()
+		if err != nil {
+			return item, fmt.Errorf("marshal stamp: %w", err)
+		}
+		item.Stamp = stamp
+	}
+	return item, nil
+}
+
+// withStamp attaches the postage stamp from the Item to the Chunk,
+// if the Item has one.
+func withStamp(ch swarm.Chunk, item shed.Item) (swarm.Chunk, error) {
+	if item.Stamp == nil {
+		return ch, nil
+	}
+	stamp := new(postage.Stamp)
+	if err := stamp.UnmarshalBinary(item.Stamp); err != nil {
+		return nil, fmt.Errorf("unmarshal stamp: %w", err)
+	}
+	return ch.WithStamp(stamp), nil
 }
 
 // addressToItem creates new Item with a provided address.
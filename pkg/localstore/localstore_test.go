@@ -137,6 +137,108 @@ func TestDB_updateGCSem(t *testing.T) {
 	}
 }
 
+// TestDB_inMemory validates that the database without a path
+// supports storing, pinning, subscriptions and garbage collection
+// with all key-value store backends.
+func TestDB_inMemory(t *testing.T) {
+	for _, backend := range []string{shed.BackendBadger, shed.BackendLevelDB} {
+		t.Run(backend, func(t *testing.T) {
+			testDBInMemory(t, backend)
+		})
+	}
+}
+
+func testDBInMemory(t *testing.T, backend string) {
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	db, err := New("", baseKey, &Options{Capacity: 10, Backend: backend}, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHookCollectGarbageChan := make(chan uint64)
+	defer setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-db.close:
+		}
+	})()
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := generateTestRandomChunk()
+	if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(ctx, storage.ModeSetPin, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("pin index count", newItemsCountTest(db.pinIndex, 1))
+
+	pushCh, stopPush := db.SubscribePush(ctx)
+	defer stopPush()
+	select {
+	case got := <-pushCh:
+		if !got.Address().Equal(ch.Address()) {
+			t.Fatalf("got push address %s, want %s", got.Address(), ch.Address())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("push subscription timeout")
+	}
+
+	pullCh, stopPull := db.SubscribePull(ctx, db.po(ch.Address()), 0, 0)
+	defer stopPull()
+	select {
+	case got := <-pullCh:
+		if !got.Address.Equal(ch.Address()) {
+			t.Fatalf("got pull address %s, want %s", got.Address, ch.Address())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("pull subscription timeout")
+	}
+
+	for i := 0; i < 20; i++ {
+		ch := generateTestRandomChunk()
+		if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(ctx, storage.ModeSetSyncPull, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gcTarget := db.gcTarget()
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize == gcTarget {
+			break
+		}
+	}
+
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, int(gcTarget)))
+
+	t.Run("get pinned chunk", func(t *testing.T) {
+		got, err := db.Get(ctx, storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Data(), ch.Data()) {
+			t.Errorf("got data %x, want %x", got.Data(), ch.Data())
+		}
+	})
+}
+
 // newTestDB is a helper function that constructs a
 // temporary database and returns a cleanup function that must
 // be called to remove the data.
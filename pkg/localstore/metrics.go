@@ -58,10 +58,20 @@ type metrics struct {
 	SubscribePushIteration        prometheus.Counter
 	SubscribePushIterationDone    prometheus.Counter
 	SubscribePushIterationFailure prometheus.Counter
+	InvalidStamp                  prometheus.Counter
+	ModePutRefusedDiskSpace       prometheus.Counter
 
 	GCSize                  prometheus.Gauge
+	ReserveSize             prometheus.Gauge
+	CacheSize               prometheus.Gauge
+	ReserveRadius           prometheus.Gauge
 	GCStoreTimeStamps       prometheus.Gauge
 	GCStoreAccessTimeStamps prometheus.Gauge
+
+	IndexSizeBytes *prometheus.GaugeVec
+	StoredBytes    prometheus.Gauge
+	CapacityBytes  prometheus.Gauge
+	FreeDiskBytes  prometheus.Gauge
 }
 
 func newMetrics() metrics {
@@ -216,7 +226,7 @@ func newMetrics() metrics {
 		ModeGetMultiFailure: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
-			Name:      "mode_get_failure_count",
+			Name:      "mode_get_multi_failure_count",
 			Help:      "Number of times MODE_GET invocation failed.",
 		}),
 		ModePut: prometheus.NewCounter(prometheus.CounterOpts{
@@ -252,7 +262,7 @@ func newMetrics() metrics {
 		ModeHasFailure: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
-			Name:      "mode_has_count",
+			Name:      "mode_has_failure_count",
 			Help:      "Number of times MODE_HAS invocation failed.",
 		}),
 		ModeHasMulti: prometheus.NewCounter(prometheus.CounterOpts{
@@ -321,6 +331,18 @@ func newMetrics() metrics {
 			Name:      "subscribe_push_iteration_failure_count",
 			Help:      "Number of times SUBSCRIBE_PUSH_ITERATION_FAILURE is invoked.",
 		}),
+		InvalidStamp: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "invalid_stamp_count",
+			Help:      "Number of chunks rejected because of an invalid postage stamp.",
+		}),
+		ModePutRefusedDiskSpace: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "mode_put_refused_disk_space_count",
+			Help:      "Number of Put calls refused because of low free disk space.",
+		}),
 
 		GCSize: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: m.Namespace,
@@ -328,6 +350,24 @@ func newMetrics() metrics {
 			Name:      "gc_size",
 			Help:      "Number of elements in Garbage collection index.",
 		}),
+		ReserveSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "reserve_size",
+			Help:      "Number of synced chunks in the reserve.",
+		}),
+		CacheSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "cache_size",
+			Help:      "Number of synced chunks in the cache.",
+		}),
+		ReserveRadius: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "reserve_radius",
+			Help:      "Proximity order from which chunks are in the reserve.",
+		}),
 		GCStoreTimeStamps: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -340,6 +380,31 @@ func newMetrics() metrics {
 			Name:      "gc_access_time_stamp",
 			Help:      "Access timestamp in Garbage collection iteration.",
 		}),
+
+		IndexSizeBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "index_size_bytes",
+			Help:      "Number of bytes stored in an index.",
+		}, []string{"index"}),
+		StoredBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "stored_bytes",
+			Help:      "Number of bytes stored in all indexes.",
+		}),
+		CapacityBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "capacity_bytes",
+			Help:      "Number of stored bytes that triggers garbage collection.",
+		}),
+		FreeDiskBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "free_disk_bytes",
+			Help:      "Free disk space measured for the watermark check.",
+		}),
 	}
 }
 
@@ -17,54 +17,302 @@
 package localstore
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 var errMissingCurrentSchema = errors.New("could not find current db schema")
 var errMissingTargetSchema = errors.New("could not find target db schema")
 
+// migrationBackupDir is the directory in the database directory
+// where index backups are written before destructive migrations.
+const migrationBackupDir = "migration-backups"
+
 type migration struct {
 	name string             // name of the schema
 	fn   func(db *DB) error // the migration function that needs to be performed in order to get to the current schema name
+	// batchFn is the migration function that performs all changes in
+	// the provided transaction, which is committed together with the
+	// schema name update. It is used instead of fn if it is set.
+	batchFn func(db *DB, batch shed.Batch) error
+	// indexes are the names of indexes that the migration changes,
+	// as returned by the DB indexes method
+	indexes []string
+	// destructive migrations delete or overwrite existing data and
+	// all changed indexes are backed up before they are performed
+	destructive bool
 }
 
 // schemaMigrations contains an ordered list of the database schemes, that is
 // in order to run data migrations in the correct sequence
 var schemaMigrations = []migration{
 	{name: DbSchemaCode, fn: func(db *DB) error { return nil }},
+	{name: DbSchemaReserve, batchFn: migrateReserve},
+}
+
+// migrateReserve stores the number of gc index items
+// for every proximity order bin.
+func migrateReserve(db *DB, batch shed.Batch) error {
+	sizes := make(map[uint8]uint64)
+	err := db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		sizes[db.po(swarm.NewAddress(item.Address))]++
+		return false, nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+	for po, size := range sizes {
+		if err := db.gcBinSizes.PutInBatch(batch, uint64(po), size); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (db *DB) migrate(schemaName string) error {
+// MigrateOptions holds optional parameters for the Migrate method.
+type MigrateOptions struct {
+	// DryRun reports pending migrations without changing the database.
+	// Migrations that are performed in a single transaction are run
+	// and their changes discarded.
+	DryRun bool
+	// Progress is called before every migration is performed
+	// with its name, one based sequence number and the total
+	// number of pending migrations.
+	Progress func(name string, n, total int)
+}
+
+// MigrationReport describes a performed or a pending migration.
+type MigrationReport struct {
+	// Name is the schema name after the migration.
+	Name string `json:"name"`
+	// Indexes are the names of indexes changed by the migration
+	// with the number of their items before the migration.
+	Indexes map[string]int `json:"indexes"`
+	// Destructive is true if the migration deletes or overwrites data.
+	Destructive bool `json:"destructive"`
+	// Transactional is true if the migration is performed in a single
+	// transaction and is not partially applied in case of a failure.
+	Transactional bool `json:"transactional"`
+	// Backup is the directory with index backups created before the
+	// migration. It is empty if no backups are written to the disk.
+	Backup string `json:"backup,omitempty"`
+}
+
+// Migrate performs all pending schema migrations in order and returns
+// their reports. Indexes changed by destructive migrations are backed
+// up before the migration and restored if the migration fails. The
+// backups are kept in the database directory to allow manual recovery.
+// All writes are blocked until migrations are done.
+func (db *DB) Migrate(o *MigrateOptions) (reports []MigrationReport, err error) {
+	if o == nil {
+		o = new(MigrateOptions)
+	}
+
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	schemaName, err := db.schemaName.Get()
+	if err != nil {
+		return nil, fmt.Errorf("get schema name: %w", err)
+	}
 	migrations, err := getMigrations(schemaName, DbSchemaCurrent, schemaMigrations, db)
 	if err != nil {
-		return fmt.Errorf("error getting migrations for current schema (%s): %v", schemaName, err)
+		return nil, fmt.Errorf("error getting migrations for current schema (%s): %w", schemaName, err)
 	}
 
 	// no migrations to run
 	if migrations == nil {
-		return nil
+		return nil, nil
 	}
 
-	db.logger.Infof("need to run data migrations on localstore. numMigrations : %s, schemaName : %s ", len(migrations), schemaName)
-	for i := 0; i < len(migrations); i++ {
-		err := migrations[i].fn(db)
-		if err != nil {
-			return err
+	db.logger.Infof("need to run data migrations on localstore. numMigrations : %d, schemaName : %s ", len(migrations), schemaName)
+	indexes := db.indexes()
+	for i, m := range migrations {
+		if o.Progress != nil {
+			o.Progress(m.name, i+1, len(migrations))
 		}
-		err = db.schemaName.Put(migrations[i].name) // put the name of the current schema
-		if err != nil {
+
+		r := MigrationReport{
+			Name:          m.name,
+			Indexes:       make(map[string]int),
+			Destructive:   m.destructive,
+			Transactional: m.batchFn != nil,
+		}
+		for _, name := range m.indexes {
+			index, ok := indexes[name]
+			if !ok {
+				return reports, fmt.Errorf("migration %s: unknown index %s", m.name, name)
+			}
+			if r.Indexes[name], err = index.Count(); err != nil {
+				return reports, fmt.Errorf("migration %s: count %s: %w", m.name, name, err)
+			}
+		}
+
+		if o.DryRun {
+			if m.batchFn != nil {
+				batch := db.shed.GetBatch()
+				err := m.batchFn(db, batch)
+				batch.Discard()
+				if err != nil {
+					return reports, fmt.Errorf("migration %s: %w", m.name, err)
+				}
+			}
+			reports = append(reports, r)
+			continue
+		}
+
+		var b *migrationBackup
+		if m.destructive {
+			b, err = db.backupIndexes(m.name, m.indexes)
+			if err != nil {
+				return reports, fmt.Errorf("migration %s: backup: %w", m.name, err)
+			}
+			r.Backup = b.dir
+		}
+
+		if err := db.runMigration(m); err != nil {
+			if b != nil {
+				if rerr := b.restore(); rerr != nil {
+					return reports, fmt.Errorf("migration %s: %v: restore backup: %w", m.name, err, rerr)
+				}
+				db.logger.Infof("restored indexes after failed migration. migrationId : %d, backup : %s", i, b.dir)
+			}
+			return reports, fmt.Errorf("migration %s: %w", m.name, err)
+		}
+		db.logger.Infof("successfully ran migration. migrationId : %d, currentSchema : %s", i, m.name)
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+// MigrationsPending returns true if the database has schema migrations
+// that are not performed, which is possible only if it is opened with
+// the SkipMigrations option.
+func (db *DB) MigrationsPending() (bool, error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	schemaName, err := db.schemaName.Get()
+	if err != nil {
+		return false, fmt.Errorf("get schema name: %w", err)
+	}
+	migrations, err := getMigrations(schemaName, DbSchemaCurrent, schemaMigrations, db)
+	if err != nil {
+		return false, fmt.Errorf("error getting migrations for current schema (%s): %w", schemaName, err)
+	}
+	return len(migrations) > 0, nil
+}
+
+// runMigration performs the migration and updates the schema name.
+func (db *DB) runMigration(m migration) error {
+	if m.batchFn == nil {
+		if err := m.fn(db); err != nil {
 			return err
 		}
-		schemaName, err = db.schemaName.Get()
+		return db.schemaName.Put(m.name) // put the name of the current schema
+	}
+	batch := db.shed.GetBatch()
+	if err := m.batchFn(db, batch); err != nil {
+		batch.Discard()
+		return err
+	}
+	if err := db.schemaName.PutInBatch(batch, m.name); err != nil {
+		batch.Discard()
+		return err
+	}
+	return db.shed.WriteBatch(batch)
+}
+
+// migrationBackup holds index backups made before a migration.
+// Backups are written to files in the dir directory, or kept in
+// memory if the database is not persisted on the disk.
+type migrationBackup struct {
+	dir     string
+	indexes map[string]shed.Index
+	buffers map[string]*bytes.Buffer
+}
+
+// backupIndexes creates backups of indexes with provided names.
+func (db *DB) backupIndexes(migrationName string, names []string) (b *migrationBackup, err error) {
+	b = &migrationBackup{
+		indexes: make(map[string]shed.Index),
+		buffers: make(map[string]*bytes.Buffer),
+	}
+	if db.path != "" {
+		b.dir = filepath.Join(db.path, migrationBackupDir, migrationName)
+		if err := os.MkdirAll(b.dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	indexes := db.indexes()
+	for _, name := range names {
+		index := indexes[name]
+		b.indexes[name] = index
+
+		var w io.Writer
+		var f *os.File
+		if b.dir != "" {
+			f, err = os.OpenFile(filepath.Join(b.dir, name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+			if err != nil {
+				return nil, err
+			}
+			w = f
+		} else {
+			buf := new(bytes.Buffer)
+			b.buffers[name] = buf
+			w = buf
+		}
+		count, err := index.Backup(w)
+		if f != nil {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		db.logger.Debugf("localstore migration %s: backed up %d items of %s", migrationName, count, name)
+	}
+	return b, nil
+}
+
+// restore replaces items in all backed up indexes with ones from backups.
+func (b *migrationBackup) restore() error {
+	names := make([]string, 0, len(b.indexes))
+	for name := range b.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := b.restoreIndex(name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
 		}
-		db.logger.Infof("successfully ran migration. migrationId : %s, currentSchema : %s", i, schemaName)
 	}
 	return nil
 }
 
+func (b *migrationBackup) restoreIndex(name string) (err error) {
+	if b.dir == "" {
+		_, err = b.indexes[name].Restore(b.buffers[name])
+		return err
+	}
+	f, err := os.Open(filepath.Join(b.dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = b.indexes[name].Restore(f)
+	return err
+}
+
 // getMigrations returns an ordered list of migrations that need be executed
 // with no errors in order to bring the localstore to the most up-to-date
 // schema definition
@@ -17,13 +17,19 @@
 package localstore
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
 )
 
 func TestOneMigration(t *testing.T) {
@@ -311,3 +317,205 @@ func TestMigrationFailTo(t *testing.T) {
 		t.Errorf("migration ran but shouldnt have")
 	}
 }
+
+// TestMigrationDryRun validates that a dry run does not change the
+// database and that a destructive transactional migration backs up
+// the changed indexes.
+func TestMigrationDryRun(t *testing.T) {
+	defer func(v []migration, s string) {
+		schemaMigrations = v
+		DbSchemaCurrent = s
+	}(schemaMigrations, DbSchemaCurrent)
+
+	DbSchemaCurrent = DbSchemaCode
+	dbSchemaNext := "dbSchemaNext"
+
+	schemaMigrations = []migration{
+		{name: DbSchemaCode, fn: func(db *DB) error { return nil }},
+		{
+			name:        dbSchemaNext,
+			indexes:     []string{"pushIndex"},
+			destructive: true,
+			batchFn: func(db *DB, batch shed.Batch) error {
+				return db.pushIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+					return false, db.pushIndex.DeleteInBatch(batch, item)
+				}, nil)
+			},
+		},
+	}
+
+	dir, baseKey, cleanup := newMigrationTestDir(t)
+	defer cleanup()
+	logger := logging.New(ioutil.Discard, 0)
+
+	chunkCount := putMigrationTestChunks(t, dir, baseKey, 10)
+
+	DbSchemaCurrent = dbSchemaNext
+
+	db, err := New(dir, baseKey, &Options{SkipMigrations: true}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var progress []string
+	reports, err := db.Migrate(&MigrateOptions{
+		DryRun: true,
+		Progress: func(name string, n, total int) {
+			progress = append(progress, fmt.Sprintf("%s %d/%d", name, n, total))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("got %v reports, want 1", len(reports))
+	}
+	r := reports[0]
+	if r.Name != dbSchemaNext || !r.Destructive || !r.Transactional || r.Indexes["pushIndex"] != chunkCount || r.Backup != "" {
+		t.Errorf("got report %+v", r)
+	}
+	if want := dbSchemaNext + " 1/1"; len(progress) != 1 || progress[0] != want {
+		t.Errorf("got progress %v, want %v", progress, want)
+	}
+	checkMigrationTestDB(t, db, DbSchemaCode, chunkCount)
+	checkMigrationsPending(t, db, true)
+
+	reports, err = db.Migrate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("got %v reports, want 1", len(reports))
+	}
+	checkMigrationsPending(t, db, false)
+	wantBackup := filepath.Join(dir, migrationBackupDir, dbSchemaNext)
+	if reports[0].Backup != wantBackup {
+		t.Errorf("got backup %q, want %q", reports[0].Backup, wantBackup)
+	}
+	if _, err := os.Stat(filepath.Join(wantBackup, "pushIndex")); err != nil {
+		t.Error(err)
+	}
+	checkMigrationTestDB(t, db, dbSchemaNext, 0)
+}
+
+// TestMigrationRestoreBackup validates that indexes are restored
+// from backups if a destructive migration fails.
+func TestMigrationRestoreBackup(t *testing.T) {
+	defer func(v []migration, s string) {
+		schemaMigrations = v
+		DbSchemaCurrent = s
+	}(schemaMigrations, DbSchemaCurrent)
+
+	DbSchemaCurrent = DbSchemaCode
+	dbSchemaNext := "dbSchemaNext"
+	errMigration := errors.New("migration error")
+
+	schemaMigrations = []migration{
+		{name: DbSchemaCode, fn: func(db *DB) error { return nil }},
+		{
+			name:        dbSchemaNext,
+			indexes:     []string{"pushIndex"},
+			destructive: true,
+			fn: func(db *DB) error {
+				item, err := db.pushIndex.First(nil)
+				if err != nil {
+					return err
+				}
+				if err := db.pushIndex.Delete(item); err != nil {
+					return err
+				}
+				return errMigration
+			},
+		},
+	}
+
+	dir, baseKey, cleanup := newMigrationTestDir(t)
+	defer cleanup()
+	logger := logging.New(ioutil.Discard, 0)
+
+	chunkCount := putMigrationTestChunks(t, dir, baseKey, 10)
+
+	DbSchemaCurrent = dbSchemaNext
+
+	if _, err := New(dir, baseKey, nil, logger); !errors.Is(err, errMigration) {
+		t.Fatalf("got error %v, want %v", err, errMigration)
+	}
+
+	db, err := New(dir, baseKey, &Options{SkipMigrations: true}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	checkMigrationTestDB(t, db, DbSchemaCode, chunkCount)
+}
+
+// newMigrationTestDir returns a temporary directory for the
+// database and a random base key.
+func newMigrationTestDir(t *testing.T) (dir string, baseKey []byte, cleanup func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "localstore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseKey = make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	return dir, baseKey, func() { os.RemoveAll(dir) }
+}
+
+// putMigrationTestChunks creates the database with the current schema
+// and uploads count chunks to it.
+func putMigrationTestChunks(t *testing.T, dir string, baseKey []byte, count int) int {
+	t.Helper()
+
+	db, err := New(dir, baseKey, nil, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < count; i++ {
+		if _, err := db.Put(context.Background(), storage.ModePutUpload, generateTestRandomChunk()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+// checkMigrationTestDB validates the schema name and
+// the number of items in the push index.
+func checkMigrationTestDB(t *testing.T, db *DB, wantSchema string, wantPushCount int) {
+	t.Helper()
+
+	schemaName, err := db.schemaName.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schemaName != wantSchema {
+		t.Errorf("got schema name %q, want %q", schemaName, wantSchema)
+	}
+	count, err := db.pushIndex.Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != wantPushCount {
+		t.Errorf("got %v push index items, want %v", count, wantPushCount)
+	}
+}
+
+func checkMigrationsPending(t *testing.T, db *DB, want bool) {
+	t.Helper()
+
+	pending, err := db.MigrationsPending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending != want {
+		t.Errorf("got migrations pending %v, want %v", pending, want)
+	}
+}
@@ -18,6 +18,7 @@ package localstore
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/shed"
@@ -47,7 +48,7 @@ func (db *DB) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address)
 		}
 		return nil, err
 	}
-	return swarm.NewChunk(swarm.NewAddress(out.Address), out.Data).WithPinCounter(out.PinCounter), nil
+	return withStamp(swarm.NewChunk(swarm.NewAddress(out.Address), out.Data).WithPinCounter(out.PinCounter), out)
 }
 
 // get returns Item from the retrieval index
@@ -59,6 +60,15 @@ func (db *DB) get(mode storage.ModeGet, addr swarm.Address) (out shed.Item, err
 	if err != nil {
 		return out, err
 	}
+	s, err := db.postageIndex.Get(item)
+	switch err {
+	case nil:
+		out.Stamp = s.Stamp
+	case shed.ErrNotFound:
+		// chunk is stored without a postage stamp
+	default:
+		return out, err
+	}
 	switch mode {
 	// update the access timestamp and gc index
 	case storage.ModeGetRequest:
@@ -123,8 +133,70 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 	db.batchMu.Lock()
 	defer db.batchMu.Unlock()
 
-	batch := db.shed.GetBatch(true)
+	batch := db.shed.GetBatch()
+
+	ok, err := db.updateGCInBatch(batch, item)
+	if err != nil || !ok {
+		return err
+	}
+	return db.shed.WriteBatch(batch)
+}
+
+// UpdateAccess updates access timestamps and the garbage collection
+// index of synced chunks in a single batch. It is used by stores that
+// serve chunks without calling Get with ModeGetRequest, such as a
+// memory cache in front of the database. Chunks that are not found
+// or not yet synced are skipped.
+func (db *DB) UpdateAccess(addrs ...swarm.Address) (err error) {
+	db.metrics.GCUpdate.Inc()
+	defer totalTimeMetric(db.metrics.TotalTimeUpdateGC, time.Now())
+
+	defer func() {
+		if err != nil {
+			db.metrics.GCUpdateError.Inc()
+		}
+	}()
+
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	batch := db.shed.GetBatch()
+
+	// the same chunk must be updated only once in a batch
+	// as the access index is read from the database
+	seen := make(map[string]struct{}, len(addrs))
+	var updated bool
+	for _, addr := range addrs {
+		if _, ok := seen[addr.ByteString()]; ok {
+			continue
+		}
+		seen[addr.ByteString()] = struct{}{}
+
+		// the gc index key contains the bin id
+		item, err := db.retrievalDataIndex.Get(addressToItem(addr))
+		if err != nil {
+			if errors.Is(err, shed.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		ok, err := db.updateGCInBatch(batch, item)
+		if err != nil {
+			return err
+		}
+		updated = updated || ok
+	}
+	if !updated {
+		batch.Discard()
+		return nil
+	}
+	return db.shed.WriteBatch(batch)
+}
 
+// updateGCInBatch puts the garbage collection index update for
+// a single item in the batch and reports if the item was updated.
+// This function must be called under batchMu lock.
+func (db *DB) updateGCInBatch(batch shed.Batch, item shed.Item) (updated bool, err error) {
 	// update accessTimeStamp in retrieve, gc
 
 	i, err := db.retrievalAccessIndex.Get(item)
@@ -134,31 +206,31 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 	case shed.ErrNotFound:
 		// no chunk accesses
 	default:
-		return err
+		return false, err
 	}
 	if item.AccessTimestamp == 0 {
 		// chunk is not yet synced
 		// do not add it to the gc index
-		return nil
+		return false, nil
 	}
 	// delete current entry from the gc index
 	err = db.gcIndex.DeleteInBatch(batch, item)
 	if err != nil {
-		return err
+		return false, err
 	}
 	// update access timestamp
 	item.AccessTimestamp = now()
 	// update retrieve access index
 	err = db.retrievalAccessIndex.PutInBatch(batch, item)
 	if err != nil {
-		return err
+		return false, err
 	}
 	// add new entry to gc index
 	err = db.gcIndex.PutInBatch(batch, item)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return db.shed.WriteBatch(batch)
+	return true, nil
 }
 
 // testHookUpdateGC is a hook that can provide
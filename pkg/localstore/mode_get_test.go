@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 // TestModeGetRequest validates ModeGetRequest index values on the provided DB.
@@ -276,3 +277,69 @@ func TestSetTestHookUpdateGC(t *testing.T) {
 		t.Errorf("got hook value %v, want %v", got, original)
 	}
 }
+
+// TestDB_UpdateAccess validates that access timestamps and the gc
+// index of synced chunks are updated in a batch, while chunks that
+// are not synced are skipped.
+func TestDB_UpdateAccess(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	uploadTimestamp := time.Now().UTC().UnixNano()
+	reset := setNow(func() (t int64) {
+		return uploadTimestamp
+	})
+
+	synced := generateTestRandomChunk()
+	unsynced := generateTestRandomChunk()
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, synced, unsynced); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(context.Background(), storage.ModeSetSyncPull, synced.Address()); err != nil {
+		t.Fatal(err)
+	}
+	reset()
+
+	accessTimestamp := uploadTimestamp + 100
+	defer setNow(func() (t int64) {
+		return accessTimestamp
+	})()
+
+	err := db.UpdateAccess(synced.Address(), unsynced.Address(), synced.Address(), generateTestRandomChunk().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("synced retrieve indexes", newRetrieveIndexesTestWithAccess(db, synced, uploadTimestamp, accessTimestamp))
+
+	t.Run("unsynced retrieve indexes", newRetrieveIndexesTestWithAccess(db, unsynced, uploadTimestamp, 0))
+
+	t.Run("gc index", newGCIndexTest(db, synced, uploadTimestamp, accessTimestamp, 1, nil))
+
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, 1))
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+}
+
+// TestDB_SetRemoveHandler validates that the remove handler
+// is called with addresses of removed chunks.
+func TestDB_SetRemoveHandler(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	var removed []swarm.Address
+	db.SetRemoveHandler(func(addrs ...swarm.Address) {
+		removed = append(removed, addrs...)
+	})
+
+	ch := generateTestRandomChunk()
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(context.Background(), storage.ModeSetRemove, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || !removed[0].Equal(ch.Address()) {
+		t.Errorf("got removed addresses %v, want %v", removed, ch.Address())
+	}
+}
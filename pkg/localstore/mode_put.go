@@ -18,12 +18,13 @@ package localstore
 
 import (
 	"context"
+	"fmt"
 	"time"
 
-	"github.com/dgraph-io/badger/v2"
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
 )
 
 // Put stores Chunks to database and depending
@@ -51,15 +52,30 @@ func (db *DB) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk)
 // slice. This is the same behaviour as if the same chunks are passed one by one
 // in multiple put method calls.
 func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err error) {
+	// validate postage stamps of chunks received from the network
+	// before any of them is stored
+	if db.validStamp != nil && (mode == storage.ModePutRequest || mode == storage.ModePutSync) {
+		for _, ch := range chs {
+			if err := db.validStamp(ch); err != nil {
+				db.metrics.InvalidStamp.Inc()
+				return nil, fmt.Errorf("chunk %s: %w", ch.Address(), err)
+			}
+		}
+	}
+
+	if err := db.checkDiskSpace(mode); err != nil {
+		return nil, err
+	}
+
 	// protect parallel updates
 	db.batchMu.Lock()
 	defer db.batchMu.Unlock()
 
-	batch := db.shed.GetBatch(true)
+	batch := db.shed.GetBatch()
 
 	// variables that provide information for operations
 	// to be done after write batch function successfully executes
-	var gcSizeChange int64                      // number to add or subtract from gcSize
+	gcSizeChange := make(gcSizeChanges)         // numbers to add or subtract from gc sizes of bins
 	var triggerPushFeed bool                    // signal push feed subscriptions to iterate
 	triggerPullFeed := make(map[uint8]struct{}) // signal pull feed subscriptions to iterate
 
@@ -78,12 +94,16 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putRequest(batch, binIDs, chunkToItem(ch))
+			item, err := chunkToItem(ch)
+			if err != nil {
+				return nil, err
+			}
+			exists, c, err := db.putRequest(batch, binIDs, item)
 			if err != nil {
 				return nil, err
 			}
 			exist[i] = exists
-			gcSizeChange += c
+			gcSizeChange.add(db.po(ch.Address()), c)
 		}
 
 	case storage.ModePutUpload:
@@ -92,7 +112,11 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putUpload(batch, binIDs, chunkToItem(ch))
+			item, err := chunkToItem(ch)
+			if err != nil {
+				return nil, err
+			}
+			exists, c, err := db.putUpload(batch, binIDs, item)
 			if err != nil {
 				return nil, err
 			}
@@ -103,7 +127,7 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				triggerPullFeed[db.po(ch.Address())] = struct{}{}
 				triggerPushFeed = true
 			}
-			gcSizeChange += c
+			gcSizeChange.add(db.po(ch.Address()), c)
 		}
 
 	case storage.ModePutSync:
@@ -112,7 +136,11 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putSync(batch, binIDs, chunkToItem(ch))
+			item, err := chunkToItem(ch)
+			if err != nil {
+				return nil, err
+			}
+			exists, c, err := db.putSync(batch, binIDs, item)
 			if err != nil {
 				return nil, err
 			}
@@ -122,7 +150,7 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				// after the batch is successfully written
 				triggerPullFeed[db.po(ch.Address())] = struct{}{}
 			}
-			gcSizeChange += c
+			gcSizeChange.add(db.po(ch.Address()), c)
 		}
 
 	default:
@@ -144,6 +172,7 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 	if err != nil {
 		return nil, err
 	}
+	db.checkStoredBytes()
 
 	for po := range triggerPullFeed {
 		db.triggerPullSubscriptions(po)
@@ -151,15 +180,41 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 	if triggerPushFeed {
 		db.triggerPushSubscriptions()
 	}
+	if mode == storage.ModePutUpload {
+		db.incTagsStored(chs, exist)
+	}
 	return exist, nil
 }
 
+// incTagsStored increments the stored count of upload tags for all
+// chunks and the seen count for chunks that were already stored.
+// It must be called after the batch is successfully written.
+func (db *DB) incTagsStored(chs []swarm.Chunk, exist []bool) {
+	if db.tags == nil {
+		return
+	}
+	for i, ch := range chs {
+		if ch.TagID() == 0 {
+			continue
+		}
+		t, err := db.tags.Get(ch.TagID())
+		if err != nil {
+			db.logger.Errorf("error getting tags on put upload. uid : %d", ch.TagID())
+			continue
+		}
+		if exist[i] {
+			t.Inc(tags.StateSeen)
+		}
+		t.Inc(tags.StateStored)
+	}
+}
+
 // putRequest adds an Item to the batch by updating required indexes:
 //  - put to indexes: retrieve, gc
 //  - it does not enter the syncpool
 // The batch can be written to the database.
 // Provided batch and binID map are updated.
-func (db *DB) putRequest(batch *badger.Txn, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
+func (db *DB) putRequest(batch shed.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
 	i, err := db.retrievalDataIndex.Get(item)
 	switch err {
 	case nil:
@@ -191,6 +246,10 @@ func (db *DB) putRequest(batch *badger.Txn, binIDs map[uint8]uint64, item shed.I
 	if err != nil {
 		return false, 0, err
 	}
+	err = db.putStampInBatch(batch, item)
+	if err != nil {
+		return false, 0, err
+	}
 
 	return exists, gcSizeChange, nil
 }
@@ -199,7 +258,7 @@ func (db *DB) putRequest(batch *badger.Txn, binIDs map[uint8]uint64, item shed.I
 //  - put to indexes: retrieve, push, pull
 // The batch can be written to the database.
 // Provided batch and binID map are updated.
-func (db *DB) putUpload(batch *badger.Txn, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
+func (db *DB) putUpload(batch shed.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
 	exists, err = db.retrievalDataIndex.Has(item)
 	if err != nil {
 		return false, 0, err
@@ -231,6 +290,10 @@ func (db *DB) putUpload(batch *badger.Txn, binIDs map[uint8]uint64, item shed.It
 	if err != nil {
 		return false, 0, err
 	}
+	err = db.putStampInBatch(batch, item)
+	if err != nil {
+		return false, 0, err
+	}
 	err = db.pullIndex.PutInBatch(batch, item)
 	if err != nil {
 		return false, 0, err
@@ -261,7 +324,7 @@ func (db *DB) putUpload(batch *badger.Txn, binIDs map[uint8]uint64, item shed.It
 //  - put to indexes: retrieve, pull
 // The batch can be written to the database.
 // Provided batch and binID map are updated.
-func (db *DB) putSync(batch *badger.Txn, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
+func (db *DB) putSync(batch shed.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
 	exists, err = db.retrievalDataIndex.Has(item)
 	if err != nil {
 		return false, 0, err
@@ -286,6 +349,10 @@ func (db *DB) putSync(batch *badger.Txn, binIDs map[uint8]uint64, item shed.Item
 	if err != nil {
 		return false, 0, err
 	}
+	err = db.putStampInBatch(batch, item)
+	if err != nil {
+		return false, 0, err
+	}
 	err = db.pullIndex.PutInBatch(batch, item)
 	if err != nil {
 		return false, 0, err
@@ -311,7 +378,7 @@ func (db *DB) putSync(batch *badger.Txn, binIDs map[uint8]uint64, item shed.Item
 // a chunk is added to a node's localstore and given that the chunk is
 // already within that node's NN (thus, it can be added to the gc index
 // safely)
-func (db *DB) setGC(batch *badger.Txn, item shed.Item) (gcSizeChange int64, err error) {
+func (db *DB) setGC(batch shed.Batch, item shed.Item) (gcSizeChange int64, err error) {
 	if item.BinID == 0 {
 		i, err := db.retrievalDataIndex.Get(item)
 		if err != nil {
@@ -349,6 +416,15 @@ func (db *DB) setGC(batch *badger.Txn, item shed.Item) (gcSizeChange int64, err
 	return gcSizeChange, nil
 }
 
+// putStampInBatch stores the postage stamp of the item
+// if it has one.
+func (db *DB) putStampInBatch(batch shed.Batch, item shed.Item) (err error) {
+	if item.Stamp == nil {
+		return nil
+	}
+	return db.postageIndex.PutInBatch(batch, item)
+}
+
 // incBinID is a helper function for db.put* methods that increments bin id
 // based on the current value in the database. This function must be called under
 // a db.batchMu lock. Provided binID map is updated.
@@ -19,14 +19,22 @@ package localstore
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
 	"github.com/ethersphere/bee/pkg/shed"
+	mockstate "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	tagtesting "github.com/ethersphere/bee/pkg/tags/testing"
 )
 
 // TestModePutRequest validates ModePutRequest index values on the provided DB.
@@ -149,6 +157,39 @@ func TestModePutUpload(t *testing.T) {
 	}
 }
 
+// TestModePutUpload_tags validates that stored and seen counts
+// of upload tags are incremented by ModePutUpload.
+func TestModePutUpload_tags(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, &Options{Tags: tags.NewTags()})
+	defer cleanupFunc()
+
+	tag, err := db.tags.Create("test", 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := generateTestRandomChunks(2)
+	for i := range chunks {
+		chunks[i] = chunks[i].WithTagID(tag.Uid)
+	}
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	tagtesting.CheckTag(t, tag, 0, 2, 0, 0, 0, 3)
+
+	// the same chunk uploaded again is seen
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks[0]); err != nil {
+		t.Fatal(err)
+	}
+	tagtesting.CheckTag(t, tag, 0, 3, 1, 0, 0, 3)
+
+	// chunks from the network are not counted
+	if _, err := db.Put(context.Background(), storage.ModePutSync, generateTestRandomChunk().WithTagID(tag.Uid)); err != nil {
+		t.Fatal(err)
+	}
+	tagtesting.CheckTag(t, tag, 0, 3, 1, 0, 0, 3)
+}
+
 // TestModePutUpload_parallel uploads chunks in parallel
 // and validates if all chunks can be retrieved with correct data.
 func TestModePutUpload_parallel(t *testing.T) {
@@ -279,6 +320,7 @@ func TestModePut_sameChunk(t *testing.T) {
 					pushIndex: false,
 				},
 			} {
+				tcn := tcn
 				t.Run(tcn.name, func(t *testing.T) {
 					t.Parallel()
 
@@ -478,6 +520,94 @@ func TestPutDuplicateChunks(t *testing.T) {
 	}
 }
 
+// TestModePut_stamps validates that postage stamps of chunks received
+// from the network are validated before they are stored, and that the
+// stamps are returned with the chunks.
+func TestModePut_stamps(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchStore := batchstore.New(mockstate.NewStateStore())
+	batchID := make([]byte, postage.BatchIDSize)
+	if err := batchStore.Create(batchID, owner, big.NewInt(1), 8); err != nil {
+		t.Fatal(err)
+	}
+	stamper := postage.NewStamper(batchStore, mockstate.NewStateStore(), signer)
+
+	db, cleanupFunc := newTestDB(t, &Options{
+		ValidStamp: postage.ValidStamp(batchStore),
+	})
+	defer cleanupFunc()
+
+	for _, mode := range []storage.ModePut{
+		storage.ModePutRequest,
+		storage.ModePutSync,
+	} {
+		t.Run(mode.String(), func(t *testing.T) {
+			t.Run("no stamp", func(t *testing.T) {
+				ch := generateTestRandomChunk()
+				_, err := db.Put(context.Background(), mode, ch)
+				if !errors.Is(err, postage.ErrNoStamp) {
+					t.Fatalf("got error %v, want %v", err, postage.ErrNoStamp)
+				}
+				if _, err := db.Get(context.Background(), storage.ModeGetLookup, ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+					t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+				}
+			})
+
+			t.Run("invalid stamp", func(t *testing.T) {
+				ch := generateTestRandomChunk()
+				stamp, err := stamper.Stamp(batchID, generateTestRandomChunk().Address())
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = db.Put(context.Background(), mode, ch.WithStamp(stamp))
+				if !errors.Is(err, postage.ErrOwnerMismatch) {
+					t.Fatalf("got error %v, want %v", err, postage.ErrOwnerMismatch)
+				}
+				if _, err := db.Get(context.Background(), storage.ModeGetLookup, ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+					t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+				}
+			})
+
+			t.Run("valid stamp", func(t *testing.T) {
+				ch := generateTestRandomChunk()
+				stamp, err := stamper.Stamp(batchID, ch.Address())
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = db.Put(context.Background(), mode, ch.WithStamp(stamp))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := db.Get(context.Background(), storage.ModeGetLookup, ch.Address())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Stamp() == nil {
+					t.Fatal("got no stamp")
+				}
+				if got.Stamp().Index() != stamp.Index() || !bytes.Equal(got.Stamp().Sig(), stamp.Sig()) {
+					t.Fatalf("got stamp %+v, want %+v", got.Stamp(), stamp)
+				}
+			})
+		})
+	}
+
+	t.Run("upload without stamp", func(t *testing.T) {
+		_, err := db.Put(context.Background(), storage.ModePutUpload, generateTestRandomChunk())
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
 // BenchmarkPutUpload runs a series of benchmarks that upload
 // a specific number of chunks in parallel.
 //
@@ -21,7 +21,6 @@ import (
 	"errors"
 	"time"
 
-	"github.com/dgraph-io/badger/v2"
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -51,11 +50,11 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 	db.batchMu.Lock()
 	defer db.batchMu.Unlock()
 
-	batch := db.shed.GetBatch(true)
+	batch := db.shed.GetBatch()
 
 	// variables that provide information for operations
 	// to be done after write batch function successfully executes
-	var gcSizeChange int64                      // number to add or subtract from gcSize
+	gcSizeChange := make(gcSizeChanges)         // numbers to add or subtract from gc sizes of bins
 	triggerPullFeed := make(map[uint8]struct{}) // signal pull feed subscriptions to iterate
 
 	switch mode {
@@ -70,7 +69,7 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 			if err != nil {
 				return err
 			}
-			gcSizeChange += c
+			gcSizeChange.add(po, c)
 			triggerPullFeed[po] = struct{}{}
 		}
 		for po, id := range binIDs {
@@ -85,7 +84,7 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 			if err != nil {
 				return err
 			}
-			gcSizeChange += c
+			gcSizeChange.add(db.po(addr), c)
 		}
 
 	case storage.ModeSetRemove:
@@ -94,7 +93,7 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 			if err != nil {
 				return err
 			}
-			gcSizeChange += c
+			gcSizeChange.add(db.po(addr), c)
 		}
 
 	case storage.ModeSetPin:
@@ -125,6 +124,10 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 	if err != nil {
 		return err
 	}
+	db.checkStoredBytes()
+	if mode == storage.ModeSetRemove {
+		db.notifyRemoved(addrs...)
+	}
 	for po := range triggerPullFeed {
 		db.triggerPullSubscriptions(po)
 	}
@@ -134,7 +137,7 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 // setAccess sets the chunk access time by updating required indexes:
 //  - add to pull, insert to gc
 // Provided batch and binID map are updated.
-func (db *DB) setAccess(batch *badger.Txn, binIDs map[uint8]uint64, addr swarm.Address, po uint8) (gcSizeChange int64, err error) {
+func (db *DB) setAccess(batch shed.Batch, binIDs map[uint8]uint64, addr swarm.Address, po uint8) (gcSizeChange int64, err error) {
 
 	item := addressToItem(addr)
 
@@ -199,7 +202,7 @@ func (db *DB) setAccess(batch *badger.Txn, binIDs map[uint8]uint64, addr swarm.A
 //   from push sync index
 // - update to gc index happens given item does not exist in pin index
 // Provided batch is updated.
-func (db *DB) setSync(batch *badger.Txn, addr swarm.Address, mode storage.ModeSet) (gcSizeChange int64, err error) {
+func (db *DB) setSync(batch shed.Batch, addr swarm.Address, mode storage.ModeSet) (gcSizeChange int64, err error) {
 	item := addressToItem(addr)
 
 	// need to get access timestamp here as it is not
@@ -336,7 +339,7 @@ func (db *DB) setSync(batch *badger.Txn, addr swarm.Address, mode storage.ModeSe
 // setRemove removes the chunk by updating indexes:
 //  - delete from retrieve, pull, gc
 // Provided batch is updated.
-func (db *DB) setRemove(batch *badger.Txn, addr swarm.Address) (gcSizeChange int64, err error) {
+func (db *DB) setRemove(batch shed.Batch, addr swarm.Address) (gcSizeChange int64, err error) {
 	item := addressToItem(addr)
 
 	// need to get access timestamp here as it is not
@@ -369,6 +372,10 @@ func (db *DB) setRemove(batch *badger.Txn, addr swarm.Address) (gcSizeChange int
 	if err != nil {
 		return 0, err
 	}
+	err = db.postageIndex.DeleteInBatch(batch, item)
+	if err != nil {
+		return 0, err
+	}
 	err = db.gcIndex.DeleteInBatch(batch, item)
 	if err != nil {
 		return 0, err
@@ -386,7 +393,7 @@ func (db *DB) setRemove(batch *badger.Txn, addr swarm.Address) (gcSizeChange int
 // setPin increments pin counter for the chunk by updating
 // pin index and sets the chunk to be excluded from garbage collection.
 // Provided batch is updated.
-func (db *DB) setPin(batch *badger.Txn, addr swarm.Address) (err error) {
+func (db *DB) setPin(batch shed.Batch, addr swarm.Address) (err error) {
 	item := addressToItem(addr)
 
 	// Get the existing pin counter of the chunk
@@ -421,7 +428,7 @@ func (db *DB) setPin(batch *badger.Txn, addr swarm.Address) (err error) {
 
 // setUnpin decrements pin counter for the chunk by updating pin index.
 // Provided batch is updated.
-func (db *DB) setUnpin(batch *badger.Txn, addr swarm.Address) (err error) {
+func (db *DB) setUnpin(batch shed.Batch, addr swarm.Address) (err error) {
 	item := addressToItem(addr)
 
 	// Get the existing pin counter of the chunk
@@ -83,8 +83,6 @@ func TestModeSetSyncPullNormalTag(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tag.Inc(tags.StateStored) // so we don't get an error on tag.Status later on
-
 	item, err := db.pullIndex.Get(shed.Item{
 		Address: ch.Address().Bytes(),
 		BinID:   1,
@@ -116,7 +114,7 @@ func TestModeSetSyncPullNormalTag(t *testing.T) {
 		t.Fatalf("unexpected tag id value got %d want %d", item.Tag, tag.Uid)
 	}
 
-	// 1 stored (because incremented manually in test), 1 sent, 1 total
+	// 1 stored (incremented by put upload), 1 sent, 1 total
 	tagtesting.CheckTag(t, tag, 0, 1, 0, 1, 0, 1)
 }
 
@@ -136,7 +134,6 @@ func TestModeSetSyncPullAnonymousTag(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	tag.Inc(tags.StateStored) // so we don't get an error on tag.Status later on
 
 	item, err := db.pullIndex.Get(shed.Item{
 		Address: ch.Address().Bytes(),
@@ -167,7 +164,7 @@ func TestModeSetSyncPullAnonymousTag(t *testing.T) {
 		t.Fatalf("unexpected tag id value got %d want %d", item.Tag, 0)
 	}
 
-	// 1 stored (because incremented manually in test), 1 sent, 1 total
+	// 1 stored (incremented by put upload), 1 sent, 1 total
 	tagtesting.CheckTag(t, tag, 0, 1, 0, 1, 0, 1)
 }
 
@@ -189,7 +186,6 @@ func TestModeSetSyncPullPushAnonymousTag(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tag.Inc(tags.StateStored) // so we don't get an error on tag.Status later on
 	item, err := db.pullIndex.Get(shed.Item{
 		Address: ch.Address().Bytes(),
 		BinID:   1,
@@ -227,7 +223,7 @@ func TestModeSetSyncPullPushAnonymousTag(t *testing.T) {
 		t.Fatalf("unexpected tag id value got %d want %d", item.Tag, 0)
 	}
 
-	// 1 stored (because incremented manually in test), 1 sent, 1 total
+	// 1 stored (incremented by put upload), 1 sent, 1 total
 	tagtesting.CheckTag(t, tag, 0, 1, 0, 1, 0, 1)
 
 	// verify that the item does not exist in the push index
@@ -258,7 +254,6 @@ func TestModeSetSyncPushNormalTag(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tag.Inc(tags.StateStored) // so we don't get an error on tag.Status later on
 	item, err := db.pullIndex.Get(shed.Item{
 		Address: ch.Address().Bytes(),
 		BinID:   1,
@@ -0,0 +1,43 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// PinnedChunks returns at most limit pinned chunks ordered by their
+// addresses. If the cursor is not zero, only chunks with addresses
+// greater than the cursor are returned.
+func (db *DB) PinnedChunks(ctx context.Context, cursor swarm.Address, limit int) (pinnedChunks []*storage.Pinner, err error) {
+	var opts *shed.IterateOptions
+	if !cursor.IsZero() {
+		opts = &shed.IterateOptions{
+			StartFrom:         &shed.Item{Address: cursor.Bytes()},
+			SkipStartFromItem: true,
+		}
+	}
+	err = db.pinIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		default:
+		}
+		pinnedChunks = append(pinnedChunks, &storage.Pinner{
+			Address:    swarm.NewAddress(item.Address),
+			PinCounter: item.PinCounter,
+		})
+		return len(pinnedChunks) >= limit, nil
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("pinned chunks: %w", err)
+	}
+	return pinnedChunks, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestPinnedChunks(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	ctx := context.Background()
+	chunks := generateTestRandomChunks(5)
+	if _, err := db.Put(ctx, storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	addrs := chunkAddresses(chunks)
+	if err := db.Set(ctx, storage.ModeSetPin, addrs...); err != nil {
+		t.Fatal(err)
+	}
+	// pin the first chunk once more
+	if err := db.Set(ctx, storage.ModeSetPin, addrs[0]); err != nil {
+		t.Fatal(err)
+	}
+	pinCounters := map[string]uint64{addrs[0].String(): 2}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].String() < addrs[j].String()
+	})
+
+	var got []*storage.Pinner
+	cursor := swarm.ZeroAddress
+	for {
+		pinned, err := db.PinnedChunks(ctx, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pinned) == 0 {
+			break
+		}
+		if len(pinned) > 2 {
+			t.Fatalf("got %v pinned chunks, want at most 2", len(pinned))
+		}
+		got = append(got, pinned...)
+		cursor = pinned[len(pinned)-1].Address
+	}
+
+	if len(got) != len(addrs) {
+		t.Fatalf("got %v pinned chunks, want %v", len(got), len(addrs))
+	}
+	for i, p := range got {
+		if !p.Address.Equal(addrs[i]) {
+			t.Errorf("pinned chunk %v: got address %s, want %s", i, p.Address, addrs[i])
+		}
+		want, ok := pinCounters[p.Address.String()]
+		if !ok {
+			want = 1
+		}
+		if p.PinCounter != want {
+			t.Errorf("pinned chunk %s: got pin counter %v, want %v", p.Address, p.PinCounter, want)
+		}
+	}
+}
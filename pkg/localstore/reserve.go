@@ -0,0 +1,192 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// NoReserveRadius is the reserve radius with which all
+// chunks are in the cache and no chunks are in the reserve.
+const NoReserveRadius = swarm.MaxPO + 1
+
+// gcSizeChanges holds changes of the number of
+// gc index items by their proximity order bins.
+type gcSizeChanges map[uint8]int64
+
+// add adds the change to the bin.
+func (c gcSizeChanges) add(po uint8, change int64) {
+	if change != 0 {
+		c[po] += change
+	}
+}
+
+// gcSizes holds the number of items in gc index
+// that are in the reserve and in the cache.
+type gcSizes struct {
+	reserve uint64
+	cache   uint64
+}
+
+// SetReserveRadius sets the proximity order from which synced
+// chunks are kept in the reserve, instead of the cache. It is
+// called by the topology driver when the neighbourhood depth
+// changes. With NoReserveRadius all chunks are in the cache.
+func (db *DB) SetReserveRadius(radius uint8) {
+	if radius > NoReserveRadius {
+		radius = NoReserveRadius
+	}
+	if old := atomic.SwapUint32(&db.radius, uint32(radius)); old == uint32(radius) {
+		return
+	}
+	db.metrics.ReserveRadius.Set(float64(radius))
+
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	sizes, err := db.gcSizes()
+	if err != nil {
+		db.logger.Debugf("localstore set reserve radius: %v", err)
+		db.logger.Error("localstore set reserve radius")
+		return
+	}
+	db.checkGCSizes(sizes)
+}
+
+// ReserveRadius returns the proximity order from which
+// synced chunks are kept in the reserve.
+func (db *DB) ReserveRadius() (radius uint8) {
+	return uint8(atomic.LoadUint32(&db.radius))
+}
+
+// gcSizes returns the current sizes of the reserve and the cache.
+func (db *DB) gcSizes() (sizes gcSizes, err error) {
+	radius := db.ReserveRadius()
+	for po := uint8(0); po <= swarm.MaxPO; po++ {
+		size, err := db.gcBinSizes.Get(uint64(po))
+		if err != nil {
+			return sizes, err
+		}
+		if po >= radius {
+			sizes.reserve += size
+		} else {
+			sizes.cache += size
+		}
+	}
+	return sizes, nil
+}
+
+// putGCSizesInBatch applies changes to the gc size and gc sizes
+// of bins and returns new sizes of the reserve and the cache.
+// This function must be called under batchMu lock.
+func (db *DB) putGCSizesInBatch(batch shed.Batch, change gcSizeChanges) (sizes gcSizes, err error) {
+	var total int64
+	for po, c := range change {
+		size, err := db.gcBinSizes.Get(uint64(po))
+		if err != nil {
+			return sizes, err
+		}
+		if err := db.gcBinSizes.PutInBatch(batch, uint64(po), addToSize(size, c)); err != nil {
+			return sizes, err
+		}
+		total += c
+	}
+	if total != 0 {
+		gcSize, err := db.gcSize.Get()
+		if err != nil && !errors.Is(err, shed.ErrNotFound) {
+			return sizes, err
+		}
+		if err := db.gcSize.PutInBatch(batch, addToSize(gcSize, total)); err != nil {
+			return sizes, err
+		}
+	}
+
+	// compute sizes with changes that are not yet written
+	radius := db.ReserveRadius()
+	for po := uint8(0); po <= swarm.MaxPO; po++ {
+		size, err := db.gcBinSizes.Get(uint64(po))
+		if err != nil {
+			return sizes, err
+		}
+		size = addToSize(size, change[po])
+		if po >= radius {
+			sizes.reserve += size
+		} else {
+			sizes.cache += size
+		}
+	}
+	return sizes, nil
+}
+
+// checkGCSizes updates size metrics and triggers garbage collection
+// if the reserve or the cache reached their capacities.
+func (db *DB) checkGCSizes(sizes gcSizes) {
+	db.metrics.ReserveSize.Set(float64(sizes.reserve))
+	db.metrics.CacheSize.Set(float64(sizes.cache))
+
+	if sizes.reserve >= db.reserveCapacity || (db.capacity > 0 && sizes.cache >= db.capacity) {
+		db.triggerGarbageCollection()
+	}
+}
+
+// reserveTarget returns the number of chunks that garbage collection
+// leaves in the reserve, calculated from db.reserveCapacity and
+// gcTargetRatio.
+func (db *DB) reserveTarget() (target uint64) {
+	return uint64(float64(db.reserveCapacity) * gcTargetRatio)
+}
+
+// collectReserveGarbage calls the collect function for synced chunks
+// in the reserve until it returns true. Chunks with the lowest
+// proximity order to the base key are collected first, and in the
+// same bin in the order in which they are stored.
+func (db *DB) collectReserveGarbage(radius uint8, collect func(item shed.Item) (stop bool, err error)) (err error) {
+	var stopped bool
+	for po := int(radius); po <= swarm.MaxPO && !stopped; po++ {
+		err := db.pullIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			// only synced chunks that are not pinned are in the gc index
+			i, err := db.retrievalAccessIndex.Get(item)
+			if err != nil {
+				if errors.Is(err, shed.ErrNotFound) {
+					return false, nil
+				}
+				return true, err
+			}
+			item.AccessTimestamp = i.AccessTimestamp
+			ok, err := db.gcIndex.Has(item)
+			if err != nil {
+				return true, err
+			}
+			if !ok {
+				return false, nil
+			}
+			stopped, err = collect(item)
+			return stopped, err
+		}, &shed.IterateOptions{
+			Prefix: []byte{uint8(po)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addToSize adds the change to the size,
+// protecting it from an underflow.
+func addToSize(size uint64, change int64) uint64 {
+	if change < 0 {
+		c := uint64(-change)
+		if c > size {
+			return 0
+		}
+		return size - c
+	}
+	return size + uint64(change)
+}
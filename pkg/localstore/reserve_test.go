@@ -0,0 +1,242 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestDB_reserveCache validates that garbage collection of the cache
+// does not remove chunks in the reserve.
+func TestDB_reserveCache(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, &Options{
+		Capacity:        10,
+		ReserveCapacity: 1000,
+	})
+	defer cleanupFunc()
+
+	radius := uint8(1)
+	db.SetReserveRadius(radius)
+
+	reserve, cache := putSyncedTestChunks(t, db, radius, 60)
+
+	cacheTarget := db.gcTarget()
+	waitGCSizes(t, db, func(reserveSize, cacheSize int) bool {
+		return cacheSize <= int(cacheTarget)
+	})
+
+	indexes, err := db.DebugIndices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexes["reserveSize"], len(reserve); got != want {
+		t.Errorf("got reserve size %v, want %v", got, want)
+	}
+	if got, want := indexes["cacheSize"], int(cacheTarget); got != want {
+		t.Errorf("got cache size %v, want %v", got, want)
+	}
+	for _, ch := range reserve {
+		if _, err := db.Get(context.Background(), storage.ModeGetRequest, ch.Address()); err != nil {
+			t.Errorf("reserve chunk %s: %v", ch.Address(), err)
+		}
+	}
+	// the most recently synced chunks are kept in the cache
+	for _, ch := range cache[len(cache)-int(cacheTarget):] {
+		if _, err := db.Get(context.Background(), storage.ModeGetRequest, ch.Address()); err != nil {
+			t.Errorf("cache chunk %s: %v", ch.Address(), err)
+		}
+	}
+
+	t.Run("radius change", func(t *testing.T) {
+		db.SetReserveRadius(NoReserveRadius)
+		if got := db.ReserveRadius(); got != NoReserveRadius {
+			t.Errorf("got radius %v, want %v", got, NoReserveRadius)
+		}
+		// reserve chunks are now in the cache and collected
+		waitGCSizes(t, db, func(reserveSize, cacheSize int) bool {
+			return reserveSize == 0 && cacheSize <= int(cacheTarget)
+		})
+	})
+}
+
+// TestDB_reserveGarbage validates that garbage collection of the
+// full reserve removes chunks with the lowest proximity order first.
+func TestDB_reserveGarbage(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, &Options{
+		Capacity:        1000,
+		ReserveCapacity: 20,
+	})
+	defer cleanupFunc()
+
+	db.SetReserveRadius(0)
+
+	reserve, _ := putSyncedTestChunks(t, db, 0, 40)
+
+	reserveTarget := db.reserveTarget()
+	waitGCSizes(t, db, func(reserveSize, cacheSize int) bool {
+		return reserveSize <= int(reserveTarget)
+	})
+
+	// find the lowest proximity order of kept chunks
+	// and the highest of removed ones
+	minKept := -1
+	maxRemoved := -1
+	var kept int
+	for _, ch := range reserve {
+		po := int(db.po(ch.Address()))
+		_, err := db.Get(context.Background(), storage.ModeGetRequest, ch.Address())
+		switch err {
+		case nil:
+			kept++
+			if minKept < 0 || po < minKept {
+				minKept = po
+			}
+		case storage.ErrNotFound:
+			if po > maxRemoved {
+				maxRemoved = po
+			}
+		default:
+			t.Fatal(err)
+		}
+	}
+	if kept != int(reserveTarget) {
+		t.Errorf("got %v kept chunks, want %v", kept, reserveTarget)
+	}
+	if maxRemoved > minKept {
+		t.Errorf("removed chunk with proximity order %v, while keeping one with %v", maxRemoved, minKept)
+	}
+}
+
+// TestDB_reserveCapacityBytes validates that the reserve is garbage
+// collected when the database is over its capacity in bytes, and the
+// cache is empty.
+func TestDB_reserveCapacityBytes(t *testing.T) {
+	// measure the number of bytes that a synced chunk takes
+	db, cleanupFunc := newTestDB(t, nil)
+	putSyncedTestChunks(t, db, 0, 1)
+	chunkBytes := db.storedBytes()
+	cleanupFunc()
+
+	capacityBytes := 20 * chunkBytes
+	db, cleanupFunc = newTestDB(t, &Options{
+		CapacityBytes:   capacityBytes,
+		ReserveCapacity: 1000,
+	})
+	defer cleanupFunc()
+
+	// all chunks are in the reserve
+	db.SetReserveRadius(0)
+	putSyncedTestChunks(t, db, 0, 40)
+
+	timeout := time.After(10 * time.Second)
+	for db.storedBytes() >= capacityBytes {
+		select {
+		case <-timeout:
+			t.Fatalf("got %v stored bytes, want less than %v", db.storedBytes(), capacityBytes)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	waitGCSizes(t, db, func(reserveSize, cacheSize int) bool {
+		return reserveSize > 0 && reserveSize < 20 && cacheSize == 0
+	})
+}
+
+// TestMigrateReserve validates that the reserve migration
+// computes gc sizes of bins in an existing database.
+func TestMigrateReserve(t *testing.T) {
+	dir, baseKey, cleanup := newMigrationTestDir(t)
+	defer cleanup()
+
+	logger := logging.New(ioutil.Discard, 0)
+	db, err := New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reserve, cache := putSyncedTestChunks(t, db, 1, 20)
+	// reset the database to the schema before the migration
+	for po := uint64(0); po <= swarm.MaxPO; po++ {
+		if err := db.gcBinSizes.Put(po, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.schemaName.Put(DbSchemaCode); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.SetReserveRadius(1)
+	indexes, err := db.DebugIndices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexes["reserveSize"], len(reserve); got != want {
+		t.Errorf("got reserve size %v, want %v", got, want)
+	}
+	if got, want := indexes["cacheSize"], len(cache); got != want {
+		t.Errorf("got cache size %v, want %v", got, want)
+	}
+}
+
+// putSyncedTestChunks uploads and syncs count chunks in a single
+// call and returns them split by the radius to the reserve and
+// cache chunks in the order in which they are stored.
+func putSyncedTestChunks(t *testing.T, db *DB, radius uint8, count int) (reserve, cache []swarm.Chunk) {
+	t.Helper()
+
+	chunks := generateTestRandomChunks(count)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(context.Background(), storage.ModeSetSyncPull, chunkAddresses(chunks)...); err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range chunks {
+		if db.po(ch.Address()) >= radius {
+			reserve = append(reserve, ch)
+		} else {
+			cache = append(cache, ch)
+		}
+	}
+	return reserve, cache
+}
+
+// waitGCSizes waits until the reserve and cache sizes satisfy the
+// condition, checking them after every garbage collection run.
+func waitGCSizes(t *testing.T, db *DB, cond func(reserveSize, cacheSize int) bool) {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		db.batchMu.Lock()
+		sizes, err := db.gcSizes()
+		db.batchMu.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cond(int(sizes.reserve), int(sizes.cache)) {
+			return
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("timeout waiting for gc sizes, reserve %v, cache %v", sizes.reserve, sizes.cache)
+		}
+	}
+}
@@ -73,9 +73,19 @@ func (db *DB) SubscribePush(ctx context.Context) (c <-chan swarm.Chunk, stop fun
 					if err != nil {
 						return true, err
 					}
+					// get postage stamp
+					if s, err := db.postageIndex.Get(item); err == nil {
+						dataItem.Stamp = s.Stamp
+					} else if err != shed.ErrNotFound {
+						return true, err
+					}
+					ch, err := withStamp(swarm.NewChunk(swarm.NewAddress(dataItem.Address), dataItem.Data).WithTagID(item.Tag), dataItem)
+					if err != nil {
+						return true, err
+					}
 
 					select {
-					case chunks <- swarm.NewChunk(swarm.NewAddress(dataItem.Address), dataItem.Data).WithTagID(item.Tag):
+					case chunks <- ch:
 						count++
 						// set next iteration start item
 						// when its chunk is successfully sent to channel
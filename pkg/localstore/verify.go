@@ -0,0 +1,517 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// VerifyOptions holds optional parameters for Verify and Repair.
+type VerifyOptions struct {
+	// Validator, if set, validates the content of every stored chunk.
+	Validator swarm.ChunkValidator
+}
+
+// VerifyReport holds the results of the database integrity check.
+type VerifyReport struct {
+	// Chunks is the number of chunks in the retrieval data index.
+	Chunks int64 `json:"chunks"`
+	// InvalidChunks is the number of chunks with content
+	// that is not valid for their addresses.
+	InvalidChunks int64 `json:"invalidChunks"`
+	// Indexes holds the reports for every index by its name.
+	Indexes map[string]*IndexReport `json:"indexes"`
+	// GCSize holds the stored and computed gc size.
+	GCSize ValueReport `json:"gcSize"`
+	// GCBinSizes holds the stored and computed gc sizes
+	// for bins in which the stored value is not correct.
+	GCBinSizes map[uint8]ValueReport `json:"gcBinSizes"`
+	// BinIDs holds the stored and computed last bin IDs
+	// for bins in which the stored value is not correct.
+	BinIDs map[uint8]ValueReport `json:"binIDs"`
+	// Repaired is true if the found problems are repaired.
+	Repaired bool `json:"repaired"`
+}
+
+// IndexReport holds the results of a single index check.
+type IndexReport struct {
+	// Items is the number of checked index items.
+	Items int64 `json:"items"`
+	// Dangling is the number of items for chunks that
+	// are not in the retrieval data index.
+	Dangling int64 `json:"dangling"`
+	// Invalid is the number of items that are not
+	// consistent with other indexes.
+	Invalid int64 `json:"invalid"`
+	// Missing is the number of items that should be
+	// in the index, but are not.
+	Missing int64 `json:"missing"`
+}
+
+// ValueReport holds a stored value and its computed correct value.
+type ValueReport struct {
+	Stored   uint64 `json:"stored"`
+	Computed uint64 `json:"computed"`
+}
+
+// OK returns true if no problems are found.
+func (r *VerifyReport) OK() bool {
+	if r.InvalidChunks > 0 || len(r.BinIDs) > 0 || len(r.GCBinSizes) > 0 || r.GCSize.Stored != r.GCSize.Computed {
+		return false
+	}
+	for _, i := range r.Indexes {
+		if i.Dangling > 0 || i.Invalid > 0 || i.Missing > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// repairBatchSize is the maximal number of repair
+// operations committed in a single transaction.
+var repairBatchSize = 1000
+
+// Verify cross-checks all indexes, the gc sizes and the bin IDs
+// vector and optionally validates chunk content. The database is
+// not changed. All writes are blocked until the check is done.
+func (db *DB) Verify(o *VerifyOptions) (*VerifyReport, error) {
+	return db.verify(o, false)
+}
+
+// Repair performs the same checks as Verify and repairs the found
+// problems. Chunks with invalid content and dangling index items are
+// removed, index items that are missing or not consistent with the
+// retrieval data index are put back with the values derived from it,
+// missing gc exclude items for pinned chunks are added, and the gc
+// sizes and bin IDs are recomputed.
+func (db *DB) Repair(o *VerifyOptions) (*VerifyReport, error) {
+	return db.verify(o, true)
+}
+
+func (db *DB) verify(o *VerifyOptions, repair bool) (r *VerifyReport, err error) {
+	if o == nil {
+		o = new(VerifyOptions)
+	}
+
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	r = &VerifyReport{
+		Indexes:    make(map[string]*IndexReport),
+		GCBinSizes: make(map[uint8]ValueReport),
+		BinIDs:     make(map[uint8]ValueReport),
+	}
+	w := &repairWriter{db: db, enabled: repair}
+
+	// check chunks and find the largest bin ID in every bin
+	invalid := make(map[string]struct{})
+	maxBinIDs := make(map[uint8]uint64)
+	gcBinSizes := make(map[uint8]uint64)
+	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		r.Chunks++
+		addr := swarm.NewAddress(item.Address)
+		po := db.po(addr)
+		if item.BinID > maxBinIDs[po] {
+			maxBinIDs[po] = item.BinID
+		}
+		if o.Validator == nil || o.Validator.Validate(swarm.NewChunk(addr, item.Data)) {
+			return false, nil
+		}
+		r.InvalidChunks++
+		invalid[addr.String()] = struct{}{}
+		return false, w.apply(func(batch shed.Batch) error {
+			return db.removeInBatch(batch, item)
+		})
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval data index: %w", err)
+	}
+	if err := w.flush(); err != nil {
+		return nil, err
+	}
+
+	// exists reports if the chunk is stored and has valid content
+	exists := func(item shed.Item) (bool, error) {
+		if _, ok := invalid[swarm.NewAddress(item.Address).String()]; ok {
+			return !repair, nil
+		}
+		return db.retrievalDataIndex.Has(item)
+	}
+
+	// checkIndex iterates over the index and removes dangling items
+	// and items for which the check function returns false
+	checkIndex := func(name string, index shed.Index, check func(item shed.Item) (bool, error)) error {
+		ir := new(IndexReport)
+		r.Indexes[name] = ir
+		err := index.Iterate(func(item shed.Item) (stop bool, err error) {
+			ir.Items++
+			ok, err := exists(item)
+			if err != nil {
+				return true, err
+			}
+			if !ok {
+				ir.Dangling++
+			} else if check != nil {
+				if ok, err = check(item); err != nil {
+					return true, err
+				}
+				if !ok {
+					ir.Invalid++
+				}
+			}
+			if ok {
+				return false, nil
+			}
+			return false, w.apply(func(batch shed.Batch) error {
+				return index.DeleteInBatch(batch, item)
+			})
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return w.flush()
+	}
+
+	// items with keys that do not match the retrieval data and access
+	// indexes are removed, but they are kept by chunk address as they
+	// hold values that are needed to put the corrected items back
+	stalePull := make(map[string]shed.Item)
+	stalePush := make(map[string]shed.Item)
+	staleGC := make(map[string]shed.Item)
+
+	if err := checkIndex("retrievalAccessIndex", db.retrievalAccessIndex, nil); err != nil {
+		return nil, err
+	}
+	if err := checkIndex("pullIndex", db.pullIndex, func(item shed.Item) (bool, error) {
+		i, err := db.retrievalDataIndex.Get(item)
+		if err != nil {
+			return false, err
+		}
+		if i.BinID == item.BinID {
+			return true, nil
+		}
+		stalePull[swarm.NewAddress(item.Address).String()] = item
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := checkIndex("pushIndex", db.pushIndex, func(item shed.Item) (bool, error) {
+		i, err := db.retrievalDataIndex.Get(item)
+		if err != nil {
+			return false, err
+		}
+		if i.StoreTimestamp == item.StoreTimestamp {
+			return true, nil
+		}
+		stalePush[swarm.NewAddress(item.Address).String()] = item
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := checkIndex("gcIndex", db.gcIndex, func(item shed.Item) (bool, error) {
+		i, err := db.retrievalDataIndex.Get(item)
+		if err != nil {
+			return false, err
+		}
+		a, err := db.retrievalAccessIndex.Get(item)
+		if err != nil && !errors.Is(err, shed.ErrNotFound) {
+			return false, err
+		}
+		if err == nil && i.BinID == item.BinID && a.AccessTimestamp == item.AccessTimestamp {
+			return true, nil
+		}
+		// the latest access timestamp is kept for
+		// chunks without the retrieval access item
+		key := swarm.NewAddress(item.Address).String()
+		if s, ok := staleGC[key]; !ok || s.AccessTimestamp < item.AccessTimestamp {
+			staleGC[key] = item
+		}
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := checkIndex("gcExcludeIndex", db.gcExcludeIndex, nil); err != nil {
+		return nil, err
+	}
+	if err := checkIndex("postageIndex", db.postageIndex, nil); err != nil {
+		return nil, err
+	}
+
+	// derive the expected items of every chunk in the indexes
+	// that depend on the retrieval data index and put back the
+	// ones that are missing or were removed as inconsistent
+	accessReport := r.Indexes["retrievalAccessIndex"]
+	pullReport := r.Indexes["pullIndex"]
+	gcReport := r.Indexes["gcIndex"]
+	put := func(index shed.Index, item shed.Item) error {
+		return w.apply(func(batch shed.Batch) error {
+			return index.PutInBatch(batch, item)
+		})
+	}
+	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		addr := swarm.NewAddress(item.Address)
+		key := addr.String()
+		if _, ok := invalid[key]; ok {
+			return false, nil
+		}
+		item.Data = nil
+
+		// chunks waiting for push syncing are in the push index
+		// with the store timestamp of the retrieval data index
+		i, err := db.pushIndex.Get(item)
+		inPush := err == nil
+		if err != nil && !errors.Is(err, shed.ErrNotFound) {
+			return true, err
+		}
+		if s, ok := stalePush[key]; ok && !inPush {
+			i = item
+			i.Tag = s.Tag
+			if err := put(db.pushIndex, i); err != nil {
+				return true, err
+			}
+			inPush = true
+		}
+		pushTag := i.Tag
+
+		// uploaded chunks are in the pull index under their bin
+		// ID, chunks that are not uploaded are in it only if they
+		// were synced or accessed, which can not be derived
+		i, err = db.pullIndex.Get(item)
+		switch {
+		case err == nil:
+			// bin ids are unique in bins, a different address
+			// means that another chunk has the same bin id,
+			// which can not be repaired without changing it
+		case errors.Is(err, shed.ErrNotFound):
+			i = item
+			if s, ok := stalePull[key]; ok {
+				i.Tag = s.Tag
+			} else if inPush {
+				pullReport.Missing++
+				i.Tag = pushTag
+			} else {
+				break
+			}
+			if err := put(db.pullIndex, i); err != nil {
+				return true, err
+			}
+		default:
+			return true, err
+		}
+
+		// accessed chunks are in the gc index unless they
+		// are pinned, the access timestamp of a removed gc
+		// item is used if the chunk has no access item
+		i, err = db.retrievalAccessIndex.Get(item)
+		switch {
+		case err == nil:
+			item.AccessTimestamp = i.AccessTimestamp
+		case errors.Is(err, shed.ErrNotFound):
+			s, ok := staleGC[key]
+			if !ok {
+				// the chunk is not accessed
+				return false, nil
+			}
+			accessReport.Missing++
+			item.AccessTimestamp = s.AccessTimestamp
+			if err := put(db.retrievalAccessIndex, item); err != nil {
+				return true, err
+			}
+		default:
+			return true, err
+		}
+		inGC, err := db.gcIndex.Has(item)
+		if err != nil {
+			return true, err
+		}
+		if !inGC {
+			pinned, err := db.pinIndex.Has(item)
+			if err != nil {
+				return true, err
+			}
+			excluded, err := db.gcExcludeIndex.Has(item)
+			if err != nil {
+				return true, err
+			}
+			if pinned || excluded {
+				return false, nil
+			}
+			if _, ok := staleGC[key]; !ok {
+				gcReport.Missing++
+			}
+			if err := put(db.gcIndex, item); err != nil {
+				return true, err
+			}
+		}
+		r.GCSize.Computed++
+		gcBinSizes[db.po(addr)]++
+		return false, nil
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval data index: %w", err)
+	}
+	if err := w.flush(); err != nil {
+		return nil, err
+	}
+
+	// pinned chunks that are in the gc index must be in
+	// the gc exclude index not to be garbage collected
+	gcExcludeReport := r.Indexes["gcExcludeIndex"]
+	if err := checkIndex("pinIndex", db.pinIndex, func(item shed.Item) (bool, error) {
+		excluded, err := db.gcExcludeIndex.Has(item)
+		if err != nil || excluded {
+			return true, err
+		}
+		a, err := db.retrievalAccessIndex.Get(item)
+		if err != nil {
+			if errors.Is(err, shed.ErrNotFound) {
+				// not accessed chunks are not in the gc index
+				return true, nil
+			}
+			return true, err
+		}
+		i, err := db.retrievalDataIndex.Get(item)
+		if err != nil {
+			return true, err
+		}
+		item.AccessTimestamp = a.AccessTimestamp
+		item.BinID = i.BinID
+		inGC, err := db.gcIndex.Has(item)
+		if err != nil || !inGC {
+			return true, err
+		}
+		gcExcludeReport.Missing++
+		return true, w.apply(func(batch shed.Batch) error {
+			return db.gcExcludeIndex.PutInBatch(batch, item)
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	// gc sizes and bin IDs
+	if r.GCSize.Stored, err = db.gcSize.Get(); err != nil && !errors.Is(err, shed.ErrNotFound) {
+		return nil, fmt.Errorf("gc size: %w", err)
+	}
+	if r.GCSize.Stored != r.GCSize.Computed {
+		if err := w.apply(func(batch shed.Batch) error {
+			return db.gcSize.PutInBatch(batch, r.GCSize.Computed)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for bin := uint8(0); bin <= swarm.MaxPO; bin++ {
+		stored, err := db.gcBinSizes.Get(uint64(bin))
+		if err != nil {
+			return nil, fmt.Errorf("gc bin size: %w", err)
+		}
+		if stored == gcBinSizes[bin] {
+			continue
+		}
+		r.GCBinSizes[bin] = ValueReport{
+			Stored:   stored,
+			Computed: gcBinSizes[bin],
+		}
+		if err := w.apply(func(batch shed.Batch) error {
+			return db.gcBinSizes.PutInBatch(batch, uint64(bin), gcBinSizes[bin])
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for bin := uint8(0); bin <= swarm.MaxPO; bin++ {
+		stored, err := db.binIDs.Get(uint64(bin))
+		if err != nil {
+			return nil, fmt.Errorf("bin id: %w", err)
+		}
+		// bin IDs must not be decreased as they are
+		// used as cursors by pull syncing peers
+		if stored >= maxBinIDs[bin] {
+			continue
+		}
+		r.BinIDs[bin] = ValueReport{
+			Stored:   stored,
+			Computed: maxBinIDs[bin],
+		}
+		if err := w.apply(func(batch shed.Batch) error {
+			return db.binIDs.PutInBatch(batch, uint64(bin), maxBinIDs[bin])
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.flush(); err != nil {
+		return nil, err
+	}
+
+	r.Repaired = repair && !r.OK()
+	return r, nil
+}
+
+// removeInBatch removes the chunk from all indexes.
+func (db *DB) removeInBatch(batch shed.Batch, item shed.Item) (err error) {
+	i, err := db.retrievalAccessIndex.Get(item)
+	switch {
+	case err == nil:
+		item.AccessTimestamp = i.AccessTimestamp
+		if err := db.gcIndex.DeleteInBatch(batch, item); err != nil {
+			return err
+		}
+	case !errors.Is(err, shed.ErrNotFound):
+		return err
+	}
+	for _, index := range []shed.Index{
+		db.retrievalDataIndex,
+		db.retrievalAccessIndex,
+		db.pullIndex,
+		db.pushIndex,
+		db.gcExcludeIndex,
+		db.pinIndex,
+		db.postageIndex,
+	} {
+		if err := index.DeleteInBatch(batch, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repairWriter applies repair operations in transactions that are
+// committed after every repairBatchSize operations. If it is not
+// enabled, operations are not applied.
+type repairWriter struct {
+	db      *DB
+	enabled bool
+	batch   shed.Batch
+	count   int
+}
+
+func (w *repairWriter) apply(f func(batch shed.Batch) error) error {
+	if !w.enabled {
+		return nil
+	}
+	if w.batch == nil {
+		w.batch = w.db.shed.GetBatch()
+	}
+	if err := f(w.batch); err != nil {
+		return err
+	}
+	w.count++
+	if w.count >= repairBatchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *repairWriter) flush() error {
+	if w.batch == nil {
+		return nil
+	}
+	err := w.db.shed.WriteBatch(w.batch)
+	w.batch = nil
+	w.count = 0
+	return err
+}
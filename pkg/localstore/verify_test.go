@@ -0,0 +1,221 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestVerifyRepair corrupts the database and validates that the
+// problems are reported by Verify and fixed by Repair.
+func TestVerifyRepair(t *testing.T) {
+	db, cleanup := newTestDB(t, nil)
+	defer cleanup()
+
+	ctx := context.Background()
+	var addrs []swarm.Address
+	for i := 0; i < 10; i++ {
+		ch := generateTestRandomChunk()
+		if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, ch.Address())
+	}
+	if err := db.Set(ctx, storage.ModeSetSyncPull, addrs...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(ctx, storage.ModeSetPin, addrs[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := addrs[1]
+	o := &VerifyOptions{
+		Validator: chunkValidatorFunc(func(ch swarm.Chunk) bool {
+			return !ch.Address().Equal(invalid)
+		}),
+	}
+
+	r, err := db.Verify(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.InvalidChunks != 1 {
+		t.Fatalf("got %v invalid chunks, want 1", r.InvalidChunks)
+	}
+	if r, err := db.Verify(nil); err != nil {
+		t.Fatal(err)
+	} else if !r.OK() {
+		t.Fatalf("got report %+v for consistent database", r)
+	}
+
+	// remove chunk data, leaving other index items dangling
+	if err := db.retrievalDataIndex.Delete(addressToItem(addrs[0])); err != nil {
+		t.Fatal(err)
+	}
+	// remove the gc exclude item of a pinned chunk
+	if err := db.gcExcludeIndex.Delete(addressToItem(addrs[2])); err != nil {
+		t.Fatal(err)
+	}
+	// move the pull item of a chunk to a wrong bin id
+	item, err := db.retrievalDataIndex.Get(addressToItem(addrs[4]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pullItem, err := db.pullIndex.Get(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.pullIndex.Delete(pullItem); err != nil {
+		t.Fatal(err)
+	}
+	pullItem.BinID += 100
+	if err := db.pullIndex.Put(pullItem); err != nil {
+		t.Fatal(err)
+	}
+	// remove the gc item of a chunk
+	item, err = db.retrievalDataIndex.Get(addressToItem(addrs[5]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessItem, err := db.retrievalAccessIndex.Get(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.AccessTimestamp = accessItem.AccessTimestamp
+	if err := db.gcIndex.Delete(item); err != nil {
+		t.Fatal(err)
+	}
+	// remove the access item of a chunk in the gc index
+	if err := db.retrievalAccessIndex.Delete(addressToItem(addrs[6])); err != nil {
+		t.Fatal(err)
+	}
+	// change the store timestamp of a push item
+	item, err = db.retrievalDataIndex.Get(addressToItem(addrs[7]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushItem, err := db.pushIndex.Get(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.pushIndex.Delete(pushItem); err != nil {
+		t.Fatal(err)
+	}
+	pushItem.StoreTimestamp++
+	if err := db.pushIndex.Put(pushItem); err != nil {
+		t.Fatal(err)
+	}
+	// remove the pull item of a chunk that is not push synced
+	item, err = db.retrievalDataIndex.Get(addressToItem(addrs[8]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.pullIndex.Delete(item); err != nil {
+		t.Fatal(err)
+	}
+	// reset the last bin id
+	bin := db.po(addrs[3])
+	if err := db.binIDs.Put(uint64(bin), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	checkReport := func(t *testing.T, r *VerifyReport) {
+		t.Helper()
+
+		if r.OK() {
+			t.Fatal("got consistent database report")
+		}
+		if r.Chunks != 9 {
+			t.Errorf("got %v chunks, want 9", r.Chunks)
+		}
+		if r.InvalidChunks != 1 {
+			t.Errorf("got %v invalid chunks, want 1", r.InvalidChunks)
+		}
+		for _, name := range []string{"retrievalAccessIndex", "pullIndex", "gcIndex"} {
+			if got := r.Indexes[name].Dangling; got != 1 {
+				t.Errorf("got %v dangling %s items, want 1", got, name)
+			}
+		}
+		for name, want := range map[string]IndexReport{
+			"retrievalAccessIndex": {Missing: 1},
+			"pullIndex":            {Invalid: 1, Missing: 1},
+			"pushIndex":            {Invalid: 1},
+			"gcIndex":              {Invalid: 1, Missing: 1},
+			"gcExcludeIndex":       {Missing: 1},
+		} {
+			got := r.Indexes[name]
+			if got.Invalid != want.Invalid || got.Missing != want.Missing {
+				t.Errorf("got %v invalid and %v missing %s items, want %v and %v", got.Invalid, got.Missing, name, want.Invalid, want.Missing)
+			}
+		}
+		if r.GCSize.Stored != 10 || r.GCSize.Computed != 8 {
+			t.Errorf("got gc size %+v, want stored 10 and computed 8", r.GCSize)
+		}
+		if v, ok := r.BinIDs[bin]; !ok || v.Stored != 0 || v.Computed == 0 {
+			t.Errorf("got bin %v id report %+v", bin, v)
+		}
+	}
+
+	t.Run("verify", func(t *testing.T) {
+		// verify twice to check that the database is not changed
+		for i := 0; i < 2; i++ {
+			r, err := db.Verify(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkReport(t, r)
+			if r.Repaired {
+				t.Error("got repaired report")
+			}
+		}
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		r, err := db.Repair(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkReport(t, r)
+		if !r.Repaired {
+			t.Error("got not repaired report")
+		}
+
+		r, err = db.Verify(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.OK() {
+			t.Fatalf("got report %+v after repair", r)
+		}
+		if r.Chunks != 8 {
+			t.Errorf("got %v chunks, want 8", r.Chunks)
+		}
+		if _, err := db.Get(ctx, storage.ModeGetLookup, invalid); err != storage.ErrNotFound {
+			t.Errorf("got error %v for invalid chunk, want %v", err, storage.ErrNotFound)
+		}
+		has, err := db.gcExcludeIndex.Has(addressToItem(addrs[2]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !has {
+			t.Error("pinned chunk not in gc exclude index")
+		}
+
+		// restored items must match the retrieval data
+		for _, addr := range addrs[4:9] {
+			info, err := db.DebugChunk(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !info.PullSyncable || info.PushSynced || !info.GCEligible {
+				t.Errorf("got chunk %s info %+v after repair", addr, info)
+			}
+		}
+	})
+}
@@ -5,10 +5,12 @@
 package logging
 
 import (
+	"bufio"
 	"net"
 	"net/http"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/sirupsen/logrus"
 )
 
@@ -37,6 +39,9 @@ func NewHTTPAccessLogHandler(logger Logger, level logrus.Level, message string)
 				"size":     rl.size,
 				"duration": time.Since(startTime).Seconds(),
 			}
+			if v := sctx.GetHTTPRequestID(r.Context()); v != "" {
+				fields["request_id"] = v
+			}
 			if v := r.Referer(); v != "" {
 				fields["referrer"] = v
 			}
@@ -68,6 +73,15 @@ func (l *responseLogger) Flush() {
 	l.w.(http.Flusher).Flush()
 }
 
+func (l *responseLogger) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := l.w.(http.Hijacker).Hijack()
+	if err == nil && l.status == 0 {
+		// the status is written by the handler directly to the connection
+		l.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
 func (l *responseLogger) Push(target string, opts *http.PushOptions) error {
 	return l.w.(http.Pusher).Push(target, opts)
 }
@@ -0,0 +1,44 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/sirupsen/logrus"
+)
+
+// NewHTTPRecoveryHandler recovers from panics in the handler, logs them
+// with the stack trace and responds with the internal server error.
+func NewHTTPRecoveryHandler(logger Logger) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					// the server aborts the response without logging
+					panic(v)
+				}
+				fields := logrus.Fields{
+					"method": r.Method,
+					"uri":    r.RequestURI,
+				}
+				if id := sctx.GetHTTPRequestID(r.Context()); id != "" {
+					fields["request_id"] = id
+				}
+				logger.WithFields(fields).Errorf("http handler panic: %v\n%s", v, debug.Stack())
+				jsonhttp.InternalServerError(w, nil)
+			}()
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
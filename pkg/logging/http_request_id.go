@@ -0,0 +1,59 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/sctx"
+)
+
+// RequestIDHeader is the HTTP header that holds the request identifier.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the maximal length of the
+// request identifier that is accepted from the client.
+const maxRequestIDLength = 128
+
+// NewHTTPRequestIDHandler sets the request identifier in the request
+// context and in the response header. The identifier from the request
+// header is used if it is valid, otherwise a new one is generated.
+func NewHTTPRequestIDHandler() func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			h.ServeHTTP(w, r.WithContext(sctx.SetHTTPRequestID(r.Context(), id)))
+		})
+	}
+}
+
+// validRequestID returns true if the identifier is not empty, not too
+// long and contains only printable ASCII characters without spaces.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// should never happen
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logging_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/sirupsen/logrus"
+	"resenje.org/web"
+)
+
+func TestHTTPRequestIDHandler(t *testing.T) {
+	var got string
+	h := logging.NewHTTPRequestIDHandler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = sctx.GetHTTPRequestID(r.Context())
+	}))
+
+	for _, tc := range []struct {
+		name      string
+		requestID string
+		accepted  bool
+	}{
+		{name: "generated"},
+		{name: "accepted", requestID: "client-request-1", accepted: true},
+		{name: "invalid", requestID: "client request"},
+		{name: "too long", requestID: strings.Repeat("a", 129)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.requestID != "" {
+				r.Header.Set(logging.RequestIDHeader, tc.requestID)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got == "" {
+				t.Fatal("request id not set in context")
+			}
+			if header := w.Header().Get(logging.RequestIDHeader); header != got {
+				t.Errorf("got response header %q, want %q", header, got)
+			}
+			if accepted := got == tc.requestID; accepted != tc.accepted {
+				t.Errorf("got request id %q, accepted %v, want %v", got, accepted, tc.accepted)
+			}
+		})
+	}
+}
+
+func TestHTTPRecoveryHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logrus.InfoLevel)
+
+	h := web.ChainHandlers(
+		logging.NewHTTPRequestIDHandler(),
+		logging.NewHTTPAccessLogHandler(logger, logrus.InfoLevel, "access"),
+		logging.NewHTTPRecoveryHandler(logger),
+		web.FinalHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("handler failure")
+		}),
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(logging.RequestIDHeader, "some-request")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status %v, want %v", w.Code, http.StatusInternalServerError)
+	}
+	if want := `{"message":"Internal Server Error","code":500}`; strings.TrimSpace(w.Body.String()) != want {
+		t.Errorf("got body %q, want %q", w.Body.String(), want)
+	}
+
+	log := buf.String()
+	for _, want := range []string{
+		"handler failure",
+		"http_test.go",
+		"request_id=some-request",
+		"status=500",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log %q does not contain %q", log, want)
+		}
+	}
+}
@@ -9,30 +9,44 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/gateway"
 	"github.com/ethersphere/bee/pkg/hive"
 	"github.com/ethersphere/bee/pkg/keystore"
 	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
 	memkeystore "github.com/ethersphere/bee/pkg/keystore/mem"
+	"github.com/ethersphere/bee/pkg/listener"
 	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/metrics"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	postagefile "github.com/ethersphere/bee/pkg/postage/listener/file"
+	"github.com/ethersphere/bee/pkg/resolver"
+	resolvercache "github.com/ethersphere/bee/pkg/resolver/cache"
+	"github.com/ethersphere/bee/pkg/resolver/ens"
+	"github.com/ethersphere/bee/pkg/resolver/static"
 	"github.com/ethersphere/bee/pkg/statestore/leveldb"
 	mockinmem "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/cache"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/topology/full"
 	"github.com/ethersphere/bee/pkg/tracing"
 	ma "github.com/multiformats/go-multiaddr"
@@ -43,26 +57,53 @@ type Bee struct {
 	p2pCancel        context.CancelFunc
 	apiServer        *http.Server
 	debugAPIServer   *http.Server
+	listeners        []*listener.Listener
 	errorLogWriter   *io.PipeWriter
 	tracerCloser     io.Closer
-	stateStoreCloser io.Closer
+	stateStore       storage.StateStorer
 	localstoreCloser io.Closer
+	tags             *tags.Tags
 }
 
 type Options struct {
-	DataDir            string
-	Password           string
-	APIAddr            string
-	DebugAPIAddr       string
-	Addr               string
-	DisableWS          bool
-	DisableQUIC        bool
-	NetworkID          int32
-	Bootnodes          []string
-	Logger             logging.Logger
-	TracingEnabled     bool
-	TracingEndpoint    string
-	TracingServiceName string
+	DataDir               string
+	Password              string
+	APIAddr               string
+	APITLSCertFile        string
+	APITLSKeyFile         string
+	APISocketMode         os.FileMode
+	APIAuth               bool
+	APIPublicScopes       []string
+	Gateway               bool
+	GatewayWriteAllowlist []string
+	GatewayRequestRate    float64
+	GatewayRequestBurst   int
+	GatewayByteRate       uint64
+	GatewayByteBurst      uint64
+	GatewayUploadQuota    uint64
+	ResolverStaticFile    string
+	ResolverENSEndpoint   string
+	ResolverENSRegistry   string
+	ResolverCacheTTL      time.Duration
+	DebugAPIAddr          string
+	DebugAPITLSCertFile   string
+	DebugAPITLSKeyFile    string
+	DebugAPISocketMode    os.FileMode
+	Addr                  string
+	DisableWS             bool
+	DisableQUIC           bool
+	NetworkID             int32
+	Bootnodes             []string
+	Logger                logging.Logger
+	TracingEnabled        bool
+	TracingEndpoint       string
+	TracingServiceName    string
+	PostageEventsFile     string
+	DBBackend             string
+	DBCapacity            uint64
+	DBReserveCapacity     uint64
+	DBFreeDiskWatermark   uint64
+	DBCacheCapacity       uint64
 }
 
 func NewBee(o Options) (*Bee, error) {
@@ -123,7 +164,7 @@ func NewBee(o Options) (*Bee, error) {
 			return nil, fmt.Errorf("statestore: %w", err)
 		}
 	}
-	b.stateStoreCloser = stateStore
+	b.stateStore = stateStore
 	addressbook := addressbook.New(stateStore)
 
 	p2ps, err := libp2p.New(p2pCtx, libp2p.Options{
@@ -163,9 +204,10 @@ func NewBee(o Options) (*Bee, error) {
 		return nil, fmt.Errorf("hive service: %w", err)
 	}
 
-	topologyDriver := full.New(hive, addressbook, p2ps, logger)
+	topologyDriver := full.New(address, hive, addressbook, p2ps, logger)
 	hive.SetPeerAddedHandler(topologyDriver.AddPeer)
 	p2ps.SetPeerAddedHandler(topologyDriver.AddPeer)
+	p2ps.SetPeerRemovedHandler(topologyDriver.RemovePeer)
 	addrs, err := p2ps.Addresses()
 	if err != nil {
 		return nil, fmt.Errorf("get server addresses: %w", err)
@@ -175,30 +217,140 @@ func NewBee(o Options) (*Bee, error) {
 		logger.Infof("p2p address: %s", addr)
 	}
 
-	var storer storage.Storer
-	if o.DataDir == "" {
-		// TODO: this needs to support in-mem localstore implementation somehow
-	} else {
-		storer, err = localstore.New(filepath.Join(o.DataDir, "localstore"), address.Bytes(), nil, logger)
-		if err != nil {
-			return nil, fmt.Errorf("localstore: %w", err)
+	// Postage stamps are issued and validated only if batch events
+	// are provided by an event source.
+	var (
+		stamper    postage.Stamper
+		validStamp func(swarm.Chunk) error
+	)
+	if o.PostageEventsFile != "" {
+		batchStore := batchstore.New(stateStore)
+		if err := postagefile.New(o.PostageEventsFile).Listen(p2pCtx, batchStore); err != nil {
+			return nil, fmt.Errorf("postage events: %w", err)
 		}
+		stamper = postage.NewStamper(batchStore, stateStore, crypto.NewDefaultSigner(swarmPrivateKey))
+		validStamp = postage.ValidStamp(batchStore)
+	}
+
+	var path string
+	if o.DataDir != "" {
+		path = filepath.Join(o.DataDir, "localstore")
+	}
+	// unfinished upload tags are restored from the previous run
+	tagg := tags.NewTags()
+	if err := tagg.Load(stateStore); err != nil {
+		return nil, fmt.Errorf("tags: %w", err)
+	}
+	b.tags = tagg
+
+	// localstore keeps its data only in memory if the path is empty
+	storer, err := localstore.New(path, address.Bytes(), &localstore.Options{
+		Tags:              tagg,
+		ValidStamp:        validStamp,
+		Backend:           o.DBBackend,
+		CapacityBytes:     o.DBCapacity,
+		ReserveCapacity:   o.DBReserveCapacity / swarm.ChunkSize,
+		FreeDiskWatermark: o.DBFreeDiskWatermark,
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: %w", err)
 	}
 	b.localstoreCloser = storer
+	// chunks within the neighbourhood depth are kept in the reserve
+	topologyDriver.SetNeighborhoodDepthHandler(storer.SetReserveRadius)
+
+	var chunkStorer storage.Storer = storer
+	var chunkCache *cache.Cache
+	if o.DBCacheCapacity > 0 {
+		// hot chunks are served from memory
+		chunkCache = cache.New(storer, &cache.Options{
+			CapacityBytes: o.DBCacheCapacity,
+		}, logger)
+		chunkStorer = chunkCache
+		b.localstoreCloser = chunkCache
+	}
 
-	var apiService api.Service
+	var (
+		apiService     api.Service
+		gatewayService *gateway.Gateway
+	)
 	if o.APIAddr != "" {
+		var authenticator *auth.Authenticator
+		if o.APIAuth {
+			publicScopes, err := auth.ParseScopes(o.APIPublicScopes)
+			if err != nil {
+				return nil, fmt.Errorf("api public scopes: %w", err)
+			}
+			// admin secret and token signing key are derived from the swarm key
+			authenticator, err = auth.New(swarmPrivateKey, &auth.Options{
+				PublicScopes: publicScopes,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("api auth: %w", err)
+			}
+		}
+
 		// API server
+		if o.Gateway {
+			writeAllowlist, err := gateway.ParseNetworks(o.GatewayWriteAllowlist)
+			if err != nil {
+				return nil, fmt.Errorf("gateway write allowlist: %w", err)
+			}
+			gatewayService = gateway.New(stateStore, gateway.Options{
+				WriteAllowlist:   writeAllowlist,
+				RequestRate:      o.GatewayRequestRate,
+				RequestBurst:     o.GatewayRequestBurst,
+				ByteRate:         float64(o.GatewayByteRate),
+				ByteBurst:        int64(o.GatewayByteBurst),
+				DailyUploadQuota: o.GatewayUploadQuota,
+			}, logger)
+		}
+
+		var resolvers resolver.Multi
+		if o.ResolverStaticFile != "" {
+			staticResolver, err := static.Load(o.ResolverStaticFile)
+			if err != nil {
+				return nil, fmt.Errorf("static resolver: %w", err)
+			}
+			resolvers = append(resolvers, staticResolver)
+		}
+		if o.ResolverENSEndpoint != "" {
+			ensResolver, err := ens.New(ens.Options{
+				Endpoint:        o.ResolverENSEndpoint,
+				RegistryAddress: o.ResolverENSRegistry,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("ens resolver: %w", err)
+			}
+			resolvers = append(resolvers, ensResolver)
+		}
+		var nameResolver resolver.Interface
+		if len(resolvers) > 0 {
+			nameResolver = resolvercache.New(resolvers, &resolvercache.Options{
+				TTL: o.ResolverCacheTTL,
+			})
+		}
+
 		apiService = api.New(api.Options{
 			Pingpong: pingPong,
-			Storer:   storer,
+			Storer:   chunkStorer,
+			Stamper:  stamper,
+			Tags:     tagg,
+			Auth:     authenticator,
+			Gateway:  gatewayService,
+			Resolver: nameResolver,
 			Logger:   logger,
 			Tracer:   tracer,
 		})
-		apiListener, err := net.Listen("tcp", o.APIAddr)
+		apiListener, err := listener.New(o.APIAddr, listener.Options{
+			TLSCertFile: o.APITLSCertFile,
+			TLSKeyFile:  o.APITLSKeyFile,
+			SocketMode:  o.APISocketMode,
+		})
 		if err != nil {
 			return nil, fmt.Errorf("api listener: %w", err)
 		}
+		b.listeners = append(b.listeners, apiListener)
 
 		apiServer := &http.Server{
 			Handler:  apiService,
@@ -206,7 +358,7 @@ func NewBee(o Options) (*Bee, error) {
 		}
 
 		go func() {
-			logger.Infof("api address: %s", apiListener.Addr())
+			logger.Infof("api address: %s, tls: %v", listenerAddr(apiListener), apiListener.TLS())
 
 			if err := apiServer.Serve(apiListener); err != nil && err != http.ErrServerClosed {
 				logger.Debugf("api server: %v", err)
@@ -225,21 +377,34 @@ func NewBee(o Options) (*Bee, error) {
 			Logger:         logger,
 			Addressbook:    addressbook,
 			TopologyDriver: topologyDriver,
+			Storer:         storer,
 		})
 		// register metrics from components
 		debugAPIService.MustRegisterMetrics(p2ps.Metrics()...)
 		debugAPIService.MustRegisterMetrics(pingPong.Metrics()...)
+		debugAPIService.MustRegisterMetrics(storer.Metrics()...)
+		if chunkCache != nil {
+			debugAPIService.MustRegisterMetrics(chunkCache.Metrics()...)
+		}
 		if apiService != nil {
 			debugAPIService.MustRegisterMetrics(apiService.Metrics()...)
 		}
+		if gatewayService != nil {
+			debugAPIService.MustRegisterMetrics(gatewayService.Metrics()...)
+		}
 		if l, ok := logger.(metrics.Collector); ok {
 			debugAPIService.MustRegisterMetrics(l.Metrics()...)
 		}
 
-		debugAPIListener, err := net.Listen("tcp", o.DebugAPIAddr)
+		debugAPIListener, err := listener.New(o.DebugAPIAddr, listener.Options{
+			TLSCertFile: o.DebugAPITLSCertFile,
+			TLSKeyFile:  o.DebugAPITLSKeyFile,
+			SocketMode:  o.DebugAPISocketMode,
+		})
 		if err != nil {
 			return nil, fmt.Errorf("debug api listener: %w", err)
 		}
+		b.listeners = append(b.listeners, debugAPIListener)
 
 		debugAPIServer := &http.Server{
 			Handler:  debugAPIService,
@@ -247,7 +412,7 @@ func NewBee(o Options) (*Bee, error) {
 		}
 
 		go func() {
-			logger.Infof("debug api address: %s", debugAPIListener.Addr())
+			logger.Infof("debug api address: %s, tls: %v", listenerAddr(debugAPIListener), debugAPIListener.TLS())
 
 			if err := debugAPIServer.Serve(debugAPIListener); err != nil && err != http.ErrServerClosed {
 				logger.Debugf("debug api server: %v", err)
@@ -300,6 +465,17 @@ func NewBee(o Options) (*Bee, error) {
 	return b, nil
 }
 
+// ReloadTLS loads TLS certificates and keys of the
+// HTTP API listeners from their files again.
+func (b *Bee) ReloadTLS() error {
+	for _, l := range b.listeners {
+		if err := l.Reload(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (b *Bee) Shutdown(ctx context.Context) error {
 	var eg errgroup.Group
 	if b.apiServer != nil {
@@ -331,7 +507,11 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("tracer: %w", err)
 	}
 
-	if err := b.stateStoreCloser.Close(); err != nil {
+	if err := b.tags.Save(b.stateStore); err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+
+	if err := b.stateStore.Close(); err != nil {
 		return fmt.Errorf("statestore: %w", err)
 	}
 
@@ -341,3 +521,12 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 
 	return b.errorLogWriter.Close()
 }
+
+// listenerAddr returns the address of the listener
+// in the format of the listen address options.
+func listenerAddr(l *listener.Listener) string {
+	if l.Addr().Network() == "unix" {
+		return listener.UnixPrefix + l.Addr().String()
+	}
+	return l.Addr().String()
+}
@@ -373,6 +373,12 @@ func (s *Service) SetPeerAddedHandler(h func(context.Context, swarm.Address) err
 	s.peerHandler = h
 }
 
+// SetPeerRemovedHandler sets the function that is called with the
+// overlay address of every peer that is disconnected.
+func (s *Service) SetPeerRemovedHandler(h func(overlay swarm.Address)) {
+	s.peers.setRemovedHandler(h)
+}
+
 func (s *Service) NewStream(ctx context.Context, overlay swarm.Address, headers p2p.Headers, protocolName, protocolVersion, streamName string) (p2p.Stream, error) {
 	peerID, found := s.peers.peerID(overlay)
 	if !found {
@@ -19,6 +19,7 @@ type peerRegistry struct {
 	underlays   map[string]libp2ppeer.ID                    // map overlay address to underlay peer id
 	overlays    map[libp2ppeer.ID]swarm.Address             // map underlay peer id to overlay address
 	connections map[libp2ppeer.ID]map[network.Conn]struct{} // list of connections for safe removal on Disconnect notification
+	removed     func(overlay swarm.Address)                 // called with addresses of removed peers
 	mu          sync.RWMutex
 
 	network.Notifiee // peerRegistry can be the receiver for network.Notify
@@ -44,15 +45,15 @@ func (r *peerRegistry) Disconnected(_ network.Network, c network.Conn) {
 	peerID := c.RemotePeer()
 
 	r.mu.Lock()
-	defer r.mu.Unlock()
 
 	// remove only the related connection,
 	// not eventually newly created one for the same peer
 	if _, ok := r.connections[peerID][c]; !ok {
+		r.mu.Unlock()
 		return
 	}
 
-	overlay := r.overlays[peerID]
+	overlay, found := r.overlays[peerID]
 	delete(r.overlays, peerID)
 	delete(r.underlays, overlay.ByteString())
 
@@ -60,6 +61,12 @@ func (r *peerRegistry) Disconnected(_ network.Network, c network.Conn) {
 	if len(r.connections[peerID]) == 0 {
 		delete(r.connections, peerID)
 	}
+	removed := r.removed
+	r.mu.Unlock()
+
+	if found && removed != nil {
+		removed(overlay)
+	}
 }
 
 func (r *peerRegistry) peers() []p2p.Peer {
@@ -112,9 +119,20 @@ func (r *peerRegistry) overlay(peerID libp2ppeer.ID) (swarm.Address, bool) {
 
 func (r *peerRegistry) remove(peerID libp2ppeer.ID) {
 	r.mu.Lock()
-	overlay := r.overlays[peerID]
+	overlay, found := r.overlays[peerID]
 	delete(r.overlays, peerID)
 	delete(r.underlays, overlay.ByteString())
 	delete(r.connections, peerID)
+	removed := r.removed
+	r.mu.Unlock()
+
+	if found && removed != nil {
+		removed(overlay)
+	}
+}
+
+func (r *peerRegistry) setRemovedHandler(h func(overlay swarm.Address)) {
+	r.mu.Lock()
+	r.removed = h
 	r.mu.Unlock()
 }
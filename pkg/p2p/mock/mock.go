@@ -14,12 +14,13 @@ import (
 )
 
 type Service struct {
-	addProtocolFunc         func(p2p.ProtocolSpec) error
-	connectFunc             func(ctx context.Context, addr ma.Multiaddr) (overlay swarm.Address, err error)
-	disconnectFunc          func(overlay swarm.Address) error
-	peersFunc               func() []p2p.Peer
-	setPeerAddedHandlerFunc func(func(context.Context, swarm.Address) error)
-	addressesFunc           func() ([]ma.Multiaddr, error)
+	addProtocolFunc           func(p2p.ProtocolSpec) error
+	connectFunc               func(ctx context.Context, addr ma.Multiaddr) (overlay swarm.Address, err error)
+	disconnectFunc            func(overlay swarm.Address) error
+	peersFunc                 func() []p2p.Peer
+	setPeerAddedHandlerFunc   func(func(context.Context, swarm.Address) error)
+	setPeerRemovedHandlerFunc func(func(swarm.Address))
+	addressesFunc             func() ([]ma.Multiaddr, error)
 }
 
 func WithAddProtocolFunc(f func(p2p.ProtocolSpec) error) Option {
@@ -52,6 +53,12 @@ func WithSetPeerAddedHandlerFunc(f func(func(context.Context, swarm.Address) err
 	})
 }
 
+func WithSetPeerRemovedHandlerFunc(f func(func(swarm.Address))) Option {
+	return optionFunc(func(s *Service) {
+		s.setPeerRemovedHandlerFunc = f
+	})
+}
+
 func WithAddressesFunc(f func() ([]ma.Multiaddr, error)) Option {
 	return optionFunc(func(s *Service) {
 		s.addressesFunc = f
@@ -95,6 +102,14 @@ func (s *Service) SetPeerAddedHandler(f func(context.Context, swarm.Address) err
 	s.setPeerAddedHandlerFunc(f)
 }
 
+func (s *Service) SetPeerRemovedHandler(f func(swarm.Address)) {
+	if s.setPeerRemovedHandlerFunc == nil {
+		return
+	}
+
+	s.setPeerRemovedHandlerFunc(f)
+}
+
 func (s *Service) Addresses() ([]ma.Multiaddr, error) {
 	if s.addressesFunc == nil {
 		return nil, errors.New("function Addresses not configured")
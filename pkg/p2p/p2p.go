@@ -19,6 +19,7 @@ type Service interface {
 	Disconnect(overlay swarm.Address) error
 	Peers() []Peer
 	SetPeerAddedHandler(func(context.Context, swarm.Address) error)
+	SetPeerRemovedHandler(func(overlay swarm.Address))
 	Addresses() ([]ma.Multiaddr, error)
 }
 
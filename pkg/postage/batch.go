@@ -0,0 +1,72 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"errors"
+	"math/big"
+)
+
+const (
+	// BatchIDSize is the size of a postage batch ID in bytes.
+	BatchIDSize = 32
+	// OwnerSize is the size of a postage batch owner Ethereum
+	// address in bytes.
+	OwnerSize = 20
+
+	valueSize = 32
+	batchSize = BatchIDSize + valueSize + 1 + OwnerSize
+)
+
+// Batch represents a postage batch, a payment for storing a number of
+// chunks that is defined by the batch depth.
+type Batch struct {
+	ID    []byte   // batch ID
+	Value *big.Int // paid value of the batch
+	Depth uint8    // batch depth, 2^Depth chunks can be stamped
+	Owner []byte   // owner Ethereum address
+}
+
+// Capacity returns the number of chunks that can be
+// stamped with the batch.
+func (b *Batch) Capacity() uint64 {
+	return 1 << b.Depth
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (b *Batch) MarshalBinary() ([]byte, error) {
+	if len(b.ID) != BatchIDSize {
+		return nil, errors.New("invalid batch id")
+	}
+	if len(b.Owner) != OwnerSize {
+		return nil, errors.New("invalid batch owner")
+	}
+	value := b.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	if value.Sign() < 0 || len(value.Bytes()) > valueSize {
+		return nil, errors.New("invalid batch value")
+	}
+	out := make([]byte, batchSize)
+	copy(out, b.ID)
+	v := value.Bytes()
+	copy(out[BatchIDSize+valueSize-len(v):BatchIDSize+valueSize], v)
+	out[BatchIDSize+valueSize] = b.Depth
+	copy(out[BatchIDSize+valueSize+1:], b.Owner)
+	return out, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (b *Batch) UnmarshalBinary(buf []byte) error {
+	if len(buf) != batchSize {
+		return errors.New("invalid batch data size")
+	}
+	b.ID = append([]byte(nil), buf[:BatchIDSize]...)
+	b.Value = new(big.Int).SetBytes(buf[BatchIDSize : BatchIDSize+valueSize])
+	b.Depth = buf[BatchIDSize+valueSize]
+	b.Owner = append([]byte(nil), buf[BatchIDSize+valueSize+1:]...)
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package batchstore provides a postage batch store that persists
+// batches in the state store.
+package batchstore
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const keyPrefix = "batchstore_"
+
+var _ Interface = (*store)(nil)
+
+// Interface is a postage batch store that applies batch events.
+type Interface interface {
+	postage.Storer
+	postage.EventUpdater
+}
+
+type store struct {
+	store storage.StateStorer
+	mu    sync.Mutex // serializes batch updates
+}
+
+// New constructs a batch store that is also an EventUpdater for
+// batch events.
+func New(st storage.StateStorer) Interface {
+	return &store{
+		store: st,
+	}
+}
+
+// Get returns a batch with the provided ID.
+func (s *store) Get(id []byte) (*postage.Batch, error) {
+	b := new(postage.Batch)
+	if err := s.store.Get(batchKey(id), b); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, postage.ErrNotFound
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// Put stores a batch.
+func (s *store) Put(b *postage.Batch) error {
+	return s.store.Put(batchKey(b.ID), b)
+}
+
+// Create stores a new batch.
+func (s *store) Create(id []byte, owner []byte, value *big.Int, depth uint8) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Put(&postage.Batch{
+		ID:    id,
+		Owner: owner,
+		Value: value,
+		Depth: depth,
+	})
+}
+
+// TopUp adds value to an existing batch.
+func (s *store) TopUp(id []byte, value *big.Int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.Get(id)
+	if err != nil {
+		return fmt.Errorf("top up: %w", err)
+	}
+	b.Value = new(big.Int).Add(b.Value, value)
+	return s.Put(b)
+}
+
+// UpdateDepth changes the depth of an existing batch.
+func (s *store) UpdateDepth(id []byte, depth uint8) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.Get(id)
+	if err != nil {
+		return fmt.Errorf("update depth: %w", err)
+	}
+	b.Depth = depth
+	return s.Put(b)
+}
+
+func batchKey(id []byte) string {
+	return keyPrefix + hex.EncodeToString(id)
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batchstore_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	"github.com/ethersphere/bee/pkg/postage/listener/file"
+	"github.com/ethersphere/bee/pkg/postage/listener/mem"
+	mockstate "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+var (
+	batchID = bytes.Repeat([]byte{0x01}, postage.BatchIDSize)
+	owner   = bytes.Repeat([]byte{0x02}, postage.OwnerSize)
+	events  = []postage.Event{
+		{Type: postage.EventCreate, BatchID: hex.EncodeToString(batchID), Owner: hex.EncodeToString(owner), Value: big.NewInt(10), Depth: 8},
+		{Type: postage.EventTopUp, BatchID: hex.EncodeToString(batchID), Value: big.NewInt(5)},
+		{Type: postage.EventUpdateDepth, BatchID: hex.EncodeToString(batchID), Depth: 10},
+	}
+)
+
+func TestStore_memListener(t *testing.T) {
+	store := batchstore.New(mockstate.NewStateStore())
+
+	if err := mem.New(events...).Listen(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+
+	checkBatch(t, store)
+}
+
+func TestStore_fileListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bee-batchstore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	for _, e := range events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	path := filepath.Join(dir, "events.json")
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := batchstore.New(mockstate.NewStateStore())
+
+	if err := file.New(path).Listen(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+
+	checkBatch(t, store)
+}
+
+func TestStore_notFound(t *testing.T) {
+	store := batchstore.New(mockstate.NewStateStore())
+
+	if _, err := store.Get(batchID); !errors.Is(err, postage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, postage.ErrNotFound)
+	}
+	if err := store.TopUp(batchID, big.NewInt(1)); !errors.Is(err, postage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, postage.ErrNotFound)
+	}
+}
+
+func checkBatch(t *testing.T, store postage.Storer) {
+	t.Helper()
+
+	b, err := store.Get(batchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Owner, owner) {
+		t.Errorf("got owner %x, want %x", b.Owner, owner)
+	}
+	if b.Value.Cmp(big.NewInt(15)) != 0 {
+		t.Errorf("got value %v, want %v", b.Value, 15)
+	}
+	if b.Depth != 10 {
+		t.Errorf("got depth %v, want %v", b.Depth, 10)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// EventType enumerates postage batch event types.
+type EventType string
+
+// Batch event types.
+const (
+	EventCreate      EventType = "create"
+	EventTopUp       EventType = "topup"
+	EventUpdateDepth EventType = "depth"
+)
+
+// Event is a single change of the batch state as provided by a Listener.
+// Batch ID and owner are hex encoded.
+type Event struct {
+	Type    EventType `json:"type"`
+	BatchID string    `json:"batchID"`
+	Owner   string    `json:"owner,omitempty"`
+	Value   *big.Int  `json:"value,omitempty"`
+	Depth   uint8     `json:"depth,omitempty"`
+}
+
+// Apply applies the event to the EventUpdater.
+func (e Event) Apply(u EventUpdater) error {
+	id, err := hex.DecodeString(e.BatchID)
+	if err != nil || len(id) != BatchIDSize {
+		return fmt.Errorf("invalid batch id %q", e.BatchID)
+	}
+	value := e.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	switch e.Type {
+	case EventCreate:
+		owner, err := hex.DecodeString(e.Owner)
+		if err != nil || len(owner) != OwnerSize {
+			return fmt.Errorf("invalid batch owner %q", e.Owner)
+		}
+		return u.Create(id, owner, value, e.Depth)
+	case EventTopUp:
+		return u.TopUp(id, value)
+	case EventUpdateDepth:
+		return u.UpdateDepth(id, e.Depth)
+	default:
+		return fmt.Errorf("unknown batch event type %q", e.Type)
+	}
+}
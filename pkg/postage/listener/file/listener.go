@@ -0,0 +1,59 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package file provides a postage batch event source that reads
+// JSON-encoded events from a file.
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethersphere/bee/pkg/postage"
+)
+
+var _ postage.Listener = (*Listener)(nil)
+
+// Listener reads a stream of JSON-encoded postage.Event values from a file.
+type Listener struct {
+	path string
+}
+
+// New constructs a new Listener for the file on the provided path.
+func New(path string) *Listener {
+	return &Listener{
+		path: path,
+	}
+}
+
+// Listen applies all events from the file to the updater.
+func (l *Listener) Listen(ctx context.Context, updater postage.EventUpdater) (err error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		var e postage.Event
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("decode event %d: %w", i, err)
+		}
+		if err := e.Apply(updater); err != nil {
+			return fmt.Errorf("event %d: %w", i, err)
+		}
+	}
+}
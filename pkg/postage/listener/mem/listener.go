@@ -0,0 +1,58 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mem provides an in-memory postage batch event source.
+package mem
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/postage"
+)
+
+var _ postage.Listener = (*Listener)(nil)
+
+// Listener holds postage batch events in memory.
+type Listener struct {
+	events []postage.Event
+	mu     sync.Mutex
+}
+
+// New constructs a new Listener with initial events.
+func New(events ...postage.Event) *Listener {
+	return &Listener{
+		events: events,
+	}
+}
+
+// Add appends events that will be applied on the next Listen call.
+func (l *Listener) Add(events ...postage.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, events...)
+}
+
+// Listen applies all pending events to the updater.
+func (l *Listener) Listen(ctx context.Context, updater postage.EventUpdater) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, e := range l.events {
+		select {
+		case <-ctx.Done():
+			l.events = l.events[i:]
+			return ctx.Err()
+		default:
+		}
+		if err := e.Apply(updater); err != nil {
+			l.events = l.events[i+1:]
+			return fmt.Errorf("event %d: %w", i, err)
+		}
+	}
+	l.events = nil
+	return nil
+}
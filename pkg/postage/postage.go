@@ -0,0 +1,52 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package postage implements postage stamps that protect the network from
+// spam. Uploaders buy postage batches with a certain depth and value and
+// every uploaded chunk is stamped with a signature of the batch owner over
+// the chunk address, the batch ID and the index of the stamp within the
+// batch. Nodes that receive chunks validate the stamps against the batch
+// state before storing them.
+package postage
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrNotFound is returned when a batch is not known.
+	ErrNotFound = errors.New("postage: batch not found")
+	// ErrOwnerMismatch is returned when a stamp is not signed by the
+	// owner of the batch.
+	ErrOwnerMismatch = errors.New("postage: owner mismatch")
+	// ErrBatchFull is returned when all stamps of a batch are issued.
+	ErrBatchFull = errors.New("postage: batch is full")
+	// ErrInvalidIndex is returned when a stamp index is out of the
+	// batch capacity.
+	ErrInvalidIndex = errors.New("postage: invalid index")
+	// ErrNoStamp is returned when a chunk has no postage stamp.
+	ErrNoStamp = errors.New("postage: chunk has no stamp")
+)
+
+// Storer is a persistent store for postage batches.
+type Storer interface {
+	Get(id []byte) (*Batch, error)
+	Put(b *Batch) error
+}
+
+// EventUpdater applies batch events provided by an event source
+// to the batch state.
+type EventUpdater interface {
+	Create(id []byte, owner []byte, value *big.Int, depth uint8) error
+	TopUp(id []byte, value *big.Int) error
+	UpdateDepth(id []byte, depth uint8) error
+}
+
+// Listener provides batch events from an event source, such as a
+// blockchain contract, and applies them to the EventUpdater.
+type Listener interface {
+	Listen(ctx context.Context, updater EventUpdater) error
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	indexSize = 8
+	sigSize   = 65
+	// StampSize is the size of a marshaled postage stamp in bytes.
+	StampSize = BatchIDSize + indexSize + sigSize
+)
+
+var _ swarm.Stamp = (*Stamp)(nil)
+
+// Stamp represents a postage stamp as attached to a chunk.
+type Stamp struct {
+	batchID []byte // postage batch ID
+	index   uint64 // index of the stamp within the batch
+	sig     []byte // signature of the batch owner
+}
+
+// NewStamp constructs a new stamp from a given batch ID, index and signature.
+func NewStamp(batchID []byte, index uint64, sig []byte) *Stamp {
+	return &Stamp{
+		batchID: batchID,
+		index:   index,
+		sig:     sig,
+	}
+}
+
+// BatchID returns the batch ID of the stamp.
+func (s *Stamp) BatchID() []byte {
+	return s.batchID
+}
+
+// Index returns the index of the stamp within the batch.
+func (s *Stamp) Index() uint64 {
+	return s.index
+}
+
+// Sig returns the signature of the batch owner.
+func (s *Stamp) Sig() []byte {
+	return s.sig
+}
+
+// MarshalBinary gives the byte slice serialisation of a stamp:
+// batchID[32]|index[8]|signature[65].
+func (s *Stamp) MarshalBinary() ([]byte, error) {
+	if len(s.batchID) != BatchIDSize {
+		return nil, errors.New("invalid stamp batch id")
+	}
+	if len(s.sig) != sigSize {
+		return nil, errors.New("invalid stamp signature")
+	}
+	buf := make([]byte, StampSize)
+	copy(buf, s.batchID)
+	binary.BigEndian.PutUint64(buf[BatchIDSize:BatchIDSize+indexSize], s.index)
+	copy(buf[BatchIDSize+indexSize:], s.sig)
+	return buf, nil
+}
+
+// UnmarshalBinary parses a serialised stamp into a Stamp.
+func (s *Stamp) UnmarshalBinary(buf []byte) error {
+	if len(buf) != StampSize {
+		return errors.New("invalid stamp size")
+	}
+	s.batchID = append([]byte(nil), buf[:BatchIDSize]...)
+	s.index = binary.BigEndian.Uint64(buf[BatchIDSize : BatchIDSize+indexSize])
+	s.sig = append([]byte(nil), buf[BatchIDSize+indexSize:]...)
+	return nil
+}
+
+// Valid checks the validity of the stamp for the chunk address against
+// the batch that it references.
+func (s *Stamp) Valid(addr swarm.Address, b *Batch) error {
+	if !bytes.Equal(s.batchID, b.ID) {
+		return ErrNotFound
+	}
+	if s.index >= b.Capacity() {
+		return ErrInvalidIndex
+	}
+	pub, err := crypto.Recover(s.sig, toSignDigest(addr, s.batchID, s.index))
+	if err != nil {
+		return err
+	}
+	owner, err := crypto.NewEthereumAddress(*pub)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(owner, b.Owner) {
+		return ErrOwnerMismatch
+	}
+	return nil
+}
+
+// ValidStamp returns a function that validates the postage stamp of
+// a chunk against the batches in the provided store.
+func ValidStamp(store Storer) func(ch swarm.Chunk) error {
+	return func(ch swarm.Chunk) error {
+		stamp := ch.Stamp()
+		if stamp == nil {
+			return ErrNoStamp
+		}
+		s, ok := stamp.(*Stamp)
+		if !ok {
+			b, err := stamp.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			s = new(Stamp)
+			if err := s.UnmarshalBinary(b); err != nil {
+				return err
+			}
+		}
+		b, err := store.Get(s.BatchID())
+		if err != nil {
+			return fmt.Errorf("get batch %x: %w", s.BatchID(), err)
+		}
+		return s.Valid(ch.Address(), b)
+	}
+}
+
+// toSignDigest creates the data that is signed by the batch owner
+// to produce the stamp signature.
+func toSignDigest(addr swarm.Address, batchID []byte, index uint64) []byte {
+	buf := make([]byte, 0, len(addr.Bytes())+len(batchID)+indexSize)
+	buf = append(buf, addr.Bytes()...)
+	buf = append(buf, batchID...)
+	i := make([]byte, indexSize)
+	binary.BigEndian.PutUint64(i, index)
+	return append(buf, i...)
+}
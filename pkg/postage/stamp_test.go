@@ -0,0 +1,239 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	mockstate "github.com/ethersphere/bee/pkg/statestore/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+)
+
+// TestStamper issues stamps from a batch and validates them
+// against the batch store.
+func TestStamper(t *testing.T) {
+	signer := newTestSigner(t)
+	store := batchstore.New(mockstate.NewStateStore())
+	batch := newTestBatch(t, store, signer, 2)
+
+	stamper := postage.NewStamper(store, mockstate.NewStateStore(), signer)
+	validStamp := postage.ValidStamp(store)
+
+	for i := uint64(0); i < batch.Capacity(); i++ {
+		ch := chunktesting.GenerateTestRandomChunk()
+		stamp, err := stamper.Stamp(batch.ID, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stamp.Index() != i {
+			t.Fatalf("got stamp index %v, want %v", stamp.Index(), i)
+		}
+		if err := validStamp(ch.WithStamp(stamp)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("batch full", func(t *testing.T) {
+		ch := chunktesting.GenerateTestRandomChunk()
+		_, err := stamper.Stamp(batch.ID, ch.Address())
+		if !errors.Is(err, postage.ErrBatchFull) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrBatchFull)
+		}
+	})
+
+	t.Run("unknown batch", func(t *testing.T) {
+		ch := chunktesting.GenerateTestRandomChunk()
+		_, err := stamper.Stamp(make([]byte, postage.BatchIDSize), ch.Address())
+		if !errors.Is(err, postage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrNotFound)
+		}
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		other := newTestBatch(t, store, newTestSigner(t), 2)
+		ch := chunktesting.GenerateTestRandomChunk()
+		_, err := stamper.Stamp(other.ID, ch.Address())
+		if !errors.Is(err, postage.ErrOwnerMismatch) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrOwnerMismatch)
+		}
+	})
+}
+
+// TestStamperRelease checks that only the index of the last
+// issued stamp is returned to the batch.
+func TestStamperRelease(t *testing.T) {
+	signer := newTestSigner(t)
+	store := batchstore.New(mockstate.NewStateStore())
+	batch := newTestBatch(t, store, signer, 4)
+	stamper := postage.NewStamper(store, mockstate.NewStateStore(), signer)
+
+	stamp := func(want uint64) *postage.Stamp {
+		t.Helper()
+		s, err := stamper.Stamp(batch.ID, chunktesting.GenerateTestRandomChunk().Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Index() != want {
+			t.Fatalf("got stamp index %v, want %v", s.Index(), want)
+		}
+		return s
+	}
+	release := func(s *postage.Stamp) {
+		t.Helper()
+		if err := stamper.Release(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first := stamp(0)
+	release(first)
+	first = stamp(0)
+	second := stamp(1)
+
+	// the first index stays used as it is not the last issued one
+	release(first)
+	stamp(2)
+	release(second)
+	stamp(3)
+}
+
+// TestValidStamp checks that invalid stamps are rejected.
+func TestValidStamp(t *testing.T) {
+	signer := newTestSigner(t)
+	store := batchstore.New(mockstate.NewStateStore())
+	batch := newTestBatch(t, store, signer, 4)
+	stamper := postage.NewStamper(store, mockstate.NewStateStore(), signer)
+	validStamp := postage.ValidStamp(store)
+
+	ch := chunktesting.GenerateTestRandomChunk()
+	stamp, err := stamper.Stamp(batch.ID, ch.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no stamp", func(t *testing.T) {
+		err := validStamp(chunktesting.GenerateTestRandomChunk())
+		if !errors.Is(err, postage.ErrNoStamp) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrNoStamp)
+		}
+	})
+
+	t.Run("other chunk", func(t *testing.T) {
+		err := validStamp(chunktesting.GenerateTestRandomChunk().WithStamp(stamp))
+		if !errors.Is(err, postage.ErrOwnerMismatch) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrOwnerMismatch)
+		}
+	})
+
+	t.Run("index out of range", func(t *testing.T) {
+		s := postage.NewStamp(stamp.BatchID(), batch.Capacity(), stamp.Sig())
+		err := validStamp(ch.WithStamp(s))
+		if !errors.Is(err, postage.ErrInvalidIndex) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrInvalidIndex)
+		}
+	})
+
+	t.Run("unknown batch", func(t *testing.T) {
+		s := postage.NewStamp(make([]byte, postage.BatchIDSize), stamp.Index(), stamp.Sig())
+		err := validStamp(ch.WithStamp(s))
+		if !errors.Is(err, postage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrNotFound)
+		}
+	})
+}
+
+func TestStampMarshaling(t *testing.T) {
+	sig := make([]byte, 65)
+	if _, err := rand.Read(sig); err != nil {
+		t.Fatal(err)
+	}
+	want := postage.NewStamp(newTestBatchID(t), 42, sig)
+	buf, err := want.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf) != postage.StampSize {
+		t.Fatalf("got stamp size %v, want %v", len(buf), postage.StampSize)
+	}
+	got := new(postage.Stamp)
+	if err := got.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.BatchID(), want.BatchID()) {
+		t.Fatalf("got batch id %x, want %x", got.BatchID(), want.BatchID())
+	}
+	if got.Index() != want.Index() {
+		t.Fatalf("got index %v, want %v", got.Index(), want.Index())
+	}
+	if !bytes.Equal(got.Sig(), want.Sig()) {
+		t.Fatalf("got signature %x, want %x", got.Sig(), want.Sig())
+	}
+}
+
+func TestBatchMarshaling(t *testing.T) {
+	want := &postage.Batch{
+		ID:    newTestBatchID(t),
+		Value: big.NewInt(1000000),
+		Depth: 16,
+		Owner: bytes.Repeat([]byte{0xaa}, postage.OwnerSize),
+	}
+	buf, err := want.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := new(postage.Batch)
+	if err := got.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.ID, want.ID) || !bytes.Equal(got.Owner, want.Owner) || got.Depth != want.Depth || got.Value.Cmp(want.Value) != 0 {
+		t.Fatalf("got batch %+v, want %+v", got, want)
+	}
+}
+
+func newTestSigner(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	k, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.NewDefaultSigner(k)
+}
+
+func newTestBatchID(t *testing.T) []byte {
+	t.Helper()
+
+	id := make([]byte, postage.BatchIDSize)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func newTestBatch(t *testing.T, store postage.EventUpdater, signer crypto.Signer, depth uint8) *postage.Batch {
+	t.Helper()
+
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &postage.Batch{
+		ID:    newTestBatchID(t),
+		Value: big.NewInt(100),
+		Depth: depth,
+		Owner: owner,
+	}
+	if err := store.Create(b.ID, b.Owner, b.Value, b.Depth); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const stamperKeyPrefix = "postage_stamper_"
+
+// Stamper issues postage stamps for chunks from batches owned by the node.
+type Stamper interface {
+	Stamp(batchID []byte, addr swarm.Address) (*Stamp, error)
+	Release(stamp *Stamp) error
+}
+
+type stamper struct {
+	store  Storer
+	state  storage.StateStorer
+	signer crypto.Signer
+	mu     sync.Mutex // protects issued stamp counters
+}
+
+// NewStamper constructs a Stamper that signs stamps with the provided signer
+// and keeps the number of issued stamps per batch in the state store.
+func NewStamper(store Storer, state storage.StateStorer, signer crypto.Signer) Stamper {
+	return &stamper{
+		store:  store,
+		state:  state,
+		signer: signer,
+	}
+}
+
+// Stamp creates a postage stamp for the chunk address from the next unused
+// index of the batch.
+func (s *stamper) Stamp(batchID []byte, addr swarm.Address) (*Stamp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.store.Get(batchID)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.signer.EthereumAddress()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(owner, b.Owner) {
+		return nil, ErrOwnerMismatch
+	}
+
+	key := stamperKeyPrefix + hex.EncodeToString(batchID)
+	var index uint64
+	if err := s.state.Get(key, &index); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("get issued stamps: %w", err)
+	}
+	if index >= b.Capacity() {
+		return nil, ErrBatchFull
+	}
+
+	sig, err := s.signer.Sign(toSignDigest(addr, batchID, index))
+	if err != nil {
+		return nil, fmt.Errorf("sign stamp: %w", err)
+	}
+	if err := s.state.Put(key, index+1); err != nil {
+		return nil, fmt.Errorf("put issued stamps: %w", err)
+	}
+	return NewStamp(batchID, index, sig), nil
+}
+
+// Release returns the index of the stamp to the batch, so that it is
+// issued again, if the stamped chunk could not be stored. The index is
+// returned only if it is the last one issued from the batch, indexes of
+// stamps that were issued before later ones stay used.
+func (s *stamper) Release(stamp *Stamp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := stamperKeyPrefix + hex.EncodeToString(stamp.BatchID())
+	var index uint64
+	if err := s.state.Get(key, &index); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("get issued stamps: %w", err)
+	}
+	if index != stamp.Index()+1 {
+		return nil
+	}
+	if err := s.state.Put(key, stamp.Index()); err != nil {
+		return fmt.Errorf("put issued stamps: %w", err)
+	}
+	return nil
+}
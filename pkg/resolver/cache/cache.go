@@ -0,0 +1,123 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cache provides a resolver that keeps results of another
+// resolver in memory for a limited time.
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ resolver.Interface = (*Cache)(nil)
+
+const (
+	// defaultTTL is the time for which results are
+	// cached when it is not set in Options.
+	defaultTTL = 5 * time.Minute
+	// defaultCapacity is the number of cached names
+	// when it is not set in Options.
+	defaultCapacity = 10000
+)
+
+// Options are cache parameters.
+type Options struct {
+	// TTL is the time for which resolved
+	// and not found names are cached.
+	TTL time.Duration
+	// Capacity is the maximal number of cached names.
+	Capacity int
+}
+
+// Cache wraps a resolver and keeps its results in memory. Names that
+// are not found are cached as well, as names may come from clients,
+// but other errors are not.
+type Cache struct {
+	resolver resolver.Interface
+	ttl      time.Duration
+	capacity int
+
+	mu    sync.Mutex
+	items map[string]item
+
+	now func() time.Time // used in tests
+}
+
+type item struct {
+	addr    swarm.Address // zero address if the name is not found
+	expires time.Time
+}
+
+// New constructs a new Cache in front of the resolver.
+func New(r resolver.Interface, o *Options) *Cache {
+	if o == nil {
+		o = new(Options)
+	}
+	c := &Cache{
+		resolver: r,
+		ttl:      o.TTL,
+		capacity: o.Capacity,
+		items:    make(map[string]item),
+		now:      time.Now,
+	}
+	if c.ttl <= 0 {
+		c.ttl = defaultTTL
+	}
+	if c.capacity <= 0 {
+		c.capacity = defaultCapacity
+	}
+	return c
+}
+
+// Resolve returns the cached result for the name if it is not expired,
+// or resolves it with the underlying resolver.
+func (c *Cache) Resolve(ctx context.Context, name string) (swarm.Address, error) {
+	c.mu.Lock()
+	i, ok := c.items[name]
+	c.mu.Unlock()
+	if ok && c.now().Before(i.expires) {
+		if i.addr.IsZero() {
+			return swarm.ZeroAddress, resolver.ErrNotFound
+		}
+		return i.addr, nil
+	}
+
+	addr, err := c.resolver.Resolve(ctx, name)
+	if err != nil && !errors.Is(err, resolver.ErrNotFound) {
+		return swarm.ZeroAddress, err
+	}
+
+	c.mu.Lock()
+	c.add(name, item{addr: addr, expires: c.now().Add(c.ttl)})
+	c.mu.Unlock()
+
+	return addr, err
+}
+
+// add caches the item, removing expired items if the cache is full, and
+// an arbitrary one if none of them is expired. It must be called with
+// the lock held.
+func (c *Cache) add(name string, i item) {
+	if _, ok := c.items[name]; !ok && len(c.items) >= c.capacity {
+		now := c.now()
+		for n, i := range c.items {
+			if !now.Before(i.expires) {
+				delete(c.items, n)
+			}
+		}
+		for n := range c.items {
+			if len(c.items) < c.capacity {
+				break
+			}
+			delete(c.items, n)
+		}
+	}
+	c.items[name] = i
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/resolver/cache"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// countingResolver resolves names from a map
+// and counts resolutions of every name.
+type countingResolver struct {
+	names map[string]swarm.Address
+	err   error
+	calls map[string]int
+}
+
+func (r *countingResolver) Resolve(_ context.Context, name string) (swarm.Address, error) {
+	r.calls[name]++
+	if r.err != nil {
+		return swarm.ZeroAddress, r.err
+	}
+	addr, ok := r.names[name]
+	if !ok {
+		return swarm.ZeroAddress, resolver.ErrNotFound
+	}
+	return addr, nil
+}
+
+func TestCache(t *testing.T) {
+	addr := swarm.MustParseHexAddress("aabbcc")
+	r := &countingResolver{
+		names: map[string]swarm.Address{"example.eth": addr},
+		calls: make(map[string]int),
+	}
+	c := cache.New(r, &cache.Options{
+		TTL: time.Minute,
+	})
+	now := time.Now()
+	c.SetNow(func() time.Time { return now })
+	ctx := context.Background()
+
+	resolve := func(t *testing.T, name string, wantErr error, wantCalls int) {
+		t.Helper()
+
+		got, err := c.Resolve(ctx, name)
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("got error %v, want %v", err, wantErr)
+		}
+		if err == nil && !got.Equal(addr) {
+			t.Errorf("got address %s, want %s", got, addr)
+		}
+		if r.calls[name] != wantCalls {
+			t.Errorf("got %v calls, want %v", r.calls[name], wantCalls)
+		}
+	}
+
+	resolve(t, "example.eth", nil, 1)
+	resolve(t, "example.eth", nil, 1)
+	resolve(t, "unknown.eth", resolver.ErrNotFound, 1)
+	resolve(t, "unknown.eth", resolver.ErrNotFound, 1)
+
+	// results are resolved again when they expire
+	now = now.Add(time.Minute)
+	resolve(t, "example.eth", nil, 2)
+	resolve(t, "unknown.eth", resolver.ErrNotFound, 2)
+
+	// other errors are not cached
+	errResolve := errors.New("resolve")
+	r.err = errResolve
+	resolve(t, "other.eth", errResolve, 1)
+	resolve(t, "other.eth", errResolve, 2)
+}
+
+func TestCache_capacity(t *testing.T) {
+	r := &countingResolver{
+		calls: make(map[string]int),
+	}
+	c := cache.New(r, &cache.Options{
+		Capacity: 2,
+	})
+	ctx := context.Background()
+
+	for _, name := range []string{"a", "b", "c", "a", "b", "c"} {
+		if _, err := c.Resolve(ctx, name); !errors.Is(err, resolver.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, resolver.ErrNotFound)
+		}
+	}
+	var calls int
+	for _, n := range r.calls {
+		calls += n
+	}
+	// at most two names are cached after the first three resolutions
+	if calls < 4 {
+		t.Errorf("got %v calls, want at least 4", calls)
+	}
+}
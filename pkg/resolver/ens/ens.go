@@ -0,0 +1,263 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ens provides a resolver of ENS names that reads content hashes
+// from the ENS registry and resolver contracts through an Ethereum
+// JSON-RPC endpoint.
+package ens
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+var _ resolver.Interface = (*Resolver)(nil)
+
+const (
+	// DefaultRegistryAddress is the address of the
+	// ENS registry contract on the Ethereum mainnet.
+	DefaultRegistryAddress = "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+
+	// tld is the top level domain of resolved names.
+	tld = ".eth"
+	// maxResponseSize limits the size of JSON-RPC responses.
+	maxResponseSize = 1024 * 1024
+)
+
+var (
+	// function selectors of the contract calls
+	resolverSelector    = []byte{0x01, 0x78, 0xb8, 0xbf} // resolver(bytes32)
+	contenthashSelector = []byte{0xbc, 0x1c, 0x58, 0xd1} // contenthash(bytes32)
+
+	// swarmContentHashPrefix is the EIP-1577 prefix of content hashes
+	// with Swarm references: the swarm-ns namespace, CIDv1,
+	// swarm-manifest codec and keccak-256 multihash of 32 bytes.
+	swarmContentHashPrefix = []byte{0xe4, 0x01, 0x01, 0xfa, 0x01, 0x1b, 0x20}
+
+	errInvalidResult = errors.New("invalid contract call result")
+)
+
+// Options are Resolver parameters.
+type Options struct {
+	// Endpoint is the URL of the Ethereum JSON-RPC endpoint.
+	Endpoint string
+	// RegistryAddress is the hex encoded address of the ENS registry
+	// contract, DefaultRegistryAddress is used if it is not set.
+	RegistryAddress string
+	// Client is used to make requests to the endpoint,
+	// http.DefaultClient is used if it is not set.
+	Client *http.Client
+}
+
+// Resolver resolves ENS names that end with .eth to Swarm references
+// from their content hashes.
+type Resolver struct {
+	endpoint string
+	registry string
+	client   *http.Client
+	id       uint64 // last JSON-RPC request id
+}
+
+// New constructs a new Resolver.
+func New(o Options) (*Resolver, error) {
+	if o.Endpoint == "" {
+		return nil, errors.New("endpoint not set")
+	}
+	registry := o.RegistryAddress
+	if registry == "" {
+		registry = DefaultRegistryAddress
+	}
+	if b, err := decodeHex(registry); err != nil || len(b) != 20 {
+		return nil, fmt.Errorf("invalid registry address %q", registry)
+	}
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Resolver{
+		endpoint: o.Endpoint,
+		registry: registry,
+		client:   client,
+	}, nil
+}
+
+// Resolve returns the Swarm reference from the content hash of the
+// name. It returns resolver.ErrNotFound if the name is not an ENS name,
+// has no resolver contract or no Swarm content hash.
+func (r *Resolver) Resolve(ctx context.Context, name string) (swarm.Address, error) {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, tld) {
+		return swarm.ZeroAddress, resolver.ErrNotFound
+	}
+	node, err := NameHash(name)
+	if err != nil {
+		return swarm.ZeroAddress, resolver.ErrNotFound
+	}
+
+	result, err := r.call(ctx, r.registry, resolverSelector, node)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("get resolver of %s: %w", name, err)
+	}
+	if len(result) != 32 {
+		return swarm.ZeroAddress, fmt.Errorf("get resolver of %s: %w", name, errInvalidResult)
+	}
+	resolverAddress := result[12:]
+	if bytes.Equal(resolverAddress, make([]byte, 20)) {
+		return swarm.ZeroAddress, resolver.ErrNotFound
+	}
+
+	result, err = r.call(ctx, "0x"+hex.EncodeToString(resolverAddress), contenthashSelector, node)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("get content hash of %s: %w", name, err)
+	}
+	contentHash, err := decodeBytes(result)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("get content hash of %s: %w", name, err)
+	}
+	if !bytes.HasPrefix(contentHash, swarmContentHashPrefix) || len(contentHash) != len(swarmContentHashPrefix)+swarm.HashSize {
+		// the name has no content hash or not a swarm one
+		return swarm.ZeroAddress, resolver.ErrNotFound
+	}
+	return swarm.NewAddress(contentHash[len(swarmContentHashPrefix):]), nil
+}
+
+// NameHash returns the EIP-137 hash of the name.
+func NameHash(name string) ([]byte, error) {
+	node := make([]byte, 32)
+	if name == "" {
+		return node, nil
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		if labels[i] == "" {
+			return nil, fmt.Errorf("invalid name %q", name)
+		}
+		node = keccak256(node, keccak256([]byte(labels[i])))
+	}
+	return node, nil
+}
+
+// EncodeContentHash returns the EIP-1577 content hash of the reference.
+func EncodeContentHash(addr swarm.Address) []byte {
+	return append(append([]byte(nil), swarmContentHashPrefix...), addr.Bytes()...)
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result string    `json:"result"`
+	Error  *rpcError `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type callParams struct {
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+// call makes an eth_call of the contract function with the selector and
+// the 32 byte argument, and returns the result.
+func (r *Resolver) call(ctx context.Context, to string, selector, arg []byte) ([]byte, error) {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&r.id, 1),
+		Method:  "eth_call",
+		Params: []interface{}{
+			callParams{
+				To:   to,
+				Data: "0x" + hex.EncodeToString(selector) + hex.EncodeToString(arg),
+			},
+			"latest",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return nil, fmt.Errorf("json-rpc endpoint: %s", resp.Status)
+	}
+
+	var v rpcResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&v); err != nil {
+		return nil, fmt.Errorf("decode json-rpc response: %w", err)
+	}
+	if v.Error != nil {
+		return nil, fmt.Errorf("json-rpc error %d: %s", v.Error.Code, v.Error.Message)
+	}
+	return decodeHex(v.Result)
+}
+
+// decodeBytes returns the value of the ABI encoded dynamic bytes
+// type, which is the only value of the result.
+func decodeBytes(result []byte) ([]byte, error) {
+	if len(result) == 0 {
+		// contracts without the function return no data
+		return nil, nil
+	}
+	if len(result) < 64 {
+		return nil, errInvalidResult
+	}
+	offset := new(big.Int).SetBytes(result[:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(result)-32) {
+		return nil, errInvalidResult
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(result[start-32 : start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(result))-start {
+		return nil, errInvalidResult
+	}
+	return result[start : start+length.Uint64()], nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil, fmt.Errorf("hex string without 0x prefix")
+	}
+	return hex.DecodeString(s[2:])
+}
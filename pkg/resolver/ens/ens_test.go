@@ -0,0 +1,166 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ens_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/resolver/ens"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	registryAddress = "0x1111111111111111111111111111111111111111"
+	resolverAddress = "0x2222222222222222222222222222222222222222"
+)
+
+func TestNameHash(t *testing.T) {
+	for name, want := range map[string]string{
+		"":        "0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	} {
+		got, err := ens.NameHash(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(got) != want {
+			t.Errorf("%q: got %x, want %s", name, got, want)
+		}
+	}
+	if _, err := ens.NameHash("foo..eth"); err == nil {
+		t.Error("expected error for empty label")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	addr := swarm.MustParseHexAddress("aabbccddeeff00112233445566778899aabbccddeeff00112233445566778899")
+
+	// the stand-in node knows resolvers and content hashes of names
+	resolvers := map[string]string{
+		"docs.example.eth": resolverAddress,
+		"other.eth":        resolverAddress,
+	}
+	contentHashes := map[string][]byte{
+		"docs.example.eth": ens.EncodeContentHash(addr),
+		// ipfs content hash
+		"other.eth": {0xe3, 0x01, 0x01, 0x70, 0x12, 0x20},
+	}
+	var failing bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64 `json:"id"`
+			Method string `json:"method"`
+			Params []json.RawMessage
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		if failing {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":    req.ID,
+				"error": map[string]interface{}{"code": -32000, "message": "failing"},
+			})
+			return
+		}
+		var call struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		if req.Method != "eth_call" || len(req.Params) != 2 {
+			t.Errorf("unexpected request %s", req.Method)
+			return
+		}
+		if err := json.Unmarshal(req.Params[0], &call); err != nil {
+			t.Error(err)
+			return
+		}
+
+		var result []byte
+		for name := range resolvers {
+			node, err := ens.NameHash(name)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			switch call.Data {
+			case "0x0178b8bf" + hex.EncodeToString(node):
+				if call.To != registryAddress {
+					t.Errorf("got registry %s", call.To)
+				}
+				result = make([]byte, 32)
+				b, _ := hex.DecodeString(strings.TrimPrefix(resolvers[name], "0x"))
+				copy(result[12:], b)
+			case "0xbc1c58d1" + hex.EncodeToString(node):
+				if call.To != resolverAddress {
+					t.Errorf("got resolver %s", call.To)
+				}
+				result = ens.EncodeBytes(contentHashes[name])
+			}
+		}
+		if result == nil {
+			// the registry returns zero address for unknown names
+			result = make([]byte, 32)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  "0x" + hex.EncodeToString(result),
+		})
+	}))
+	defer ts.Close()
+
+	r, err := ens.New(ens.Options{
+		Endpoint:        ts.URL,
+		RegistryAddress: registryAddress,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	got, err := r.Resolve(ctx, "Docs.Example.eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(addr) {
+		t.Errorf("got address %s, want %s", got, addr)
+	}
+
+	for _, name := range []string{
+		"unknown.eth",  // no resolver
+		"other.eth",    // not a swarm content hash
+		"example.com",  // not an ens name
+		"example..eth", // invalid name
+	} {
+		if _, err := r.Resolve(ctx, name); !errors.Is(err, resolver.ErrNotFound) {
+			t.Errorf("%s: got error %v, want %v", name, err, resolver.ErrNotFound)
+		}
+	}
+
+	failing = true
+	if _, err := r.Resolve(ctx, "docs.example.eth"); err == nil || errors.Is(err, resolver.ErrNotFound) {
+		t.Errorf("got error %v, want json-rpc error", err)
+	}
+}
+
+func TestEncodeContentHash(t *testing.T) {
+	addr := swarm.MustParseHexAddress("aabbccddeeff00112233445566778899aabbccddeeff00112233445566778899")
+	got := ens.EncodeContentHash(addr)
+	want, _ := hex.DecodeString("e40101fa011b20" + addr.String())
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
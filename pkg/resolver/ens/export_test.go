@@ -0,0 +1,18 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ens
+
+import "encoding/binary"
+
+// EncodeBytes returns the ABI encoding of the
+// dynamic bytes type as the only value.
+func EncodeBytes(b []byte) []byte {
+	padded := (len(b) + 31) / 32 * 32
+	result := make([]byte, 64+padded)
+	binary.BigEndian.PutUint64(result[24:32], 32)
+	binary.BigEndian.PutUint64(result[56:64], uint64(len(b)))
+	copy(result[64:], b)
+	return result
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package resolver provides the interface of services that resolve
+// names, like ENS domains, to Swarm references.
+package resolver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrNotFound is returned if the name can not
+// be resolved by the resolver.
+var ErrNotFound = errors.New("name not found")
+
+// Interface resolves names to Swarm references.
+type Interface interface {
+	Resolve(ctx context.Context, name string) (swarm.Address, error)
+}
+
+// Multi resolves names with the first of its resolvers
+// that knows the name.
+type Multi []Interface
+
+// NewMulti constructs a new Multi from resolvers
+// in the order in which they are tried.
+func NewMulti(resolvers ...Interface) Multi {
+	return Multi(resolvers)
+}
+
+// Resolve returns the reference from the first resolver that does not
+// return ErrNotFound. Errors of other resolvers are returned only if
+// none of them resolved the name.
+func (m Multi) Resolve(ctx context.Context, name string) (swarm.Address, error) {
+	var lastErr error = ErrNotFound
+	for _, r := range m {
+		addr, err := r.Resolve(ctx, name)
+		if err == nil {
+			return addr, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			lastErr = err
+		}
+	}
+	return swarm.ZeroAddress, lastErr
+}
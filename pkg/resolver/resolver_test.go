@@ -0,0 +1,56 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/resolver/static"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+type errResolver struct {
+	err error
+}
+
+func (r errResolver) Resolve(context.Context, string) (swarm.Address, error) {
+	return swarm.ZeroAddress, r.err
+}
+
+func TestMulti(t *testing.T) {
+	first := swarm.MustParseHexAddress("aa")
+	second := swarm.MustParseHexAddress("bb")
+	errResolve := errors.New("resolve")
+
+	m := resolver.NewMulti(
+		static.New(map[string]swarm.Address{"first.eth": first}),
+		errResolver{err: errResolve},
+		static.New(map[string]swarm.Address{"first.eth": second, "second.eth": second}),
+	)
+	ctx := context.Background()
+
+	for name, want := range map[string]swarm.Address{
+		"first.eth":  first,
+		"second.eth": second,
+	} {
+		got, err := m.Resolve(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("%s: got address %s, want %s", name, got, want)
+		}
+	}
+
+	if _, err := m.Resolve(ctx, "unknown.eth"); !errors.Is(err, errResolve) {
+		t.Errorf("got error %v, want %v", err, errResolve)
+	}
+	if _, err := resolver.NewMulti().Resolve(ctx, "unknown.eth"); !errors.Is(err, resolver.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, resolver.ErrNotFound)
+	}
+}
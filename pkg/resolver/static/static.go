@@ -0,0 +1,85 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package static provides a resolver of names from a fixed set,
+// which can be loaded from a file.
+package static
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ resolver.Interface = (*Resolver)(nil)
+
+// Resolver resolves names from a fixed set. Names are
+// case insensitive.
+type Resolver struct {
+	names map[string]swarm.Address
+}
+
+// New constructs a new Resolver of the provided names.
+func New(names map[string]swarm.Address) *Resolver {
+	r := &Resolver{
+		names: make(map[string]swarm.Address, len(names)),
+	}
+	for name, addr := range names {
+		r.names[strings.ToLower(name)] = addr
+	}
+	return r
+}
+
+// Load constructs a new Resolver of names from the file. Every line of
+// the file contains a name and the hex encoded reference, separated by
+// whitespace. Empty lines and lines starting with # are ignored.
+func Load(path string) (*Resolver, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names, err := parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return New(names), nil
+}
+
+func parse(r io.Reader) (map[string]swarm.Address, error) {
+	names := make(map[string]swarm.Address)
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: name and reference expected", line)
+		}
+		addr, err := swarm.ParseHexAddress(fields[1])
+		if err != nil || len(addr.Bytes()) != swarm.HashSize {
+			return nil, fmt.Errorf("line %d: invalid reference %q", line, fields[1])
+		}
+		names[fields[0]] = addr
+	}
+	return names, s.Err()
+}
+
+// Resolve returns the reference of the name.
+func (r *Resolver) Resolve(_ context.Context, name string) (swarm.Address, error) {
+	addr, ok := r.names[strings.ToLower(name)]
+	if !ok {
+		return swarm.ZeroAddress, resolver.ErrNotFound
+	}
+	return addr, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package static_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/resolver/static"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const ref = "aabbccddeeff00112233445566778899aabbccddeeff00112233445566778899"
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bee-static-resolver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "names")
+	if err := ioutil.WriteFile(path, []byte("# sites\n\ndocs.example.eth "+ref+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := static.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	addr, err := r.Resolve(ctx, "Docs.Example.eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := swarm.MustParseHexAddress(ref); !addr.Equal(want) {
+		t.Errorf("got address %s, want %s", addr, want)
+	}
+
+	if _, err := r.Resolve(ctx, "example.eth"); !errors.Is(err, resolver.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, resolver.ErrNotFound)
+	}
+
+	for _, content := range []string{
+		"docs.example.eth",
+		"docs.example.eth aabbcc",
+		"docs.example.eth " + ref + " extra",
+	} {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := static.Load(path); err == nil {
+			t.Errorf("%q: expected error", content)
+		}
+	}
+}
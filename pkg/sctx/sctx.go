@@ -39,3 +39,17 @@ func GetTag(ctx context.Context) uint32 {
 	}
 	return 0
 }
+
+// SetHTTPRequestID sets the http request identifier in the context
+func SetHTTPRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, HTTPRequestIDKey{}, id)
+}
+
+// GetHTTPRequestID gets the http request identifier from the context
+func GetHTTPRequestID(ctx context.Context) string {
+	v, ok := ctx.Value(HTTPRequestIDKey{}).(string)
+	if ok {
+		return v
+	}
+	return ""
+}
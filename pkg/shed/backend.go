@@ -0,0 +1,121 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Names of supported key-value store backends.
+const (
+	BackendBadger  = "badger"
+	BackendLevelDB = "leveldb"
+)
+
+var (
+	// ErrBatchTooBig is returned by Batch methods if the change
+	// can not be added to the batch because of its size limit.
+	ErrBatchTooBig = errors.New("shed: batch too big")
+	// ErrUnknownBackend is returned by NewDB if the backend
+	// name is not one of the supported backends.
+	ErrUnknownBackend = errors.New("shed: unknown backend")
+)
+
+// Backend is an ordered key-value store that persists DB data.
+// Keys are ordered lexicographically.
+type Backend interface {
+	Reader
+	// Put stores the value under the key.
+	Put(key, value []byte) (err error)
+	// Delete removes the key and its value.
+	Delete(key []byte) (err error)
+	// View calls the function with a Reader
+	// over a consistent snapshot of the store.
+	View(fn func(r Reader) error) (err error)
+	// NewBatch returns a Batch for atomic changes.
+	NewBatch() (b Batch)
+	// Close releases all resources of the store.
+	Close() (err error)
+}
+
+// Reader provides read operations on a Backend or its snapshot.
+type Reader interface {
+	// Get returns the value stored under the key,
+	// or ErrNotFound if the key is not stored.
+	Get(key []byte) (value []byte, err error)
+	// Has returns true if the key is stored.
+	Has(key []byte) (yes bool, err error)
+	// ValueSize returns the length of the value stored under
+	// the key, or ErrNotFound if the key is not stored.
+	ValueSize(key []byte) (size int, err error)
+	// Iterate calls the function for stored keys and values in the
+	// order and from the start key defined by options. Slices passed
+	// to the function are valid only until it returns.
+	Iterate(o RangeOptions, fn func(key, value []byte) (stop bool, err error)) (err error)
+}
+
+// RangeOptions defines the iteration over Backend keys.
+type RangeOptions struct {
+	// Start is the key to start the iteration from. If the key is not
+	// stored, the iteration starts from the next key in the iteration
+	// order. If it is nil, all keys are iterated on.
+	Start []byte
+	// Reverse iterates over keys in descending order.
+	Reverse bool
+	// KeysOnly does not read values, passing nil values
+	// to the iteration function.
+	KeysOnly bool
+}
+
+// Batch holds changes that are applied atomically on Commit.
+type Batch interface {
+	// Put adds storing the value under the key to the batch.
+	Put(key, value []byte) (err error)
+	// Delete adds removing the key to the batch.
+	Delete(key []byte) (err error)
+	// Commit applies all changes to the store.
+	Commit() (err error)
+	// Discard drops all changes without applying them.
+	Discard()
+}
+
+// newBackend opens the backend with the provided name. If the path
+// contains data from a different backend, an error is returned.
+func newBackend(name, path string) (Backend, error) {
+	if name == "" {
+		name = BackendBadger
+	}
+	if path != "" {
+		if existing := detectBackend(path); existing != "" && existing != name {
+			return nil, fmt.Errorf("shed: %s contains %s data, not %s", path, existing, name)
+		}
+	}
+	switch name {
+	case BackendBadger:
+		return newBadgerBackend(path)
+	case BackendLevelDB:
+		return newLevelDBBackend(path)
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownBackend, name)
+}
+
+// detectBackend returns the name of the backend that created files in
+// the directory, or an empty string if it can not be determined.
+func detectBackend(path string) string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(path, name))
+		return err == nil
+	}
+	switch {
+	case exists("CURRENT"):
+		return BackendLevelDB
+	case exists("MANIFEST"):
+		return BackendBadger
+	}
+	return ""
+}
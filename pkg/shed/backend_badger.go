@@ -0,0 +1,206 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"errors"
+
+	"github.com/dgraph-io/badger/v2"
+)
+
+const (
+	DefaultSyncWrites         = false  // Dont sync the writes to disk, instead delay it as a batch
+	DefaultValueThreshold     = 1024   // Anything less than 1K value will be store with the LSM key itself
+	DefaultValueLogMaxEntries = 500000 // Max number of entries in a value log
+)
+
+var _ Backend = (*badgerBackend)(nil)
+
+// badgerBackend is the Backend implementation with BadgerDB.
+type badgerBackend struct {
+	db *badger.DB
+}
+
+// newBadgerBackend opens the BadgerDB with options that make the DB
+// useful for Chunk, State as well as Index stores. If the path is
+// empty, the database is kept only in memory.
+func newBadgerBackend(path string) (*badgerBackend, error) {
+	o := badger.DefaultOptions(path)
+	o.SyncWrites = DefaultSyncWrites
+	o.ValueThreshold = DefaultValueThreshold
+	o.ValueLogMaxEntries = DefaultValueLogMaxEntries
+	o.Logger = nil // Dont enable the badger logs
+	if path == "" {
+		o.InMemory = true
+		o.SyncWrites = false
+	}
+	db, err := badger.Open(o)
+	if err != nil {
+		return nil, err
+	}
+	return &badgerBackend{db: db}, nil
+}
+
+func (b *badgerBackend) Get(key []byte) (value []byte, err error) {
+	err = b.View(func(r Reader) (err error) {
+		value, err = r.Get(key)
+		return err
+	})
+	return value, err
+}
+
+func (b *badgerBackend) Has(key []byte) (yes bool, err error) {
+	err = b.View(func(r Reader) (err error) {
+		yes, err = r.Has(key)
+		return err
+	})
+	return yes, err
+}
+
+func (b *badgerBackend) ValueSize(key []byte) (size int, err error) {
+	err = b.View(func(r Reader) (err error) {
+		size, err = r.ValueSize(key)
+		return err
+	})
+	return size, err
+}
+
+func (b *badgerBackend) Iterate(o RangeOptions, fn func(key, value []byte) (stop bool, err error)) (err error) {
+	return b.View(func(r Reader) error {
+		return r.Iterate(o, fn)
+	})
+}
+
+func (b *badgerBackend) Put(key, value []byte) (err error) {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, value)
+	})
+}
+
+func (b *badgerBackend) Delete(key []byte) (err error) {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+func (b *badgerBackend) View(fn func(r Reader) error) (err error) {
+	return b.db.View(func(txn *badger.Txn) error {
+		return fn(badgerReader{txn: txn})
+	})
+}
+
+func (b *badgerBackend) NewBatch() Batch {
+	return badgerBatch{txn: b.db.NewTransaction(true)}
+}
+
+func (b *badgerBackend) Close() (err error) {
+	return b.db.Close()
+}
+
+// badgerReader reads from a BadgerDB transaction.
+type badgerReader struct {
+	txn *badger.Txn
+}
+
+func (r badgerReader) Get(key []byte) (value []byte, err error) {
+	item, err := r.txn.Get(key)
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+func (r badgerReader) Has(key []byte) (yes bool, err error) {
+	_, err = r.txn.Get(key)
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r badgerReader) ValueSize(key []byte) (size int, err error) {
+	item, err := r.txn.Get(key)
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	// item.ValueSize is only an estimate for values
+	// stored in the value log
+	err = item.Value(func(v []byte) error {
+		size = len(v)
+		return nil
+	})
+	return size, err
+}
+
+func (r badgerReader) Iterate(o RangeOptions, fn func(key, value []byte) (stop bool, err error)) (err error) {
+	io := badger.DefaultIteratorOptions
+	io.PrefetchValues = !o.KeysOnly
+	io.PrefetchSize = 1024
+	io.Reverse = o.Reverse
+	i := r.txn.NewIterator(io)
+	defer i.Close()
+
+	if o.Start == nil {
+		i.Rewind()
+	} else {
+		i.Seek(o.Start)
+	}
+	for ; i.Valid(); i.Next() {
+		item := i.Item()
+		var value []byte
+		if !o.KeysOnly {
+			value, err = item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+		}
+		stop, err := fn(item.Key(), value)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// badgerBatch is a Batch with a BadgerDB update transaction.
+type badgerBatch struct {
+	txn *badger.Txn
+}
+
+func (b badgerBatch) Put(key, value []byte) (err error) {
+	return badgerBatchError(b.txn.Set(key, value))
+}
+
+func (b badgerBatch) Delete(key []byte) (err error) {
+	return badgerBatchError(b.txn.Delete(key))
+}
+
+func (b badgerBatch) Commit() (err error) {
+	return b.txn.Commit()
+}
+
+func (b badgerBatch) Discard() {
+	b.txn.Discard()
+}
+
+// badgerBatchError translates the transaction size error to ErrBatchTooBig.
+func badgerBatchError(err error) error {
+	if errors.Is(err, badger.ErrTxnTooBig) {
+		return ErrBatchTooBig
+	}
+	return err
+}
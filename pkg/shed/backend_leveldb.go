@@ -0,0 +1,162 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+var _ Backend = (*levelDBBackend)(nil)
+
+// levelDBBackend is the Backend implementation with LevelDB.
+type levelDBBackend struct {
+	db *leveldb.DB
+	levelDBReader
+}
+
+// newLevelDBBackend opens the LevelDB database in the path directory.
+// If the path is empty, the database is kept only in memory.
+func newLevelDBBackend(path string) (*levelDBBackend, error) {
+	var db *leveldb.DB
+	var err error
+	if path == "" {
+		db, err = leveldb.Open(storage.NewMemStorage(), nil)
+	} else {
+		db, err = leveldb.OpenFile(path, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &levelDBBackend{
+		db:            db,
+		levelDBReader: levelDBReader{r: db},
+	}, nil
+}
+
+func (b *levelDBBackend) Put(key, value []byte) (err error) {
+	return b.db.Put(key, value, nil)
+}
+
+func (b *levelDBBackend) Delete(key []byte) (err error) {
+	return b.db.Delete(key, nil)
+}
+
+func (b *levelDBBackend) View(fn func(r Reader) error) (err error) {
+	s, err := b.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer s.Release()
+	return fn(levelDBReader{r: s})
+}
+
+func (b *levelDBBackend) NewBatch() Batch {
+	return &levelDBBatch{db: b.db}
+}
+
+func (b *levelDBBackend) Close() (err error) {
+	return b.db.Close()
+}
+
+// levelDBReader reads from a LevelDB database or its snapshot.
+type levelDBReader struct {
+	r interface {
+		Get(key []byte, ro *opt.ReadOptions) (value []byte, err error)
+		Has(key []byte, ro *opt.ReadOptions) (ret bool, err error)
+		NewIterator(slice *util.Range, ro *opt.ReadOptions) iterator.Iterator
+	}
+}
+
+func (r levelDBReader) Get(key []byte) (value []byte, err error) {
+	value, err = r.r.Get(key, nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+func (r levelDBReader) Has(key []byte) (yes bool, err error) {
+	return r.r.Has(key, nil)
+}
+
+func (r levelDBReader) ValueSize(key []byte) (size int, err error) {
+	value, err := r.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	return len(value), nil
+}
+
+func (r levelDBReader) Iterate(o RangeOptions, fn func(key, value []byte) (stop bool, err error)) (err error) {
+	i := r.r.NewIterator(nil, nil)
+	defer i.Release()
+
+	var ok bool
+	next := i.Next
+	switch {
+	case o.Reverse:
+		next = i.Prev
+		if o.Start == nil {
+			ok = i.Last()
+			break
+		}
+		// start from the last key that is not greater than the start key
+		ok = i.Seek(o.Start)
+		if !ok {
+			ok = i.Last()
+		} else if bytes.Compare(i.Key(), o.Start) > 0 {
+			ok = i.Prev()
+		}
+	case o.Start == nil:
+		ok = i.First()
+	default:
+		ok = i.Seek(o.Start)
+	}
+	for ; ok; ok = next() {
+		var value []byte
+		if !o.KeysOnly {
+			value = i.Value()
+		}
+		stop, err := fn(i.Key(), value)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return i.Error()
+}
+
+// levelDBBatch is a Batch with a LevelDB write batch.
+type levelDBBatch struct {
+	db    *leveldb.DB
+	batch leveldb.Batch
+}
+
+func (b *levelDBBatch) Put(key, value []byte) (err error) {
+	b.batch.Put(key, value)
+	return nil
+}
+
+func (b *levelDBBatch) Delete(key []byte) (err error) {
+	b.batch.Delete(key)
+	return nil
+}
+
+func (b *levelDBBatch) Commit() (err error) {
+	return b.db.Write(&b.batch, nil)
+}
+
+func (b *levelDBBatch) Discard() {
+	b.batch.Reset()
+}
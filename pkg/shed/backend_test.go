@@ -0,0 +1,225 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+)
+
+var testBackends = []string{BackendBadger, BackendLevelDB}
+
+// TestBackend validates operations of all Backend implementations.
+func TestBackend(t *testing.T) {
+	for _, name := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			b, err := newBackend(name, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+
+			for _, k := range []string{"b", "d", "f"} {
+				if err := b.Put([]byte(k), []byte("value-"+k)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			t.Run("get", func(t *testing.T) {
+				v, err := b.Get([]byte("d"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(v) != "value-d" {
+					t.Errorf("got value %q, want %q", v, "value-d")
+				}
+				if _, err := b.Get([]byte("c")); !errors.Is(err, ErrNotFound) {
+					t.Errorf("got error %v, want %v", err, ErrNotFound)
+				}
+				has, err := b.Has([]byte("f"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !has {
+					t.Error("key not found")
+				}
+			})
+
+			t.Run("iterate", func(t *testing.T) {
+				for _, tc := range []struct {
+					o    RangeOptions
+					want string
+				}{
+					{o: RangeOptions{}, want: "b:value-b,d:value-d,f:value-f"},
+					{o: RangeOptions{Start: []byte("c")}, want: "d:value-d,f:value-f"},
+					{o: RangeOptions{Start: []byte("d"), KeysOnly: true}, want: "d:,f:"},
+					{o: RangeOptions{Reverse: true}, want: "f:value-f,d:value-d,b:value-b"},
+					{o: RangeOptions{Start: []byte("e"), Reverse: true}, want: "d:value-d,b:value-b"},
+					{o: RangeOptions{Start: []byte("d"), Reverse: true}, want: "d:value-d,b:value-b"},
+					{o: RangeOptions{Start: []byte("z"), Reverse: true}, want: "f:value-f,d:value-d,b:value-b"},
+					{o: RangeOptions{Start: []byte("a"), Reverse: true}, want: ""},
+				} {
+					if got := iterateBackend(t, b, tc.o); got != tc.want {
+						t.Errorf("%+v: got %q, want %q", tc.o, got, tc.want)
+					}
+				}
+			})
+
+			t.Run("view", func(t *testing.T) {
+				err := b.View(func(r Reader) error {
+					if err := b.Put([]byte("c"), []byte("value-c")); err != nil {
+						return err
+					}
+					if has, err := r.Has([]byte("c")); err != nil || has {
+						return fmt.Errorf("got key written after the snapshot: %v", err)
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := b.Delete([]byte("c")); err != nil {
+					t.Fatal(err)
+				}
+			})
+
+			t.Run("batch", func(t *testing.T) {
+				batch := b.NewBatch()
+				if err := batch.Put([]byte("a"), []byte("value-a")); err != nil {
+					t.Fatal(err)
+				}
+				if err := batch.Delete([]byte("b")); err != nil {
+					t.Fatal(err)
+				}
+				batch.Discard()
+				if got, want := iterateBackend(t, b, RangeOptions{KeysOnly: true}), "b:,d:,f:"; got != want {
+					t.Errorf("got %q after discard, want %q", got, want)
+				}
+
+				batch = b.NewBatch()
+				if err := batch.Put([]byte("a"), []byte("value-a")); err != nil {
+					t.Fatal(err)
+				}
+				if err := batch.Delete([]byte("b")); err != nil {
+					t.Fatal(err)
+				}
+				if got, want := iterateBackend(t, b, RangeOptions{KeysOnly: true}), "b:,d:,f:"; got != want {
+					t.Errorf("got %q before commit, want %q", got, want)
+				}
+				if err := batch.Commit(); err != nil {
+					t.Fatal(err)
+				}
+				if got, want := iterateBackend(t, b, RangeOptions{KeysOnly: true}), "a:,d:,f:"; got != want {
+					t.Errorf("got %q after commit, want %q", got, want)
+				}
+			})
+		})
+	}
+}
+
+// TestNewDB_backends validates DB operations with all backends
+// and that the database can not be opened with a different
+// backend from the one that created it.
+func TestNewDB_backends(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	for _, name := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "shed-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			db, err := NewDB(dir, &Options{Backend: name}, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			index, err := db.NewIndex("retrieval", retrievalIndexFuncs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, a := range []string{"one", "two", "three"} {
+				if err := index.Put(Item{Address: []byte(a), Data: []byte(a)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			count, err := index.Count()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 3 {
+				t.Errorf("got count %v, want 3", count)
+			}
+			first, err := index.First(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(first.Address) != "one" {
+				t.Errorf("got first %q, want %q", first.Address, "one")
+			}
+			last, err := index.Last(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(last.Address) != "two" {
+				t.Errorf("got last %q, want %q", last.Address, "two")
+			}
+			if err := db.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, other := range testBackends {
+				if other == name {
+					continue
+				}
+				if _, err := NewDB(dir, &Options{Backend: other}, logger); err == nil {
+					t.Errorf("opened %s database with %s backend", name, other)
+				}
+			}
+
+			db, err = NewDB(dir, &Options{Backend: name}, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			index, err = db.NewIndex("retrieval", retrievalIndexFuncs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := index.Get(Item{Address: []byte("three")}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := NewDB("", &Options{Backend: "unknown"}, logger); !errors.Is(err, ErrUnknownBackend) {
+			t.Errorf("got error %v, want %v", err, ErrUnknownBackend)
+		}
+	})
+}
+
+// iterateBackend returns keys and values in the iteration order
+// formatted as a comma separated list of key:value pairs.
+func iterateBackend(t *testing.T, b Backend, o RangeOptions) string {
+	t.Helper()
+
+	var got []string
+	err := b.Iterate(o, func(key, value []byte) (stop bool, err error) {
+		got = append(got, string(key)+":"+string(value))
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Join(got, ",")
+}
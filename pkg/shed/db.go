@@ -26,50 +26,58 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"sync"
 
-	"github.com/dgraph-io/badger/v2"
 	"github.com/ethersphere/bee/pkg/logging"
 )
 
-const (
-	DefaultSyncWrites         = false  // Dont sync the writes to disk, instead delay it as a batch
-	DefaultValueThreshold     = 1024   // Anything less than 1K value will be store with the LSM key itself
-	DefaultValueLogMaxEntries = 500000 // Max number of entries in a value log
-)
-
 var (
 	ErrNotFound = errors.New("shed: not found")
 )
 
-// DB provides abstractions over badgerDB in order to
+// DB provides abstractions over a key-value store Backend in order to
 // implement complex structures using fields and ordered indexes.
 // It provides a schema functionality to store fields and indexes
 // information about naming and types.
 type DB struct {
 	path    string
-	bdb     *badger.DB
+	backend Backend
 	metrics metrics
 	logger  logging.Logger
+
+	// sizes holds the number of bytes
+	// of every index by its prefix
+	sizes   map[byte]uint64
+	sizesMu sync.Mutex
+}
+
+// Options holds optional parameters for the DB.
+type Options struct {
+	// Backend is the name of the key-value store that persists
+	// data, BackendBadger or BackendLevelDB. BadgerDB is used if
+	// it is not set.
+	Backend string
 }
 
-// NewDB opens the badger DB with options that make the DB useful for
-// Chunk, State as well as Index stores
-func NewDB(path string, logger logging.Logger) (db *DB, err error) {
-	o := badger.DefaultOptions(path)
-	o.SyncWrites = DefaultSyncWrites
-	o.ValueThreshold = DefaultValueThreshold
-	o.ValueLogMaxEntries = DefaultValueLogMaxEntries
-	o.Logger = nil // Dont enable the badger logs
-	database, err := badger.Open(o)
+// NewDB opens the DB with the key-value store backend selected by
+// options, making it useful for Chunk, State as well as Index stores.
+// If the path is empty, the database is kept only in memory and its
+// data is lost on Close.
+func NewDB(path string, o *Options, logger logging.Logger) (db *DB, err error) {
+	if o == nil {
+		o = new(Options)
+	}
+	backend, err := newBackend(o.Backend, path)
 	if err != nil {
 		return nil, err
 	}
 
 	db = &DB{
-		bdb:     database,
+		backend: backend,
 		metrics: newMetrics(),
 		logger:  logger,
 		path:    path,
+		sizes:   make(map[byte]uint64),
 	}
 
 	if _, err = db.getSchema(); err != nil {
@@ -79,110 +87,92 @@ func NewDB(path string, logger logging.Logger) (db *DB, err error) {
 				Fields:  make(map[string]fieldSpec),
 				Indexes: make(map[byte]indexSpec),
 			}); err != nil {
+				backend.Close()
 				return nil, err
 			}
 		} else {
+			backend.Close()
 			return nil, err
 		}
 	}
 	return db, nil
 }
 
-// Put inserts the given key and value in to badger.
+// Put inserts the given key and value in to the backend.
 func (db *DB) Put(key []byte, value []byte) (err error) {
-	return db.bdb.Update(func(txn *badger.Txn) (err error) {
-		db.metrics.PutCount.Inc()
-		err = txn.Set(key, value)
-		if err != nil {
-			db.metrics.PutFailCount.Inc()
-			return err
+	db.metrics.PutCount.Inc()
+	if isIndexKey(key) {
+		// index items are written in a batch
+		// to keep track of the index size
+		batch := db.newBatch()
+		if err = batch.Put(key, value); err == nil {
+			err = batch.Commit()
 		}
-		return nil
-	})
-
+	} else {
+		err = db.backend.Put(key, value)
+	}
+	if err != nil {
+		db.metrics.PutFailCount.Inc()
+		return err
+	}
+	return nil
 }
 
 // Get retrieves the value given the key.
 // if the key is not present a ErrNotFound is returned.
 func (db *DB) Get(key []byte) (value []byte, err error) {
-	err = db.bdb.View(func(txn *badger.Txn) (err error) {
-		item, err := txn.Get(key)
-		if err != nil {
-			db.metrics.GetFailCount.Inc()
-			if err == badger.ErrKeyNotFound {
-				db.metrics.GetNotFoundCount.Inc()
-				return ErrNotFound
-			}
-			db.metrics.GetFailCount.Inc()
-			return err
+	value, err = db.backend.Get(key)
+	if err != nil {
+		if err == ErrNotFound {
+			db.metrics.GetNotFoundCount.Inc()
+			return nil, err
 		}
-		return item.Value(func(val []byte) error {
-			value = val
-			return nil
-		})
-	})
-	if err == nil {
-		db.metrics.GetCount.Inc()
+		db.metrics.GetFailCount.Inc()
+		return nil, err
 	}
-	return value, err
+	db.metrics.GetCount.Inc()
+	return value, nil
 }
 
 // Has checks if the given key is present in the database.
 // it returns a bool indicating true or false OR error if it encounters one during the operation.
 func (db *DB) Has(key []byte) (yes bool, err error) {
-	yes = false
-	err = db.bdb.View(func(txn *badger.Txn) (err error) {
-		item, err := txn.Get(key)
-		if err != nil {
-			if err == badger.ErrKeyNotFound {
-				return nil
-			}
-			return err
-		}
-		return item.Value(func(val []byte) error {
-			yes = true
-			db.metrics.HasCount.Inc()
-			return nil
-		})
-	})
+	yes, err = db.backend.Has(key)
 	if err != nil {
 		db.metrics.HasFailCount.Inc()
 		return false, err
 	}
-
+	db.metrics.HasCount.Inc()
 	return yes, nil
 }
 
 // Delete removed the key and value if a given key is present in the DB.
 func (db *DB) Delete(key []byte) (err error) {
-	return db.bdb.Update(func(txn *badger.Txn) (err error) {
-		db.metrics.DeleteCount.Inc()
-		err = txn.Delete(key)
-		if err != nil {
-			db.metrics.DeleteFailCount.Inc()
+	db.metrics.DeleteCount.Inc()
+	if isIndexKey(key) {
+		batch := db.newBatch()
+		if err = batch.Delete(key); err == nil {
+			err = batch.Commit()
 		}
-		return err
-	})
+	} else {
+		err = db.backend.Delete(key)
+	}
+	if err != nil {
+		db.metrics.DeleteFailCount.Inc()
+	}
+	return err
 }
 
 // Count gives a count of all the keys present in the DB.
 func (db *DB) Count(ctx context.Context) (count int, err error) {
 	db.metrics.TotalCount.Inc()
-	err = db.bdb.View(func(txn *badger.Txn) (err error) {
-		o := badger.DefaultIteratorOptions
-		o.PrefetchValues = false
-		i := txn.NewIterator(o)
-		defer i.Close()
-		for i.Rewind(); i.Valid(); i.Next() {
-			item := i.Item()
-			k := item.KeySize()
-			if k < 1 {
-				continue
-			}
-			count++
-		}
-		return nil
+	err = db.backend.Iterate(RangeOptions{KeysOnly: true}, func(_, _ []byte) (stop bool, err error) {
+		count++
+		return false, nil
 	})
+	if err != nil {
+		db.metrics.TotalFailCount.Inc()
+	}
 	return count, err
 }
 
@@ -190,25 +180,12 @@ func (db *DB) Count(ctx context.Context) (count int, err error) {
 // a nil prefix acts like the total count of the DB
 func (db *DB) CountPrefix(prefix []byte) (count int, err error) {
 	db.metrics.CountPrefixCount.Inc()
-	err = db.bdb.View(func(txn *badger.Txn) (err error) {
-		o := badger.DefaultIteratorOptions
-		o.PrefetchValues = false
-		o.PrefetchSize = 1024
-		i := txn.NewIterator(o)
-		defer i.Close()
-
-		// if prefix is nil, it is equivalent to counting from beginning
-		for i.Seek(prefix); i.ValidForPrefix(prefix); i.Next() {
-			item := i.Item()
-			k := item.Key()
-			if prefix != nil {
-				if !bytes.HasPrefix(k, prefix) {
-					break
-				}
-			}
-			count++
+	err = db.backend.Iterate(RangeOptions{Start: prefix, KeysOnly: true}, func(key, _ []byte) (stop bool, err error) {
+		if !bytes.HasPrefix(key, prefix) {
+			return true, nil
 		}
-		return nil
+		count++
+		return false, nil
 	})
 	if err != nil {
 		db.metrics.CountPrefixFailCount.Inc()
@@ -219,21 +196,12 @@ func (db *DB) CountPrefix(prefix []byte) (count int, err error) {
 // CountFrom gives a count of all the keys that start from a given prefix till the end of the DB.
 func (db *DB) CountFrom(prefix []byte) (count int, err error) {
 	db.metrics.CountFromCount.Inc()
-	err = db.bdb.View(func(txn *badger.Txn) (err error) {
-		o := badger.DefaultIteratorOptions
-		o.PrefetchValues = false
-		o.PrefetchSize = 1024
-		i := txn.NewIterator(o)
-		defer i.Close()
-
-		// if prefix is nil, it is equivalent to counting from beginning
-		for i.Seek(prefix); i.Valid(); i.Next() {
-			count++
-		}
-		return nil
+	err = db.backend.Iterate(RangeOptions{Start: prefix, KeysOnly: true}, func(_, _ []byte) (stop bool, err error) {
+		count++
+		return false, nil
 	})
 	if err != nil {
-		db.metrics.CountPrefixFailCount.Inc()
+		db.metrics.CountFromFailCount.Inc()
 	}
 	return count, err
 }
@@ -243,39 +211,15 @@ func (db *DB) CountFrom(prefix []byte) (count int, err error) {
 // weather to skip the first key or not.
 func (db *DB) Iterate(startKey []byte, skipStartKey bool, fn func(key []byte, value []byte) (stop bool, err error)) (err error) {
 	db.metrics.IterationCount.Inc()
-	err = db.bdb.View(func(txn *badger.Txn) (err error) {
-		o := badger.DefaultIteratorOptions
-		o.PrefetchValues = true
-		o.PrefetchSize = 1024
-		i := txn.NewIterator(o)
-		defer i.Close()
-
-		i.Seek(startKey)
-		if !i.Valid() {
-			return nil
-		}
-
-		if skipStartKey {
-			i.Next()
-		}
-
-		for ; i.Valid(); i.Next() {
-			item := i.Item()
-			k := item.Key()
-			v, err := item.ValueCopy(nil)
-			if err != nil {
-
-				return err
-			}
-			stop, err := fn(k, v)
-			if err != nil {
-				return err
-			}
-			if stop {
-				return nil
+	first := true
+	err = db.backend.Iterate(RangeOptions{Start: startKey}, func(key, value []byte) (stop bool, err error) {
+		if first {
+			first = false
+			if skipStartKey && bytes.Equal(key, startKey) {
+				return false, nil
 			}
 		}
-		return nil
+		return fn(key, value)
 	})
 	if err != nil {
 		db.metrics.IterationFailCount.Inc()
@@ -286,25 +230,16 @@ func (db *DB) Iterate(startKey []byte, skipStartKey bool, fn func(key []byte, va
 // First returns the first key which matches the given prefix.
 func (db *DB) First(prefix []byte) (key []byte, value []byte, err error) {
 	db.metrics.FirstCount.Inc()
-	err = db.bdb.View(func(txn *badger.Txn) (err error) {
-		o := badger.DefaultIteratorOptions
-		o.PrefetchValues = true
-		o.PrefetchSize = 1
-
-		i := txn.NewIterator(o)
-		defer i.Close()
-
-		i.Seek(prefix)
-		key = i.Item().Key()
-		if !bytes.HasPrefix(key, prefix) {
-			return ErrNotFound
-		}
-		value, err = i.Item().ValueCopy(value)
-		if err != nil {
-			return err
+	err = db.backend.Iterate(RangeOptions{Start: prefix}, func(k, v []byte) (stop bool, err error) {
+		if bytes.HasPrefix(k, prefix) {
+			key = append([]byte(nil), k...)
+			value = append([]byte(nil), v...)
 		}
-		return nil
+		return true, nil
 	})
+	if err == nil && key == nil {
+		err = ErrNotFound
+	}
 	if err != nil {
 		db.metrics.FirstFailCount.Inc()
 	}
@@ -314,69 +249,56 @@ func (db *DB) First(prefix []byte) (key []byte, value []byte, err error) {
 // Last retuns the last key matching the given prefix.
 func (db *DB) Last(prefix []byte) (key []byte, value []byte, err error) {
 	db.metrics.LastCount.Inc()
-	err = db.bdb.View(func(txn *badger.Txn) (err error) {
-		o := badger.DefaultIteratorOptions
-		o.PrefetchValues = true
-		o.PrefetchSize = 1024
-		o.Reverse = true // iterate backwards
-
-		i := txn.NewIterator(o)
-		defer i.Close()
-
-		// get the next prefix in line
-		// since leveldb iterator Seek seeks to the
-		// next key if the key that it seeks to is not found
-		// and by getting the previous key, the last one for the
-		// actual prefix is found
-		nextPrefix := incByteSlice(prefix)
-		l := len(prefix)
-
-		if l > 0 && nextPrefix != nil {
-			// If there is a no key which starts which nextPrefix, badger moves the
-			// cursor to the previous key (which should be our key).
-			i.Seek(nextPrefix)
-			if bytes.HasPrefix(i.Item().Key(), prefix) {
-				key = i.Item().Key()
-				value, err = i.Item().ValueCopy(nil)
-				if err != nil {
-					return err
-				}
-			} else {
-				// If there is a key which starts with nextPrefix, we do reverse Next() to
-				// reach our key and pick that up.
-				i.Next()
-				if bytes.HasPrefix(i.Item().Key(), prefix) {
-					key = i.Item().Key()
-					value, err = i.Item().ValueCopy(nil)
-					if err != nil {
-						return err
-					}
-				}
+	// get the next prefix in line and iterate backwards
+	// from it, as the last key for the actual prefix
+	// is the first one smaller than the next prefix
+	nextPrefix := incByteSlice(prefix)
+	if len(prefix) > 0 && nextPrefix != nil {
+		err = db.backend.Iterate(RangeOptions{Start: nextPrefix, Reverse: true}, func(k, v []byte) (stop bool, err error) {
+			if bytes.Equal(k, nextPrefix) {
+				return false, nil
 			}
-		}
-
-		if key == nil {
-			return ErrNotFound
-		}
-		return nil
-	})
+			if bytes.HasPrefix(k, prefix) {
+				key = append([]byte(nil), k...)
+				value = append([]byte(nil), v...)
+			}
+			return true, nil
+		})
+	}
+	if err == nil && key == nil {
+		err = ErrNotFound
+	}
 	if err != nil {
 		db.metrics.LastFailCount.Inc()
 	}
 	return key, value, err
 }
 
-// GetBatch get a new badger transaction to be used for multiple atomic operations.
-func (db *DB) GetBatch(update bool) (txn *badger.Txn) {
+// View calls the function with a Reader over a consistent
+// snapshot of the database.
+func (db *DB) View(fn func(r Reader) error) (err error) {
+	return db.backend.View(fn)
+}
+
+// GetBatch returns a new Batch to be used for multiple atomic operations.
+func (db *DB) GetBatch() (batch Batch) {
 	db.metrics.GetBatchCount.Inc()
-	// set update to true indicating that data will be added/changed in this transaction.
-	return db.bdb.NewTransaction(update)
+	return db.newBatch()
+}
+
+// newBatch returns a Batch that keeps track of index sizes.
+func (db *DB) newBatch() (batch Batch) {
+	return &sizeBatch{
+		db:     db,
+		batch:  db.backend.NewBatch(),
+		values: make(map[string]int),
+	}
 }
 
-// WriteBatch commits the badger transaction after all the operations are over.
-func (db *DB) WriteBatch(txn *badger.Txn) (err error) {
+// WriteBatch commits the Batch after all the operations are over.
+func (db *DB) WriteBatch(batch Batch) (err error) {
 	db.metrics.WriteBatchCount.Inc()
-	err = txn.Commit()
+	err = batch.Commit()
 	if err != nil {
 		db.metrics.WriteBatchFailCount.Inc()
 		return err
@@ -384,7 +306,7 @@ func (db *DB) WriteBatch(txn *badger.Txn) (err error) {
 	return nil
 }
 
-// Close shuts down the badger DB.
+// Close shuts down the backend.
 func (db *DB) Close() (err error) {
-	return db.bdb.Close()
+	return db.backend.Close()
 }
@@ -58,7 +58,7 @@ func TestDB_persistence(t *testing.T) {
 	defer os.RemoveAll(dir)
 	logger := logging.New(ioutil.Discard, 0)
 
-	db, err := NewDB(dir, logger)
+	db, err := NewDB(dir, nil, logger)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -76,7 +76,7 @@ func TestDB_persistence(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	db2, err := NewDB(dir, logger)
+	db2, err := NewDB(dir, nil, logger)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -104,7 +104,7 @@ func newTestDB(t *testing.T) (db *DB, cleanupFunc func()) {
 		t.Fatal(err)
 	}
 	logger := logging.New(ioutil.Discard, 0)
-	db, err = NewDB(dir, logger)
+	db, err = NewDB(dir, nil, logger)
 	if err != nil {
 		os.RemoveAll(dir)
 		t.Fatal(err)
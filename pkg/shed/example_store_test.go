@@ -52,7 +52,7 @@ type Store struct {
 // automatically.
 func New(path string) (s *Store, err error) {
 	logger := logging.New(ioutil.Discard, 0)
-	db, err := shed.NewDB(path, logger)
+	db, err := shed.NewDB(path, nil, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +158,7 @@ func (s *Store) Put(_ context.Context, ch swarm.Chunk) (err error) {
 // items from them and adding new items as keys of index entries
 // are changed.
 func (s *Store) Get(_ context.Context, addr swarm.Address) (c swarm.Chunk, err error) {
-	batch := s.db.GetBatch(true)
+	batch := s.db.GetBatch()
 
 	// Get the chunk data and storage timestamp.
 	item, err := s.retrievalIndex.Get(shed.Item{
@@ -239,7 +239,7 @@ func (s *Store) CollectGarbage() (err error) {
 	for roundCount := 0; roundCount < maxRounds; roundCount++ {
 		var garbageCount int
 		// New batch for a new cg round.
-		trash := s.db.GetBatch(true)
+		trash := s.db.GetBatch()
 		// Iterate through all index items and break when needed.
 		err = s.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
 			// Remove the chunk.
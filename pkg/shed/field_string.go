@@ -17,7 +17,6 @@
 package shed
 
 import (
-	"github.com/dgraph-io/badger/v2"
 	"github.com/ethersphere/bee/pkg/logging"
 )
 
@@ -61,6 +60,6 @@ func (f StringField) Put(val string) (err error) {
 
 // PutInBatch stores a string in a batch that can be
 // saved later in database.
-func (f StringField) PutInBatch(batch *badger.Txn, val string) (err error) {
-	return batch.Set(f.key, []byte(val))
+func (f StringField) PutInBatch(batch Batch, val string) (err error) {
+	return batch.Put(f.key, []byte(val))
 }
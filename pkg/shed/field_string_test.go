@@ -73,7 +73,7 @@ func TestStringField(t *testing.T) {
 	})
 
 	t.Run("put in batch", func(t *testing.T) {
-		batch := db.GetBatch(true)
+		batch := db.GetBatch()
 		want := "simple string batch value"
 		err = simpleString.PutInBatch(batch, want)
 		if err != nil {
@@ -92,7 +92,7 @@ func TestStringField(t *testing.T) {
 		}
 
 		t.Run("overwrite", func(t *testing.T) {
-			batch := db.GetBatch(true)
+			batch := db.GetBatch()
 			want := "overwritten string batch value"
 			err = simpleString.PutInBatch(batch, want)
 			if err != nil {
@@ -19,7 +19,6 @@ package shed
 import (
 	"encoding/json"
 
-	"github.com/dgraph-io/badger/v2"
 	"github.com/ethersphere/bee/pkg/logging"
 )
 
@@ -67,12 +66,12 @@ func (f StructField) Put(val interface{}) (err error) {
 }
 
 // PutInBatch marshals provided val and puts it into the batch.
-func (f StructField) PutInBatch(batch *badger.Txn, val interface{}) (err error) {
+func (f StructField) PutInBatch(batch Batch, val interface{}) (err error) {
 	b, err := json.Marshal(val)
 	if err != nil {
 		return err
 	}
-	err = batch.Set(f.key, b)
+	err = batch.Put(f.key, b)
 	if err != nil {
 		return err
 	}
@@ -84,7 +84,7 @@ func TestStructField(t *testing.T) {
 	})
 
 	t.Run("put in batch", func(t *testing.T) {
-		batch := db.GetBatch(true)
+		batch := db.GetBatch()
 		want := complexStructure{
 			A: "simple string batch value",
 		}
@@ -106,7 +106,7 @@ func TestStructField(t *testing.T) {
 		}
 
 		t.Run("overwrite", func(t *testing.T) {
-			batch := db.GetBatch(true)
+			batch := db.GetBatch()
 			want := complexStructure{
 				A: "overwritten string batch value",
 			}
@@ -19,7 +19,6 @@ package shed
 import (
 	"encoding/binary"
 
-	"github.com/dgraph-io/badger/v2"
 	"github.com/ethersphere/bee/pkg/logging"
 )
 
@@ -67,8 +66,8 @@ func (f Uint64Field) Put(val uint64) (err error) {
 
 // PutInBatch stores a uint64 value in a batch
 // that can be saved later in the database.
-func (f Uint64Field) PutInBatch(batch *badger.Txn, val uint64) (err error) {
-	return batch.Set(f.key, encodeUint64(val))
+func (f Uint64Field) PutInBatch(batch Batch, val uint64) (err error) {
+	return batch.Put(f.key, encodeUint64(val))
 }
 
 // Inc increments a uint64 value in the database.
@@ -85,7 +84,7 @@ func (f Uint64Field) Inc() (val uint64, err error) {
 // IncInBatch increments a uint64 value in the batch
 // by retreiving a value from the database, not the same batch.
 // This operation is not goroutine save.
-func (f Uint64Field) IncInBatch(batch *badger.Txn) (val uint64, err error) {
+func (f Uint64Field) IncInBatch(batch Batch) (val uint64, err error) {
 	val, err = f.Get()
 	if err != nil {
 		return 0, err
@@ -116,7 +115,7 @@ func (f Uint64Field) Dec() (val uint64, err error) {
 // by retreiving a value from the database, not the same batch.
 // This operation is not goroutine save.
 // The field is protected from overflow to a negative value.
-func (f Uint64Field) DecInBatch(batch *badger.Txn) (val uint64, err error) {
+func (f Uint64Field) DecInBatch(batch Batch) (val uint64, err error) {
 	val, err = f.Get()
 	if err != nil {
 		return 0, err
@@ -73,7 +73,7 @@ func TestUint64Field(t *testing.T) {
 	})
 
 	t.Run("put in batch", func(t *testing.T) {
-		batch := db.GetBatch(true)
+		batch := db.GetBatch()
 		var want uint64 = 42
 		err = counter.PutInBatch(batch, want)
 		if err != nil {
@@ -92,7 +92,7 @@ func TestUint64Field(t *testing.T) {
 		}
 
 		t.Run("overwrite", func(t *testing.T) {
-			batch := db.GetBatch(true)
+			batch := db.GetBatch()
 			var want uint64 = 84
 			err = counter.PutInBatch(batch, want)
 			if err != nil {
@@ -154,7 +154,7 @@ func TestUint64Field_IncInBatch(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	batch := db.GetBatch(true)
+	batch := db.GetBatch()
 	var want uint64 = 1
 	got, err := counter.IncInBatch(batch)
 	if err != nil {
@@ -175,7 +175,7 @@ func TestUint64Field_IncInBatch(t *testing.T) {
 		t.Errorf("got uint64 %v, want %v", got, want)
 	}
 
-	batch2 := db.GetBatch(true)
+	batch2 := db.GetBatch()
 	want = 2
 	got, err = counter.IncInBatch(batch2)
 	if err != nil {
@@ -245,7 +245,7 @@ func TestUint64Field_DecInBatch(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	batch := db.GetBatch(true)
+	batch := db.GetBatch()
 	var want uint64
 	got, err := counter.DecInBatch(batch)
 	if err != nil {
@@ -266,7 +266,7 @@ func TestUint64Field_DecInBatch(t *testing.T) {
 		t.Errorf("got uint64 %v, want %v", got, want)
 	}
 
-	batch2 := db.GetBatch(true)
+	batch2 := db.GetBatch()
 	want = 42
 	err = counter.PutInBatch(batch2, want)
 	if err != nil {
@@ -284,7 +284,7 @@ func TestUint64Field_DecInBatch(t *testing.T) {
 		t.Errorf("got uint64 %v, want %v", got, want)
 	}
 
-	batch3 := db.GetBatch(true)
+	batch3 := db.GetBatch()
 	want = 41
 	got, err = counter.DecInBatch(batch3)
 	if err != nil {
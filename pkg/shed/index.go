@@ -19,7 +19,6 @@ package shed
 import (
 	"bytes"
 
-	"github.com/dgraph-io/badger/v2"
 	"github.com/ethersphere/bee/pkg/logging"
 )
 
@@ -43,6 +42,7 @@ type Item struct {
 	BinID           uint64
 	PinCounter      uint64 // maintains the no of time a chunk is pinned
 	Tag             uint32
+	Stamp           []byte // marshaled postage stamp
 }
 
 // Merge is a helper method to construct a new
@@ -70,6 +70,9 @@ func (i Item) Merge(i2 Item) (new Item) {
 	if i.Tag == 0 {
 		i.Tag = i2.Tag
 	}
+	if i.Stamp == nil {
+		i.Stamp = i2.Stamp
+	}
 	return i
 }
 
@@ -108,6 +111,9 @@ func (db *DB) NewIndex(name string, funcs IndexFuncs) (f Index, err error) {
 		return f, err
 	}
 	prefix := []byte{id}
+	if err := db.initIndexSize(id); err != nil {
+		return f, err
+	}
 	return Index{
 		db:     db,
 		logger: db.logger,
@@ -159,32 +165,25 @@ func (f Index) Get(keyFields Item) (out Item, err error) {
 // key set. The passed slice items will be changed so that they
 // contain data from the index values. No new slice is allocated.
 func (f Index) Fill(items []Item) (err error) {
-	txn := f.db.GetBatch(false)
-	defer txn.Discard()
-	for i, item := range items {
-		key, err := f.encodeKeyFunc(item)
-		if err != nil {
-			f.logger.Debugf("keyfields encoding error in Fill. Error: %s", err.Error())
-			return err
-		}
-		badgerItem, err := txn.Get(key)
-		if err != nil {
-			if err == badger.ErrKeyNotFound {
-				return ErrNotFound
+	return f.db.View(func(r Reader) error {
+		for i, item := range items {
+			key, err := f.encodeKeyFunc(item)
+			if err != nil {
+				f.logger.Debugf("keyfields encoding error in Fill. Error: %s", err.Error())
+				return err
 			}
-			return err
-		}
-		var decodedItem Item
-		err = badgerItem.Value(func(val []byte) error {
-			decodedItem, err = f.decodeValueFunc(item, val)
-			return err
-		})
-		if err != nil {
-			return err
+			value, err := r.Get(key)
+			if err != nil {
+				return err
+			}
+			decodedItem, err := f.decodeValueFunc(item, value)
+			if err != nil {
+				return err
+			}
+			items[i] = decodedItem.Merge(item)
 		}
-		items[i] = decodedItem.Merge(item)
-	}
-	return nil
+		return nil
+	})
 }
 
 // Has accepts key fields represented as Item to check
@@ -202,30 +201,21 @@ func (f Index) Has(keyFields Item) (bool, error) {
 // there this Item's encoded key is stored in the index for each of them.
 func (f Index) HasMulti(items ...Item) ([]bool, error) {
 	have := make([]bool, len(items))
-
-	txn := f.db.GetBatch(false)
-	defer txn.Discard()
-
-	opts := badger.DefaultIteratorOptions
-	opts.PrefetchValues = false
-	it := txn.NewIterator(opts)
-	defer it.Close()
-
-	for i, keyFields := range items {
-		key, err := f.encodeKeyFunc(keyFields)
-		if err != nil {
-			return nil, err
-		}
-		it.Seek(key)
-
-		if !it.Valid() {
-			have[i] = false
-			continue
-		}
-
-		if bytes.Equal(key, it.Item().Key()) {
-			have[i] = true
+	err := f.db.View(func(r Reader) error {
+		for i, keyFields := range items {
+			key, err := f.encodeKeyFunc(keyFields)
+			if err != nil {
+				return err
+			}
+			have[i], err = r.Has(key)
+			if err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return have, nil
 }
@@ -247,7 +237,7 @@ func (f Index) Put(i Item) (err error) {
 // PutInBatch is the same as Put method, but it just
 // saves the key/value pair to the batch instead
 // directly to the database.
-func (f Index) PutInBatch(batch *badger.Txn, i Item) (err error) {
+func (f Index) PutInBatch(batch Batch, i Item) (err error) {
 	key, err := f.encodeKeyFunc(i)
 	if err != nil {
 		return err
@@ -256,7 +246,7 @@ func (f Index) PutInBatch(batch *badger.Txn, i Item) (err error) {
 	if err != nil {
 		return err
 	}
-	return batch.Set(key, value)
+	return batch.Put(key, value)
 }
 
 // Delete accepts Item to remove a key/value pair
@@ -271,7 +261,7 @@ func (f Index) Delete(keyFields Item) (err error) {
 
 // DeleteInBatch is the same as Delete just the operation
 // is performed on the batch instead on the database.
-func (f Index) DeleteInBatch(batch *badger.Txn, keyFields Item) (err error) {
+func (f Index) DeleteInBatch(batch Batch, keyFields Item) (err error) {
 	key, err := f.encodeKeyFunc(keyFields)
 	if err != nil {
 		return err
@@ -319,7 +309,7 @@ func (f Index) Iterate(fn IndexIterFunc, options *IterateOptions) (err error) {
 	err = f.db.Iterate(startKey, options.SkipStartFromItem, func(key []byte, value []byte) (stop bool, err error) {
 		item, err := f.itemFromKeyValue(key, value, prefix)
 		if err != nil {
-			if err == badger.ErrKeyNotFound {
+			if err == ErrNotFound {
 				return true, nil
 			}
 			return false, err
@@ -344,18 +334,18 @@ func (f Index) First(prefix []byte) (i Item, err error) {
 
 // itemFromKeyValue returns the Item from the current iterator position.
 // If the complete encoded key does not start with totalPrefix,
-// badger.ErrNotFound is returned. Value for totalPrefix must start with
+// ErrNotFound is returned. Value for totalPrefix must start with
 // Index prefix.
 func (f Index) itemFromKeyValue(key []byte, value []byte, totalPrefix []byte) (i Item, err error) {
 	if !bytes.HasPrefix(key, totalPrefix) {
-		return i, badger.ErrKeyNotFound
+		return i, ErrNotFound
 	}
-	// create a copy of key byte slice not to share badger underlaying slice array
+	// create a copy of key byte slice not to share backend underlaying slice array
 	keyItem, err := f.decodeKeyFunc(append([]byte(nil), key...))
 	if err != nil {
 		return i, err
 	}
-	// create a copy of value byte slice not to share badger underlaying slice array
+	// create a copy of value byte slice not to share backend underlaying slice array
 	valueItem, err := f.decodeValueFunc(keyItem, append([]byte(nil), value...))
 	if err != nil {
 		return i, err
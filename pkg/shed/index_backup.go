@@ -0,0 +1,181 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// restoreDeleteBatchSize is the maximal number of keys of existing
+// items that Restore holds in memory while it deletes them.
+var restoreDeleteBatchSize = 10000
+
+// Backup writes all encoded keys and values of the Index to the writer
+// and returns the number of written items. Keys are written without the
+// Index prefix, so that the backup can be restored even if the prefix
+// of the Index changes.
+func (f Index) Backup(w io.Writer) (count int, err error) {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+	write := func(b []byte) error {
+		n := binary.PutUvarint(buf, uint64(len(b)))
+		if _, err := bw.Write(buf[:n]); err != nil {
+			return err
+		}
+		_, err := bw.Write(b)
+		return err
+	}
+	err = f.iterateRaw(func(key, value []byte) error {
+		if err := write(key[len(f.prefix):]); err != nil {
+			return err
+		}
+		if err := write(value); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+// Restore replaces all items of the Index with the items read from the
+// backup created by the Backup method. It returns the number of restored
+// items. Restore is not atomic, as the backup may not fit into a single
+// transaction, and an interrupted restore should be run again.
+func (f Index) Restore(r io.Reader) (count int, err error) {
+	if err := f.deleteAll(); err != nil {
+		return 0, err
+	}
+
+	w := f.db.newLargeBatch()
+	br := bufio.NewReader(r)
+	read := func() ([]byte, error) {
+		l, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, l)
+		_, err = io.ReadFull(br, b)
+		return b, err
+	}
+	for {
+		key, err := read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			w.discard()
+			return count, fmt.Errorf("read key: %w", err)
+		}
+		value, err := read()
+		if err != nil {
+			w.discard()
+			return count, fmt.Errorf("read value: %w", err)
+		}
+		if err := w.set(append(append([]byte(nil), f.prefix...), key...), value); err != nil {
+			w.discard()
+			return count, err
+		}
+		count++
+	}
+	return count, w.commit()
+}
+
+// deleteAll deletes all items of the Index in batches of at most
+// restoreDeleteBatchSize keys.
+func (f Index) deleteAll() error {
+	keys := make([][]byte, 0, restoreDeleteBatchSize)
+	for {
+		keys = keys[:0]
+		err := f.db.Iterate(f.prefix, false, func(key, _ []byte) (stop bool, err error) {
+			if !bytes.HasPrefix(key, f.prefix) || len(keys) >= restoreDeleteBatchSize {
+				return true, nil
+			}
+			keys = append(keys, append([]byte(nil), key...))
+			return false, nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		w := f.db.newLargeBatch()
+		for _, key := range keys {
+			if err := w.delete(key); err != nil {
+				w.discard()
+				return err
+			}
+		}
+		if err := w.commit(); err != nil {
+			return err
+		}
+	}
+}
+
+// iterateRaw calls the function for every encoded key and value of the Index.
+func (f Index) iterateRaw(fn func(key, value []byte) error) error {
+	return f.db.Iterate(f.prefix, false, func(key, value []byte) (stop bool, err error) {
+		if !bytes.HasPrefix(key, f.prefix) {
+			return true, nil
+		}
+		return false, fn(key, value)
+	})
+}
+
+// largeBatch writes changes that may not fit into a single Batch
+// by committing the batch when it becomes too big and continuing
+// with a new one.
+type largeBatch struct {
+	db    *DB
+	batch Batch
+}
+
+func (db *DB) newLargeBatch() *largeBatch {
+	return &largeBatch{
+		db:    db,
+		batch: db.GetBatch(),
+	}
+}
+
+func (b *largeBatch) set(key, value []byte) error {
+	return b.apply(func(batch Batch) error {
+		return batch.Put(key, value)
+	})
+}
+
+func (b *largeBatch) delete(key []byte) error {
+	return b.apply(func(batch Batch) error {
+		return batch.Delete(key)
+	})
+}
+
+func (b *largeBatch) apply(f func(batch Batch) error) error {
+	err := f(b.batch)
+	if !errors.Is(err, ErrBatchTooBig) {
+		return err
+	}
+	if err := b.db.WriteBatch(b.batch); err != nil {
+		return err
+	}
+	b.batch = b.db.GetBatch()
+	return f(b.batch)
+}
+
+func (b *largeBatch) commit() error {
+	return b.db.WriteBatch(b.batch)
+}
+
+func (b *largeBatch) discard() {
+	b.batch.Discard()
+}
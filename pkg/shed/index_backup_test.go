@@ -0,0 +1,101 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// TestIndex_BackupRestore validates that Restore replaces index items
+// with the ones written by Backup and that other indexes are not changed.
+func TestIndex_BackupRestore(t *testing.T) {
+	// existing items are deleted in more than one batch
+	defer func(s int) { restoreDeleteBatchSize = s }(restoreDeleteBatchSize)
+	restoreDeleteBatchSize = 3
+
+	db, cleanupFunc := newTestDB(t)
+	defer cleanupFunc()
+
+	index, err := db.NewIndex("retrieval", retrievalIndexFuncs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := db.NewIndex("other", retrievalIndexFuncs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := make([]Item, 0, 10)
+	for i := 0; i < 10; i++ {
+		item := Item{
+			Address:        []byte(fmt.Sprintf("address-%v", i)),
+			Data:           []byte(fmt.Sprintf("data-%v", i)),
+			StoreTimestamp: int64(i + 1),
+		}
+		if err := index.Put(item); err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+	otherItem := Item{
+		Address: []byte("other-address"),
+		Data:    []byte("other-data"),
+	}
+	if err := other.Put(otherItem); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	count, err := index.Backup(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(items) {
+		t.Fatalf("got backup count %v, want %v", count, len(items))
+	}
+
+	// change the index after the backup
+	if err := index.Delete(items[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Put(Item{
+		Address: []byte("new-address"),
+		Data:    []byte("new-data"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err = index.Restore(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(items) {
+		t.Fatalf("got restore count %v, want %v", count, len(items))
+	}
+
+	var i int
+	err = index.Iterate(func(item Item) (stop bool, err error) {
+		if i >= len(items) {
+			return true, fmt.Errorf("got unexpected item %s", item.Address)
+		}
+		checkItem(t, item, items[i])
+		i++
+		return false, nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != len(items) {
+		t.Errorf("got %v items, want %v", i, len(items))
+	}
+
+	got, err := other.Get(otherItem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkItem(t, got, otherItem)
+}
@@ -0,0 +1,161 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// keyPrefixIndexSize is the key prefix for persisted index sizes.
+// The index prefix byte is appended to construct the key. It shares
+// the first byte with keySchema as no other keys start with it.
+var keyPrefixIndexSize = []byte{0, 's'}
+
+// Size returns the number of bytes that keys and values
+// of all items in the index take.
+func (f Index) Size() (size uint64) {
+	return f.db.indexSize(f.prefix[0])
+}
+
+// indexSizeKey returns the key under which the size
+// of the index with the prefix is stored.
+func indexSizeKey(prefix byte) []byte {
+	return append(append(make([]byte, 0, len(keyPrefixIndexSize)+1), keyPrefixIndexSize...), prefix)
+}
+
+// isIndexKey returns true for keys of index items.
+func isIndexKey(key []byte) bool {
+	return len(key) > 0 && key[0] >= keyPrefixIndexStart
+}
+
+// indexSize returns the current size of the index with the prefix.
+func (db *DB) indexSize(prefix byte) uint64 {
+	db.sizesMu.Lock()
+	defer db.sizesMu.Unlock()
+
+	return db.sizes[prefix]
+}
+
+// initIndexSize loads the persisted size of the index with the
+// prefix. If it is not stored, the size is computed by iterating
+// over all index items and is persisted.
+func (db *DB) initIndexSize(prefix byte) (err error) {
+	db.sizesMu.Lock()
+	defer db.sizesMu.Unlock()
+
+	key := indexSizeKey(prefix)
+	value, err := db.backend.Get(key)
+	if err == nil {
+		db.sizes[prefix] = binary.BigEndian.Uint64(value)
+		return nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	var size uint64
+	err = db.backend.Iterate(RangeOptions{Start: []byte{prefix}}, func(k, v []byte) (stop bool, err error) {
+		if k[0] != prefix {
+			return true, nil
+		}
+		size += uint64(len(k) + len(v))
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.backend.Put(key, encodeUint64(size)); err != nil {
+		return err
+	}
+	db.sizes[prefix] = size
+	return nil
+}
+
+// sizeBatch is a Batch that tracks the changes of index sizes
+// and persists the new sizes together with other changes.
+type sizeBatch struct {
+	db    *DB
+	batch Batch
+	// values holds value lengths of changed index
+	// keys, or -1 if the key is deleted
+	values map[string]int
+}
+
+func (b *sizeBatch) Put(key, value []byte) (err error) {
+	if err := b.batch.Put(key, value); err != nil {
+		return err
+	}
+	if isIndexKey(key) {
+		b.values[string(key)] = len(value)
+	}
+	return nil
+}
+
+func (b *sizeBatch) Delete(key []byte) (err error) {
+	if err := b.batch.Delete(key); err != nil {
+		return err
+	}
+	if isIndexKey(key) {
+		b.values[string(key)] = -1
+	}
+	return nil
+}
+
+// Commit computes size changes against the stored values and writes
+// them in the same batch. Commits are serialized so that the sizes
+// are exact.
+func (b *sizeBatch) Commit() (err error) {
+	if len(b.values) == 0 {
+		return b.batch.Commit()
+	}
+
+	b.db.sizesMu.Lock()
+	defer b.db.sizesMu.Unlock()
+
+	deltas := make(map[byte]int64)
+	for k, length := range b.values {
+		key := []byte(k)
+		var delta int64
+		if length >= 0 {
+			delta += int64(len(key) + length)
+		}
+		stored, err := b.db.backend.ValueSize(key)
+		switch {
+		case err == nil:
+			delta -= int64(len(key) + stored)
+		case !errors.Is(err, ErrNotFound):
+			b.batch.Discard()
+			return err
+		}
+		deltas[key[0]] += delta
+	}
+
+	sizes := make(map[byte]uint64, len(deltas))
+	for prefix, delta := range deltas {
+		size := b.db.sizes[prefix]
+		if delta < 0 && uint64(-delta) > size {
+			size = 0
+		} else {
+			size = uint64(int64(size) + delta)
+		}
+		if err := b.batch.Put(indexSizeKey(prefix), encodeUint64(size)); err != nil {
+			b.batch.Discard()
+			return err
+		}
+		sizes[prefix] = size
+	}
+	if err := b.batch.Commit(); err != nil {
+		return err
+	}
+	for prefix, size := range sizes {
+		b.db.sizes[prefix] = size
+	}
+	return nil
+}
+
+func (b *sizeBatch) Discard() {
+	b.batch.Discard()
+}
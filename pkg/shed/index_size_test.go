@@ -0,0 +1,142 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+)
+
+// TestIndex_Size validates that index sizes are tracked for all
+// write operations and that they are persisted across restarts.
+func TestIndex_Size(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	for _, name := range testBackends {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "shed-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			db, err := NewDB(dir, &Options{Backend: name}, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			index, err := db.NewIndex("retrieval", retrievalIndexFuncs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := db.NewIndex("other", retrievalIndexFuncs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// key is prefix and address, value is timestamp and data
+			itemSize := func(address string, dataLen int) uint64 {
+				return uint64(1 + len(address) + 8 + dataLen)
+			}
+			checkSize := func(t *testing.T, index Index, want uint64) {
+				t.Helper()
+				if got := index.Size(); got != want {
+					t.Errorf("got size %v, want %v", got, want)
+				}
+			}
+
+			checkSize(t, index, 0)
+
+			// large values are stored in badger value log
+			if err := index.Put(Item{Address: []byte("one"), Data: make([]byte, 4096)}); err != nil {
+				t.Fatal(err)
+			}
+			want := itemSize("one", 4096)
+			checkSize(t, index, want)
+
+			// overwrite
+			if err := index.Put(Item{Address: []byte("one"), Data: make([]byte, 10)}); err != nil {
+				t.Fatal(err)
+			}
+			want = itemSize("one", 10)
+			checkSize(t, index, want)
+
+			// batch with multiple changes of the same key
+			batch := db.GetBatch()
+			for _, item := range []Item{
+				{Address: []byte("two"), Data: make([]byte, 100)},
+				{Address: []byte("two"), Data: make([]byte, 20)},
+				{Address: []byte("three"), Data: make([]byte, 30)},
+			} {
+				if err := index.PutInBatch(batch, item); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := index.DeleteInBatch(batch, Item{Address: []byte("one")}); err != nil {
+				t.Fatal(err)
+			}
+			if err := other.PutInBatch(batch, Item{Address: []byte("four")}); err != nil {
+				t.Fatal(err)
+			}
+			checkSize(t, index, want)
+			if err := db.WriteBatch(batch); err != nil {
+				t.Fatal(err)
+			}
+			want = itemSize("two", 20) + itemSize("three", 30)
+			checkSize(t, index, want)
+			checkSize(t, other, itemSize("four", 0))
+
+			// discarded batch
+			batch = db.GetBatch()
+			if err := index.DeleteInBatch(batch, Item{Address: []byte("two")}); err != nil {
+				t.Fatal(err)
+			}
+			batch.Discard()
+			checkSize(t, index, want)
+
+			// delete of a missing item
+			if err := index.Delete(Item{Address: []byte("missing")}); err != nil {
+				t.Fatal(err)
+			}
+			checkSize(t, index, want)
+
+			if err := index.Delete(Item{Address: []byte("three")}); err != nil {
+				t.Fatal(err)
+			}
+			want = itemSize("two", 20)
+			checkSize(t, index, want)
+
+			if err := db.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// the size is persisted
+			db, err = NewDB(dir, &Options{Backend: name}, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			index, err = db.NewIndex("retrieval", retrievalIndexFuncs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkSize(t, index, want)
+
+			// the size is computed if it is not persisted
+			if err := db.backend.Delete(indexSizeKey(index.prefix[0])); err != nil {
+				t.Fatal(err)
+			}
+			index, err = db.NewIndex("retrieval", retrievalIndexFuncs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkSize(t, index, want)
+			if err := db.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -104,7 +104,7 @@ func TestIndex(t *testing.T) {
 			StoreTimestamp: time.Now().UTC().UnixNano(),
 		}
 
-		batch := db.GetBatch(true)
+		batch := db.GetBatch()
 		err = index.PutInBatch(batch, want)
 		if err != nil {
 			t.Fatal(err)
@@ -128,7 +128,7 @@ func TestIndex(t *testing.T) {
 				StoreTimestamp: time.Now().UTC().UnixNano(),
 			}
 
-			batch := db.GetBatch(true)
+			batch := db.GetBatch()
 			err = index.PutInBatch(batch, want)
 			if err != nil {
 				t.Fatal(err)
@@ -150,7 +150,7 @@ func TestIndex(t *testing.T) {
 	t.Run("put in batch twice", func(t *testing.T) {
 		// ensure that the last item of items with the same db keys
 		// is actually saved
-		batch := db.GetBatch(true)
+		batch := db.GetBatch()
 		address := []byte("put-in-batch-twice-hash")
 
 		// put the first item
@@ -276,7 +276,7 @@ func TestIndex(t *testing.T) {
 		}
 		checkItem(t, got, want)
 
-		batch := db.GetBatch(true)
+		batch := db.GetBatch()
 		err = index.DeleteInBatch(batch, Item{
 			Address: want.Address,
 		})
@@ -393,7 +393,7 @@ func TestIndex_Iterate(t *testing.T) {
 			Data:    []byte("data1"),
 		},
 	}
-	batch := db.GetBatch(true)
+	batch := db.GetBatch()
 	for _, i := range items {
 		err = index.PutInBatch(batch, i)
 		if err != nil {
@@ -567,7 +567,7 @@ func TestIndex_Iterate_withPrefix(t *testing.T) {
 		{Address: []byte("want-hash-09"), Data: []byte("data89")},
 		{Address: []byte("skip-hash-10"), Data: []byte("data90")},
 	}
-	batch := db.GetBatch(true)
+	batch := db.GetBatch()
 	for _, i := range allItems {
 		err = index.PutInBatch(batch, i)
 		if err != nil {
@@ -763,7 +763,7 @@ func TestIndex_count(t *testing.T) {
 			Data:    []byte("data1"),
 		},
 	}
-	batch := db.GetBatch(true)
+	batch := db.GetBatch()
 	for _, i := range items {
 		err = index.PutInBatch(batch, i)
 		if err != nil {
@@ -936,7 +936,7 @@ func TestIndex_firstAndLast(t *testing.T) {
 		return bytes.Compare(addrs[i], addrs[j]) == -1
 	})
 
-	batch := db.GetBatch(true)
+	batch := db.GetBatch()
 	for _, addr := range addrs {
 		err = index.PutInBatch(batch, Item{
 			Address: addr,
@@ -1087,7 +1087,7 @@ func TestIndex_HasMulti(t *testing.T) {
 		Data:    []byte("data0"),
 	}
 
-	batch := db.GetBatch(true)
+	batch := db.GetBatch()
 	for _, i := range items {
 		err = index.PutInBatch(batch, i)
 		if err != nil {
@@ -19,7 +19,6 @@ package shed
 import (
 	"encoding/binary"
 
-	"github.com/dgraph-io/badger/v2"
 	"github.com/ethersphere/bee/pkg/logging"
 )
 
@@ -66,8 +65,8 @@ func (f Uint64Vector) Put(i, val uint64) (err error) {
 
 // PutInBatch stores a uint64 value at index i in a batch
 // that can be saved later in the database.
-func (f Uint64Vector) PutInBatch(batch *badger.Txn, i, val uint64) (err error) {
-	return batch.Set(f.indexKey(i), encodeUint64(val))
+func (f Uint64Vector) PutInBatch(batch Batch, i, val uint64) (err error) {
+	return batch.Put(f.indexKey(i), encodeUint64(val))
 }
 
 // Inc increments a uint64 value in the database.
@@ -84,7 +83,7 @@ func (f Uint64Vector) Inc(i uint64) (val uint64, err error) {
 // IncInBatch increments a uint64 value at index i in the batch
 // by retreiving a value from the database, not the same batch.
 // This operation is not goroutine safe.
-func (f Uint64Vector) IncInBatch(batch *badger.Txn, i uint64) (val uint64, err error) {
+func (f Uint64Vector) IncInBatch(batch Batch, i uint64) (val uint64, err error) {
 	val, err = f.Get(i)
 	if err != nil {
 		return 0, err
@@ -120,7 +119,7 @@ func (f Uint64Vector) Dec(i uint64) (val uint64, err error) {
 // by retreiving a value from the database, not the same batch.
 // This operation is not goroutine safe.
 // The field is protected from overflow to a negative value.
-func (f Uint64Vector) DecInBatch(batch *badger.Txn, i uint64) (val uint64, err error) {
+func (f Uint64Vector) DecInBatch(batch Batch, i uint64) (val uint64, err error) {
 	val, err = f.Get(i)
 	if err != nil {
 		return 0, err
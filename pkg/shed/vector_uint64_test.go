@@ -76,7 +76,7 @@ func TestUint64Vector(t *testing.T) {
 
 	t.Run("put in batch", func(t *testing.T) {
 		for _, index := range []uint64{0, 1, 2, 3, 5, 10} {
-			batch := db.GetBatch(true)
+			batch := db.GetBatch()
 			var want uint64 = 43 + index
 			err = bins.PutInBatch(batch, index, want)
 			if err != nil {
@@ -95,7 +95,7 @@ func TestUint64Vector(t *testing.T) {
 			}
 
 			t.Run("overwrite", func(t *testing.T) {
-				batch := db.GetBatch(true)
+				batch := db.GetBatch()
 				var want uint64 = 85 + index
 				err = bins.PutInBatch(batch, index, want)
 				if err != nil {
@@ -161,7 +161,7 @@ func TestUint64Vector_IncInBatch(t *testing.T) {
 	}
 
 	for _, index := range []uint64{0, 1, 2, 3, 5, 10} {
-		batch := db.GetBatch(true)
+		batch := db.GetBatch()
 		var want uint64 = 1
 		got, err := bins.IncInBatch(batch, index)
 		if err != nil {
@@ -182,7 +182,7 @@ func TestUint64Vector_IncInBatch(t *testing.T) {
 			t.Errorf("got %v uint64 %v, want %v", index, got, want)
 		}
 
-		batch2 := db.GetBatch(true)
+		batch2 := db.GetBatch()
 		want = 2
 		got, err = bins.IncInBatch(batch2, index)
 		if err != nil {
@@ -256,7 +256,7 @@ func TestUint64Vector_DecInBatch(t *testing.T) {
 	}
 
 	for _, index := range []uint64{0, 1, 2, 3, 5, 10} {
-		batch := db.GetBatch(true)
+		batch := db.GetBatch()
 		var want uint64
 		got, err := bins.DecInBatch(batch, index)
 		if err != nil {
@@ -277,7 +277,7 @@ func TestUint64Vector_DecInBatch(t *testing.T) {
 			t.Errorf("got %v uint64 %v, want %v", index, got, want)
 		}
 
-		batch2 := db.GetBatch(true)
+		batch2 := db.GetBatch()
 		want = 42 + index
 		err = bins.PutInBatch(batch2, index, want)
 		if err != nil {
@@ -295,7 +295,7 @@ func TestUint64Vector_DecInBatch(t *testing.T) {
 			t.Errorf("got %v uint64 %v, want %v", index, got, want)
 		}
 
-		batch3 := db.GetBatch(true)
+		batch3 := db.GetBatch()
 		want = 41 + index
 		got, err = bins.DecInBatch(batch3, index)
 		if err != nil {
@@ -0,0 +1,419 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cache provides a bounded in-memory cache of chunks in front
+// of a storage.Storer. Recently retrieved chunks are served from memory,
+// and access times of served chunks are written to the underlying
+// store periodically in batches.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ storage.Storer = (*Cache)(nil)
+
+const (
+	// defaultCapacityBytes is the cache size when
+	// it is not set in Options.
+	defaultCapacityBytes = 64 * 1024 * 1024
+	// defaultAccessFlushInterval is the period of writing
+	// access times of served chunks to the underlying store.
+	defaultAccessFlushInterval = time.Second
+	// accessFlushSize is the number of pending access updates
+	// that triggers writing them before the flush interval.
+	accessFlushSize = 1000
+)
+
+// accessUpdater is implemented by stores that can update
+// access times of chunks which are not retrieved with
+// ModeGetRequest, like localstore.
+type accessUpdater interface {
+	UpdateAccess(addrs ...swarm.Address) error
+}
+
+// removeNotifier is implemented by stores that notify
+// about chunks removed by garbage collection, like localstore.
+type removeNotifier interface {
+	SetRemoveHandler(h func(addrs ...swarm.Address))
+}
+
+// Cache wraps a storage.Storer and keeps recently retrieved
+// chunks in memory, up to the configured number of bytes.
+type Cache struct {
+	storage.Storer
+
+	capacity uint64
+
+	mu    sync.Mutex
+	size  uint64                   // bytes of cached chunks
+	lru   *list.List               // least recently used chunks are at the back
+	items map[string]*list.Element // chunk address to the lru element
+	// removals is incremented on every invalidation to prevent
+	// caching chunks retrieved before they were removed
+	removals uint64
+
+	accessUpdater       accessUpdater // nil if the store does not support it
+	accessMu            sync.Mutex
+	accessPending       map[string]swarm.Address
+	accessFlushInterval time.Duration
+	accessFlushTrigger  chan struct{}
+
+	hits   uint64
+	misses uint64
+
+	quit      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+
+	metrics metrics
+	logger  logging.Logger
+}
+
+// Options defines optional parameters for the Cache.
+type Options struct {
+	// CapacityBytes is the maximal size of cached chunk data.
+	CapacityBytes uint64
+	// AccessFlushInterval is the period of writing access
+	// times of chunks served from the cache.
+	AccessFlushInterval time.Duration
+}
+
+// New returns a Cache in front of the provided storer. If the storer
+// supports access updates and remove notifications, like localstore,
+// chunks served from the cache are kept fresh for its garbage
+// collection, and chunks removed by it are invalidated.
+func New(s storage.Storer, o *Options, logger logging.Logger) *Cache {
+	if o == nil {
+		o = new(Options)
+	}
+	c := &Cache{
+		Storer:              s,
+		capacity:            o.CapacityBytes,
+		lru:                 list.New(),
+		items:               make(map[string]*list.Element),
+		accessPending:       make(map[string]swarm.Address),
+		accessFlushInterval: o.AccessFlushInterval,
+		accessFlushTrigger:  make(chan struct{}, 1),
+		quit:                make(chan struct{}),
+		metrics:             newMetrics(),
+		logger:              logger,
+	}
+	if c.capacity == 0 {
+		c.capacity = defaultCapacityBytes
+	}
+	if c.accessFlushInterval <= 0 {
+		c.accessFlushInterval = defaultAccessFlushInterval
+	}
+	c.metrics.CapacityBytes.Set(float64(c.capacity))
+
+	if u, ok := s.(accessUpdater); ok {
+		c.accessUpdater = u
+		c.wg.Add(1)
+		go c.accessFlushWorker()
+	}
+	if n, ok := s.(removeNotifier); ok {
+		n.SetRemoveHandler(c.remove)
+	}
+	return c
+}
+
+// Get returns a chunk from the cache or from the underlying store,
+// caching it for subsequent calls. Pinned chunks are always
+// retrieved from the underlying store, as they carry a pin counter.
+func (c *Cache) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (ch swarm.Chunk, err error) {
+	if !cacheable(mode) {
+		return c.Storer.Get(ctx, mode, addr)
+	}
+	if ch, ok := c.get(addr); ok {
+		c.hit(1)
+		if mode == storage.ModeGetRequest {
+			c.access(addr)
+		}
+		return ch, nil
+	}
+	c.miss(1)
+
+	removals := c.removalsCount()
+	ch, err = c.Storer.Get(ctx, c.storeMode(mode), addr)
+	if err != nil {
+		return nil, err
+	}
+	if mode == storage.ModeGetRequest {
+		c.access(addr)
+	}
+	c.add(removals, ch)
+	return ch, nil
+}
+
+// GetMulti returns chunks from the cache and retrieves the ones that
+// are not cached from the underlying store in a single call.
+func (c *Cache) GetMulti(ctx context.Context, mode storage.ModeGet, addrs ...swarm.Address) (chunks []swarm.Chunk, err error) {
+	if !cacheable(mode) {
+		return c.Storer.GetMulti(ctx, mode, addrs...)
+	}
+	chunks = make([]swarm.Chunk, len(addrs))
+	var missing []swarm.Address
+	var missingIndexes []int
+	for i, addr := range addrs {
+		if ch, ok := c.get(addr); ok {
+			chunks[i] = ch
+			continue
+		}
+		missing = append(missing, addr)
+		missingIndexes = append(missingIndexes, i)
+	}
+	c.hit(len(addrs) - len(missing))
+	c.miss(len(missing))
+
+	if len(missing) > 0 {
+		removals := c.removalsCount()
+		got, err := c.Storer.GetMulti(ctx, c.storeMode(mode), missing...)
+		if err != nil {
+			return nil, err
+		}
+		for i, ch := range got {
+			chunks[missingIndexes[i]] = ch
+		}
+		c.add(removals, got...)
+	}
+	if mode == storage.ModeGetRequest {
+		c.access(addrs...)
+	}
+	return chunks, nil
+}
+
+// Has reports if the chunk is in the cache or in the underlying store.
+func (c *Cache) Has(ctx context.Context, addr swarm.Address) (yes bool, err error) {
+	if c.has(addr) {
+		return true, nil
+	}
+	return c.Storer.Has(ctx, addr)
+}
+
+// Set updates the underlying store, invalidating
+// cached chunks when they are removed.
+func (c *Cache) Set(ctx context.Context, mode storage.ModeSet, addrs ...swarm.Address) (err error) {
+	if mode == storage.ModeSetRemove {
+		defer c.remove(addrs...)
+	}
+	return c.Storer.Set(ctx, mode, addrs...)
+}
+
+// Close writes pending access updates
+// and closes the underlying store.
+func (c *Cache) Close() (err error) {
+	c.closeOnce.Do(func() {
+		close(c.quit)
+	})
+	c.wg.Wait()
+	if c.accessUpdater != nil {
+		c.flushAccess()
+	}
+	return c.Storer.Close()
+}
+
+// cacheable reports if chunks retrieved with the mode can be cached.
+func cacheable(mode storage.ModeGet) bool {
+	switch mode {
+	case storage.ModeGetRequest, storage.ModeGetSync, storage.ModeGetLookup:
+		return true
+	}
+	return false
+}
+
+// storeMode returns the mode with which chunks are retrieved from the
+// underlying store. Access times are updated by the cache in batches,
+// if the store supports it.
+func (c *Cache) storeMode(mode storage.ModeGet) storage.ModeGet {
+	if mode == storage.ModeGetRequest && c.accessUpdater != nil {
+		return storage.ModeGetLookup
+	}
+	return mode
+}
+
+// chunkSize returns the number of bytes accounted for the chunk.
+func chunkSize(ch swarm.Chunk) uint64 {
+	return uint64(len(ch.Address().Bytes()) + len(ch.Data()))
+}
+
+// get returns the cached chunk and marks it as recently used.
+func (c *Cache) get(addr swarm.Address) (ch swarm.Chunk, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[addr.ByteString()]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(swarm.Chunk), true
+}
+
+// has reports if the chunk is cached.
+func (c *Cache) has(addr swarm.Address) (yes bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, yes = c.items[addr.ByteString()]
+	return yes
+}
+
+// removalsCount returns the number of invalidations, which
+// must be passed to the add function for chunks retrieved after
+// this call.
+func (c *Cache) removalsCount() (count uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.removals
+}
+
+// add caches chunks, evicting the least recently used ones to keep the
+// size within the capacity. Chunks are not cached if any chunk was
+// invalidated after the removals count was taken, as they could have
+// been removed from the underlying store in the meantime.
+func (c *Cache) add(removals uint64, chunks ...swarm.Chunk) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.removals != removals {
+		return
+	}
+	for _, ch := range chunks {
+		key := ch.Address().ByteString()
+		if e, ok := c.items[key]; ok {
+			c.lru.MoveToFront(e)
+			continue
+		}
+		size := chunkSize(ch)
+		if size > c.capacity {
+			continue
+		}
+		for c.size+size > c.capacity {
+			c.removeElement(c.lru.Back())
+			c.metrics.EvictedCount.Inc()
+		}
+		c.items[key] = c.lru.PushFront(ch)
+		c.size += size
+	}
+	c.metrics.SizeBytes.Set(float64(c.size))
+}
+
+// remove invalidates cached chunks.
+func (c *Cache) remove(addrs ...swarm.Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removals++
+	for _, addr := range addrs {
+		if e, ok := c.items[addr.ByteString()]; ok {
+			c.removeElement(e)
+			c.metrics.InvalidatedCount.Inc()
+		}
+	}
+	c.metrics.SizeBytes.Set(float64(c.size))
+}
+
+// removeElement removes the chunk from the cache.
+// It must be called under the mu lock.
+func (c *Cache) removeElement(e *list.Element) {
+	ch := c.lru.Remove(e).(swarm.Chunk)
+	delete(c.items, ch.Address().ByteString())
+	c.size -= chunkSize(ch)
+}
+
+// hit and miss count cache lookups and update the hit ratio.
+func (c *Cache) hit(n int) {
+	c.countLookups(n, 0)
+}
+
+func (c *Cache) miss(n int) {
+	c.countLookups(0, n)
+}
+
+func (c *Cache) countLookups(hits, misses int) {
+	if hits == 0 && misses == 0 {
+		return
+	}
+	c.metrics.HitCount.Add(float64(hits))
+	c.metrics.MissCount.Add(float64(misses))
+
+	c.mu.Lock()
+	c.hits += uint64(hits)
+	c.misses += uint64(misses)
+	ratio := float64(c.hits) / float64(c.hits+c.misses)
+	c.mu.Unlock()
+
+	c.metrics.HitRatio.Set(ratio)
+}
+
+// access schedules access time updates of retrieved chunks.
+func (c *Cache) access(addrs ...swarm.Address) {
+	if c.accessUpdater == nil {
+		return
+	}
+	c.accessMu.Lock()
+	for _, addr := range addrs {
+		c.accessPending[addr.ByteString()] = addr
+	}
+	pending := len(c.accessPending)
+	c.accessMu.Unlock()
+
+	if pending >= accessFlushSize {
+		select {
+		case c.accessFlushTrigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// accessFlushWorker writes pending access updates periodically
+// or when there are enough of them, until the cache is closed.
+func (c *Cache) accessFlushWorker() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.accessFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.accessFlushTrigger:
+		case <-c.quit:
+			return
+		}
+		c.flushAccess()
+	}
+}
+
+// flushAccess writes all pending access updates
+// to the underlying store in a single call.
+func (c *Cache) flushAccess() {
+	c.accessMu.Lock()
+	if len(c.accessPending) == 0 {
+		c.accessMu.Unlock()
+		return
+	}
+	addrs := make([]swarm.Address, 0, len(c.accessPending))
+	for _, addr := range c.accessPending {
+		addrs = append(addrs, addr)
+	}
+	c.accessPending = make(map[string]swarm.Address)
+	c.accessMu.Unlock()
+
+	c.metrics.AccessFlushCount.Inc()
+	if err := c.accessUpdater.UpdateAccess(addrs...); err != nil {
+		c.metrics.AccessFlushFailure.Inc()
+		c.logger.Debugf("cache: update access of %v chunks: %v", len(addrs), err)
+		c.logger.Error("cache: update access")
+	}
+}
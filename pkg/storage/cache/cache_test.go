@@ -0,0 +1,298 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/cache"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var logger = logging.New(ioutil.Discard, 0)
+
+func TestCache_Get(t *testing.T) {
+	store := newCountingStorer()
+	c := cache.New(store, nil, logger)
+	defer c.Close()
+
+	ch := chunktesting.GenerateTestRandomChunk()
+	if _, err := c.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := c.Get(context.Background(), storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Data(), ch.Data()) {
+			t.Fatalf("got chunk data %x, want %x", got.Data(), ch.Data())
+		}
+	}
+	if got := store.getCount(); got != 1 {
+		t.Errorf("got %v store gets, want 1", got)
+	}
+
+	t.Run("multi", func(t *testing.T) {
+		other := chunktesting.GenerateTestRandomChunk()
+		if _, err := c.Put(context.Background(), storage.ModePutUpload, other); err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.GetMulti(context.Background(), storage.ModeGetRequest, ch.Address(), other.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got[0].Address().Equal(ch.Address()) || !got[1].Address().Equal(other.Address()) {
+			t.Errorf("got chunks %s %s, want %s %s", got[0].Address(), got[1].Address(), ch.Address(), other.Address())
+		}
+		if got := store.getCount(); got != 2 {
+			t.Errorf("got %v store gets, want 2", got)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := c.Get(context.Background(), storage.ModeGetRequest, chunktesting.GenerateTestRandomChunk().Address())
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+}
+
+func TestCache_capacity(t *testing.T) {
+	chunks := make([]swarm.Chunk, 3)
+	for i := range chunks {
+		chunks[i] = chunktesting.GenerateTestRandomChunk()
+	}
+	size := uint64(len(chunks[0].Address().Bytes()) + len(chunks[0].Data()))
+
+	store := newCountingStorer()
+	c := cache.New(store, &cache.Options{
+		CapacityBytes: 2 * size,
+	}, logger)
+	defer c.Close()
+
+	if _, err := c.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range chunks {
+		if _, err := c.Get(context.Background(), storage.ModeGetRequest, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the least recently used chunk is evicted
+	for i, want := range []int{3, 3, 4} {
+		if _, err := c.Get(context.Background(), storage.ModeGetRequest, chunks[2-i].Address()); err != nil {
+			t.Fatal(err)
+		}
+		if got := store.getCount(); got != want {
+			t.Errorf("chunk %v: got %v store gets, want %v", 2-i, got, want)
+		}
+	}
+}
+
+func TestCache_remove(t *testing.T) {
+	store := newCountingStorer()
+	c := cache.New(store, nil, logger)
+	defer c.Close()
+
+	chunks := []swarm.Chunk{
+		chunktesting.GenerateTestRandomChunk(),
+		chunktesting.GenerateTestRandomChunk(),
+	}
+	if _, err := c.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetMulti(context.Background(), storage.ModeGetRequest, chunks[0].Address(), chunks[1].Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Set(context.Background(), storage.ModeSetRemove, chunks[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(context.Background(), storage.ModeGetRequest, chunks[0].Address()); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	t.Run("notification", func(t *testing.T) {
+		// remove the chunk from the store directly, like garbage collection
+		if err := store.Storer.Set(context.Background(), storage.ModeSetRemove, chunks[1].Address()); err != nil {
+			t.Fatal(err)
+		}
+		store.notifyRemoved(chunks[1].Address())
+
+		if _, err := c.Get(context.Background(), storage.ModeGetRequest, chunks[1].Address()); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+}
+
+func TestCache_access(t *testing.T) {
+	store := newCountingStorer()
+	c := cache.New(store, &cache.Options{
+		AccessFlushInterval: time.Hour,
+	}, logger)
+
+	chunks := []swarm.Chunk{
+		chunktesting.GenerateTestRandomChunk(),
+		chunktesting.GenerateTestRandomChunk(),
+		chunktesting.GenerateTestRandomChunk(),
+	}
+	if _, err := c.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		for _, ch := range chunks[:2] {
+			if _, err := c.Get(context.Background(), storage.ModeGetRequest, ch.Address()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// access is not updated for chunks retrieved for syncing
+	if _, err := c.Get(context.Background(), storage.ModeGetSync, chunks[2].Address()); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.accessed(); len(got) != 0 {
+		t.Fatalf("got %v access updates before flush, want none", len(got))
+	}
+
+	// pending updates are written on close
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	updates := store.accessUpdates()
+	if len(updates) != 1 {
+		t.Fatalf("got %v access updates, want 1", len(updates))
+	}
+	got := store.accessed()
+	if len(got) != 2 {
+		t.Fatalf("got %v accessed chunks, want 2", len(got))
+	}
+	for _, ch := range chunks[:2] {
+		if _, ok := got[ch.Address().String()]; !ok {
+			t.Errorf("chunk %s access not updated", ch.Address())
+		}
+	}
+}
+
+// TestCache_localstore validates that chunks removed by the
+// localstore are invalidated in the cache.
+func TestCache_localstore(t *testing.T) {
+	db, err := localstore.New("", make([]byte, 32), nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cache.New(db, nil, logger)
+	defer c.Close()
+
+	ch := chunktesting.GenerateTestRandomChunk()
+	if _, err := c.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(context.Background(), storage.ModeSetSyncPull, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(context.Background(), storage.ModeGetRequest, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Set(context.Background(), storage.ModeSetRemove, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(context.Background(), storage.ModeGetRequest, ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+// countingStorer counts Get calls and records access updates,
+// implementing optional interfaces used by the cache.
+type countingStorer struct {
+	storage.Storer
+
+	mu            sync.Mutex
+	gets          int
+	updates       [][]swarm.Address
+	removeHandler func(addrs ...swarm.Address)
+}
+
+func newCountingStorer() *countingStorer {
+	return &countingStorer{
+		Storer: mock.NewStorer(),
+	}
+}
+
+func (s *countingStorer) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	s.mu.Lock()
+	s.gets++
+	s.mu.Unlock()
+	return s.Storer.Get(ctx, mode, addr)
+}
+
+func (s *countingStorer) GetMulti(ctx context.Context, mode storage.ModeGet, addrs ...swarm.Address) (chunks []swarm.Chunk, err error) {
+	for _, addr := range addrs {
+		ch, err := s.Get(ctx, mode, addr)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, ch)
+	}
+	return chunks, nil
+}
+
+func (s *countingStorer) UpdateAccess(addrs ...swarm.Address) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updates = append(s.updates, addrs)
+	return nil
+}
+
+func (s *countingStorer) SetRemoveHandler(h func(addrs ...swarm.Address)) {
+	s.removeHandler = h
+}
+
+func (s *countingStorer) notifyRemoved(addrs ...swarm.Address) {
+	s.removeHandler(addrs...)
+}
+
+func (s *countingStorer) Close() error {
+	return nil
+}
+
+func (s *countingStorer) getCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.gets
+}
+
+func (s *countingStorer) accessUpdates() [][]swarm.Address {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updates
+}
+
+func (s *countingStorer) accessed() map[string]struct{} {
+	accessed := make(map[string]struct{})
+	for _, addrs := range s.accessUpdates() {
+		for _, addr := range addrs {
+			accessed[addr.String()] = struct{}{}
+		}
+	}
+	return accessed
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	HitCount           prometheus.Counter
+	MissCount          prometheus.Counter
+	HitRatio           prometheus.Gauge
+	EvictedCount       prometheus.Counter
+	InvalidatedCount   prometheus.Counter
+	SizeBytes          prometheus.Gauge
+	CapacityBytes      prometheus.Gauge
+	AccessFlushCount   prometheus.Counter
+	AccessFlushFailure prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "chunk_cache"
+
+	return metrics{
+		HitCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "hit_count",
+			Help:      "Number of chunks served from the cache.",
+		}),
+		MissCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "miss_count",
+			Help:      "Number of chunks not found in the cache.",
+		}),
+		HitRatio: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "hit_ratio",
+			Help:      "Ratio of cache hits to all cache lookups.",
+		}),
+		EvictedCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "evicted_count",
+			Help:      "Number of least recently used chunks evicted from the cache.",
+		}),
+		InvalidatedCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "invalidated_count",
+			Help:      "Number of cached chunks removed from the underlying store.",
+		}),
+		SizeBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "size_bytes",
+			Help:      "Number of bytes of cached chunks.",
+		}),
+		CapacityBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "capacity_bytes",
+			Help:      "Maximal number of bytes of cached chunks.",
+		}),
+		AccessFlushCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "access_flush_count",
+			Help:      "Number of batched access time updates.",
+		}),
+		AccessFlushFailure: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "access_flush_failure",
+			Help:      "Number of failed batched access time updates.",
+		}),
+	}
+}
+
+func (c *Cache) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(c.metrics)
+}
@@ -6,6 +6,8 @@ package mock
 
 import (
 	"context"
+	"sort"
+	"sync"
 
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -13,30 +15,48 @@ import (
 
 type mockStorer struct {
 	store     map[string][]byte
+	pinned    map[string]uint64
 	validator storage.ChunkValidatorFunc
+	mu        sync.Mutex
 }
 
 func NewStorer() storage.Storer {
 	return &mockStorer{
-		store: make(map[string][]byte),
+		store:  make(map[string][]byte),
+		pinned: make(map[string]uint64),
 	}
 }
 
 func NewValidatingStorer(f storage.ChunkValidatorFunc) storage.Storer {
 	return &mockStorer{
 		store:     make(map[string][]byte),
+		pinned:    make(map[string]uint64),
 		validator: f,
 	}
 }
+
 func (m *mockStorer) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (ch swarm.Chunk, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if mode == storage.ModeGetPin {
+		c, has := m.pinned[addr.String()]
+		if !has {
+			return nil, storage.ErrNotFound
+		}
+		return swarm.NewChunk(addr, nil).WithPinCounter(c), nil
+	}
 	v, has := m.store[addr.String()]
 	if !has {
 		return nil, storage.ErrNotFound
 	}
-	return swarm.NewChunk(addr, v), nil
+	return swarm.NewChunk(addr, v).WithPinCounter(m.pinned[addr.String()]), nil
 }
 
 func (m *mockStorer) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	for _, ch := range chs {
 		if m.validator != nil {
 			if !m.validator(ch.Address(), ch.Data()) {
@@ -48,12 +68,23 @@ func (m *mockStorer) Put(ctx context.Context, mode storage.ModePut, chs ...swarm
 	return nil, nil
 }
 
-func (m *mockStorer) GetMulti(ctx context.Context, mode storage.ModeGet, addrs ...swarm.Address) (ch []swarm.Chunk, err error) {
-	panic("not implemented") // TODO: Implement
+func (m *mockStorer) GetMulti(ctx context.Context, mode storage.ModeGet, addrs ...swarm.Address) (chs []swarm.Chunk, err error) {
+	for _, addr := range addrs {
+		ch, err := m.Get(ctx, mode, addr)
+		if err != nil {
+			return nil, err
+		}
+		chs = append(chs, ch)
+	}
+	return chs, nil
 }
 
 func (m *mockStorer) Has(ctx context.Context, addr swarm.Address) (yes bool, err error) {
-	panic("not implemented") // TODO: Implement
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, has := m.store[addr.String()]
+	return has, nil
 }
 
 func (m *mockStorer) HasMulti(ctx context.Context, addrs ...swarm.Address) (yes []bool, err error) {
@@ -61,7 +92,35 @@ func (m *mockStorer) HasMulti(ctx context.Context, addrs ...swarm.Address) (yes
 }
 
 func (m *mockStorer) Set(ctx context.Context, mode storage.ModeSet, addrs ...swarm.Address) (err error) {
-	panic("not implemented") // TODO: Implement
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch mode {
+	case storage.ModeSetPin:
+		for _, addr := range addrs {
+			m.pinned[addr.String()]++
+		}
+	case storage.ModeSetUnpin:
+		for _, addr := range addrs {
+			c, has := m.pinned[addr.String()]
+			if !has {
+				return storage.ErrNotFound
+			}
+			if c > 1 {
+				m.pinned[addr.String()] = c - 1
+			} else {
+				delete(m.pinned, addr.String())
+			}
+		}
+	case storage.ModeSetRemove:
+		for _, addr := range addrs {
+			delete(m.store, addr.String())
+			delete(m.pinned, addr.String())
+		}
+	default:
+		panic("not implemented") // TODO: Implement
+	}
+	return nil
 }
 
 func (m *mockStorer) LastPullSubscriptionBinID(bin uint8) (id uint64, err error) {
@@ -72,6 +131,33 @@ func (m *mockStorer) SubscribePull(ctx context.Context, bin uint8, since uint64,
 	panic("not implemented") // TODO: Implement
 }
 
+func (m *mockStorer) PinnedChunks(ctx context.Context, cursor swarm.Address, limit int) (pinnedChunks []*storage.Pinner, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]string, 0, len(m.pinned))
+	for k := range m.pinned {
+		if cursor.IsZero() || k > cursor.String() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if len(pinnedChunks) >= limit {
+			break
+		}
+		addr, err := swarm.ParseHexAddress(k)
+		if err != nil {
+			return nil, err
+		}
+		pinnedChunks = append(pinnedChunks, &storage.Pinner{
+			Address:    addr,
+			PinCounter: m.pinned[k],
+		})
+	}
+	return pinnedChunks, nil
+}
+
 func (m *mockStorer) Close() error {
 	panic("not implemented") // TODO: Implement
 }
@@ -126,6 +126,13 @@ func (d *Descriptor) String() string {
 	return fmt.Sprintf("%s bin id %v", d.Address, d.BinID)
 }
 
+// Pinner holds the address of a pinned chunk and the
+// number of times it is pinned.
+type Pinner struct {
+	Address    swarm.Address
+	PinCounter uint64
+}
+
 type Storer interface {
 	Get(ctx context.Context, mode ModeGet, addr swarm.Address) (ch swarm.Chunk, err error)
 	GetMulti(ctx context.Context, mode ModeGet, addrs ...swarm.Address) (ch []swarm.Chunk, err error)
@@ -135,6 +142,7 @@ type Storer interface {
 	Set(ctx context.Context, mode ModeSet, addrs ...swarm.Address) (err error)
 	LastPullSubscriptionBinID(bin uint8) (id uint64, err error)
 	SubscribePull(ctx context.Context, bin uint8, since, until uint64) (c <-chan Descriptor, stop func())
+	PinnedChunks(ctx context.Context, cursor swarm.Address, limit int) (pinnedChunks []*Pinner, err error)
 	io.Closer
 }
 
@@ -150,3 +158,6 @@ type StateStorer interface {
 
 // StateIterFunc is used when iterating through StateStorer key/value pairs
 type StateIterFunc func(key, value []byte) (stop bool, err error)
+
+// ChunkValidatorFunc validates Swarm chunk address and chunk data
+type ChunkValidatorFunc func(swarm.Address, []byte) (valid bool)
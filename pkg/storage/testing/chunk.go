@@ -17,9 +17,11 @@
 package testing
 
 import (
+	"encoding/binary"
 	"math/rand"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
@@ -52,3 +54,16 @@ func GenerateTestRandomChunks(count int) []swarm.Chunk {
 	}
 	return chunks
 }
+
+// GenerateValidRandomChunk generates a content addressed Chunk
+// with random data, which is valid for ContentAddressValidator.
+func GenerateValidRandomChunk() swarm.Chunk {
+	data := make([]byte, file.SpanSize+swarm.ChunkSize)
+	binary.LittleEndian.PutUint64(data, swarm.ChunkSize)
+	rand.Read(data[file.SpanSize:])
+	addr, err := file.ContentAddress(data)
+	if err != nil {
+		panic(err)
+	}
+	return swarm.NewChunk(addr, data)
+}
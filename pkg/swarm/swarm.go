@@ -7,15 +7,18 @@ package swarm
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 )
 
 const (
-	ChunkSize = 4096
+	ChunkSize   = 4096
 	SectionSize = 32
-	MaxPO     = 16
+	Branches    = ChunkSize / SectionSize
+	HashSize    = 32
+	MaxPO       = 16
 )
 
 // Address represents an address in Swarm metric space of
@@ -98,6 +101,17 @@ type Chunk interface {
 	WithPinCounter(p uint64) Chunk
 	TagID() uint32
 	WithTagID(t uint32) Chunk
+	Stamp() Stamp
+	WithStamp(s Stamp) Chunk
+}
+
+// Stamp is a postage stamp attached to a chunk as a proof that its storage
+// is paid for by a postage batch.
+type Stamp interface {
+	BatchID() []byte
+	Index() uint64
+	Sig() []byte
+	encoding.BinaryMarshaler
 }
 
 type chunk struct {
@@ -105,6 +119,7 @@ type chunk struct {
 	sdata      []byte
 	pinCounter uint64
 	tagID      uint32
+	stamp      Stamp
 }
 
 func NewChunk(addr Address, data []byte) Chunk {
@@ -124,6 +139,11 @@ func (c *chunk) WithTagID(t uint32) Chunk {
 	return c
 }
 
+func (c *chunk) WithStamp(s Stamp) Chunk {
+	c.stamp = s
+	return c
+}
+
 func (c *chunk) Address() Address {
 	return c.addr
 }
@@ -140,6 +160,10 @@ func (c *chunk) TagID() uint32 {
 	return c.tagID
 }
 
+func (c *chunk) Stamp() Stamp {
+	return c.stamp
+}
+
 func (self *chunk) String() string {
 	return fmt.Sprintf("Address: %v Chunksize: %v", self.addr.String(), len(self.sdata))
 }
@@ -147,3 +171,29 @@ func (self *chunk) String() string {
 type ChunkValidator interface {
 	Validate(ch Chunk) (valid bool)
 }
+
+// MultiValidator is a chain of chunk validators for different
+// chunk types. A chunk is valid if any of the validators
+// in the chain validates it.
+type MultiValidator struct {
+	validators []ChunkValidator
+}
+
+// NewMultiValidator constructs a new MultiValidator
+// with validators that are run in the provided order.
+func NewMultiValidator(validators ...ChunkValidator) *MultiValidator {
+	return &MultiValidator{
+		validators: validators,
+	}
+}
+
+// Validate returns true if any of the validators in the
+// chain validates the chunk.
+func (v *MultiValidator) Validate(ch Chunk) (valid bool) {
+	for _, validator := range v.validators {
+		if validator.Validate(ch) {
+			return true
+		}
+	}
+	return false
+}
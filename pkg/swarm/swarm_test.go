@@ -81,3 +81,29 @@ func TestAddress_jsonMarshalling(t *testing.T) {
 		t.Error("unmarshalled address is not equal to the original")
 	}
 }
+
+func TestMultiValidator(t *testing.T) {
+	ch := swarm.NewChunk(swarm.MustParseHexAddress("aabb"), []byte("data"))
+
+	for _, tc := range []struct {
+		name       string
+		validators []swarm.ChunkValidator
+		want       bool
+	}{
+		{name: "empty", want: false},
+		{name: "none valid", validators: []swarm.ChunkValidator{validator(false), validator(false)}, want: false},
+		{name: "one valid", validators: []swarm.ChunkValidator{validator(false), validator(true)}, want: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := swarm.NewMultiValidator(tc.validators...).Validate(ch); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+type validator bool
+
+func (v validator) Validate(swarm.Chunk) bool {
+	return bool(v)
+}
@@ -27,9 +27,14 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tracing"
 )
 
+// stateKey is the state store key under which tags are persisted.
+const stateKey = "tags"
+
 var (
 	TagUidFunc     = rand.Uint32
 	TagNotFoundErr = errors.New("tag not found")
@@ -149,8 +154,27 @@ func (ts *Tags) UnmarshalJSON(value []byte) error {
 		// and the node was turned off before the receipt was received
 		v.Sent = v.Synced
 
-		ts.tags.Store(key, v)
+		// tracing span is not persisted
+		var tracer *tracing.Tracer
+		v.span, _, v.ctx = tracer.StartSpanFromContext(context.Background(), "new.upload.tag", nil)
+
+		ts.tags.Store(uint32(key), v)
 	}
 
 	return err
 }
+
+// Save persists tags which are not yet synced to the state store,
+// replacing previously saved ones.
+func (ts *Tags) Save(store storage.StateStorer) error {
+	return store.Put(stateKey, ts)
+}
+
+// Load adds tags persisted by Save to the state store.
+func (ts *Tags) Load(store storage.StateStorer) error {
+	err := store.Get(stateKey, ts)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
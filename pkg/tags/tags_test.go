@@ -17,7 +17,11 @@
 package tags
 
 import (
+	"errors"
 	"testing"
+
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 func TestAll(t *testing.T) {
@@ -52,3 +56,70 @@ func TestAll(t *testing.T) {
 		t.Fatalf("expected length to be 3 got %d", len(all))
 	}
 }
+
+func TestSaveLoad(t *testing.T) {
+	store := statestore.NewStateStore()
+
+	ts := NewTags()
+	unfinished, err := ts.Create("unfinished", 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unfinished.Address = swarm.MustParseHexAddress("0a0b")
+	unfinished.IncN(StateStored, 10)
+	unfinished.IncN(StateSent, 5)
+	unfinished.IncN(StateSynced, 3)
+
+	synced, err := ts.Create("synced", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	synced.Inc(StateStored)
+	synced.Inc(StateSynced)
+
+	if err := ts.Save(store); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewTags()
+	if err := loaded.Load(store); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loaded.Get(unfinished.Uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != unfinished.Name {
+		t.Errorf("got name %q, want %q", got.Name, unfinished.Name)
+	}
+	if !got.Address.Equal(unfinished.Address) {
+		t.Errorf("got address %s, want %s", got.Address, unfinished.Address)
+	}
+	for state, want := range map[State]int64{
+		StateStored: 10,
+		StateSynced: 3,
+		// chunks sent without a receipt are sent again
+		StateSent: 3,
+	} {
+		if n := got.Get(state); n != want {
+			t.Errorf("got state %v count %v, want %v", state, n, want)
+		}
+	}
+	got.FinishRootSpan()
+
+	// synced tags are not persisted
+	if _, err := loaded.Get(synced.Uid); !errors.Is(err, TagNotFoundErr) {
+		t.Errorf("got error %v, want %v", err, TagNotFoundErr)
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		ts := NewTags()
+		if err := ts.Load(statestore.NewStateStore()); err != nil {
+			t.Fatal(err)
+		}
+		if all := ts.All(); len(all) != 0 {
+			t.Errorf("got %v tags, want none", len(all))
+		}
+	})
+}
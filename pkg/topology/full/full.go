@@ -5,9 +5,11 @@
 package full
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
 
@@ -24,23 +26,34 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-var _ topology.Driver = (*Driver)(nil)
+var (
+	_ topology.Driver      = (*Driver)(nil)
+	_ topology.Snapshotter = (*Driver)(nil)
+)
+
+// nnLowWatermark is the minimal number of connected
+// peers in the neighbourhood of the node.
+const nnLowWatermark = 2
 
 // Driver drives the connectivity between nodes. It is a basic implementation of a connectivity Driver.
 // that enabled full connectivity in the sense that:
 // - Every peer which is added to the Driver gets broadcasted to every other peer regardless of its address.
 // - A random peer is picked when asking for a peer to retrieve an arbitrary chunk (Peerer interface).
 type Driver struct {
+	base          swarm.Address // the base address of this node
 	discovery     discovery.Driver
 	addressBook   addressbook.GetPutter
 	p2pService    p2p.Service
 	receivedPeers map[string]struct{} // track already received peers. Note: implement cleanup or expiration if needed to stop infinite grow
-	mtx           sync.Mutex          // guards received peers
+	mtx           sync.Mutex          // guards received peers and depth fields
+	depth         uint8
+	depthHandler  func(depth uint8)
 	logger        logging.Logger
 }
 
-func New(disc discovery.Driver, addressBook addressbook.GetPutter, p2pService p2p.Service, logger logging.Logger) *Driver {
+func New(base swarm.Address, disc discovery.Driver, addressBook addressbook.GetPutter, p2pService p2p.Service, logger logging.Logger) *Driver {
 	return &Driver{
+		base:          base,
 		discovery:     disc,
 		addressBook:   addressBook,
 		p2pService:    p2pService,
@@ -56,6 +69,8 @@ func (d *Driver) AddPeer(ctx context.Context, addr swarm.Address) error {
 	d.mtx.Lock()
 	if _, ok := d.receivedPeers[addr.ByteString()]; ok {
 		d.mtx.Unlock()
+		// the peer may be connected again
+		d.updateDepth()
 		return nil
 	}
 
@@ -86,6 +101,7 @@ func (d *Driver) AddPeer(ctx context.Context, addr swarm.Address) error {
 			}
 		}
 	}
+	d.updateDepth()
 
 	connectedAddrs := []swarm.Address{}
 	for _, addressee := range connectedPeers {
@@ -111,6 +127,12 @@ func (d *Driver) AddPeer(ctx context.Context, addr swarm.Address) error {
 	return nil
 }
 
+// RemovePeer is called when the peer is disconnected. It updates the
+// neighbourhood depth, which decreases if the peer was a neighbour.
+func (d *Driver) RemovePeer(addr swarm.Address) {
+	d.updateDepth()
+}
+
 // ChunkPeer is used to suggest a peer to ask a certain chunk from.
 func (d *Driver) ChunkPeer(addr swarm.Address) (peerAddr swarm.Address, err error) {
 	connectedPeers := d.p2pService.Peers()
@@ -130,6 +152,124 @@ func (d *Driver) ChunkPeer(addr swarm.Address) (peerAddr swarm.Address, err erro
 	return swarm.Address{}, topology.ErrNotFound
 }
 
+// NeighborhoodDepth returns the proximity order from which
+// at least nnLowWatermark connected peers are in the same bins.
+// This node is responsible for storing chunks within the depth.
+func (d *Driver) NeighborhoodDepth() uint8 {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	return d.depth
+}
+
+// SetNeighborhoodDepthHandler sets the function that is called
+// with the new neighbourhood depth when it changes. It is called
+// immediately with the current depth.
+func (d *Driver) SetNeighborhoodDepthHandler(h func(depth uint8)) {
+	d.mtx.Lock()
+	d.depthHandler = h
+	depth := d.depth
+	d.mtx.Unlock()
+
+	if h != nil {
+		h(depth)
+	}
+}
+
+// updateDepth computes the neighbourhood depth from connected
+// peers and calls the depth handler if it has changed.
+func (d *Driver) updateDepth() {
+	var bins [swarm.MaxPO + 1]int
+	for _, p := range d.p2pService.Peers() {
+		bins[swarm.Proximity(d.base.Bytes(), p.Address.Bytes())]++
+	}
+	var depth uint8
+	var count int
+	for po := swarm.MaxPO; po >= 0; po-- {
+		count += bins[po]
+		if count >= nnLowWatermark {
+			depth = uint8(po)
+			break
+		}
+	}
+
+	d.mtx.Lock()
+	changed := depth != d.depth
+	d.depth = depth
+	h := d.depthHandler
+	d.mtx.Unlock()
+
+	if changed && h != nil {
+		h(depth)
+	}
+}
+
+// Snapshot returns connected peers and peers received by AddPeer with
+// their proximity, ordered from the closest ones, and their numbers in
+// bins. Bins with at least nnLowWatermark connected peers are reported
+// as saturated.
+func (d *Driver) Snapshot() *topology.Snapshot {
+	connected := d.p2pService.Peers()
+
+	d.mtx.Lock()
+	known := make([]swarm.Address, 0, len(d.receivedPeers))
+	for k := range d.receivedPeers {
+		known = append(known, swarm.NewAddress([]byte(k)))
+	}
+	depth := d.depth
+	d.mtx.Unlock()
+
+	peers := make(map[string]*topology.PeerSnapshot, len(known)+len(connected))
+	for _, addr := range known {
+		peers[addr.ByteString()] = &topology.PeerSnapshot{
+			Address:   addr,
+			Proximity: uint8(swarm.Proximity(d.base.Bytes(), addr.Bytes())),
+		}
+	}
+	for _, p := range connected {
+		ps, ok := peers[p.Address.ByteString()]
+		if !ok {
+			ps = &topology.PeerSnapshot{
+				Address:   p.Address,
+				Proximity: uint8(swarm.Proximity(d.base.Bytes(), p.Address.Bytes())),
+			}
+			peers[p.Address.ByteString()] = ps
+		}
+		ps.Connected = true
+	}
+
+	s := &topology.Snapshot{
+		BaseAddr:  d.base,
+		Timestamp: time.Now(),
+		Depth:     depth,
+		Known:     len(peers),
+		Bins:      make([]topology.BinSnapshot, swarm.MaxPO+1),
+		Peers:     make([]topology.PeerSnapshot, 0, len(peers)),
+	}
+	for po := range s.Bins {
+		s.Bins[po].PO = uint8(po)
+	}
+	for _, ps := range peers {
+		bin := &s.Bins[ps.Proximity]
+		bin.Known++
+		if ps.Connected {
+			bin.Connected++
+			s.Connected++
+		}
+		s.Peers = append(s.Peers, *ps)
+	}
+	for po := range s.Bins {
+		s.Bins[po].Saturated = s.Bins[po].Connected >= nnLowWatermark
+	}
+	sort.Slice(s.Peers, func(i, j int) bool {
+		if s.Peers[i].Proximity != s.Peers[j].Proximity {
+			return s.Peers[i].Proximity > s.Peers[j].Proximity
+		}
+		return bytes.Compare(s.Peers[i].Address.Bytes(), s.Peers[j].Address.Bytes()) < 0
+	})
+	return s
+}
+
 func isConnected(addr swarm.Address, connectedPeers []p2p.Peer) bool {
 	for _, p := range connectedPeers {
 		if p.Address.Equal(addr) {
@@ -27,6 +27,7 @@ func TestAddPeer(t *testing.T) {
 	logger := logging.New(ioutil.Discard, 0)
 	underlay := "/ip4/127.0.0.1/tcp/7070/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkS"
 	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59a")
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
 	connectedPeers := []p2p.Peer{
 		{
 			Address: swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59b"),
@@ -51,7 +52,7 @@ func TestAddPeer(t *testing.T) {
 			return overlay, nil
 		}))
 
-		fullDriver := full.New(discovery, ab, p2p, logger)
+		fullDriver := full.New(base, discovery, ab, p2p, logger)
 		multiaddr, err := ma.NewMultiaddr(underlay)
 		if err != nil {
 			t.Fatal(err)
@@ -81,7 +82,7 @@ func TestAddPeer(t *testing.T) {
 			return swarm.Address{}, nil
 		}))
 
-		fullDriver := full.New(discovery, ab, p2p, logger)
+		fullDriver := full.New(base, discovery, ab, p2p, logger)
 		err := fullDriver.AddPeer(context.Background(), overlay)
 		if !errors.Is(err, topology.ErrNotFound) {
 			t.Fatalf("full conn driver returned err %v", err)
@@ -105,7 +106,7 @@ func TestAddPeer(t *testing.T) {
 			return connectedPeers
 		}))
 
-		fullDriver := full.New(discovery, ab, p2p, logger)
+		fullDriver := full.New(base, discovery, ab, p2p, logger)
 		multiaddr, err := ma.NewMultiaddr(underlay)
 		if err != nil {
 			t.Fatal("error creating multiaddr")
@@ -152,7 +153,7 @@ func TestAddPeer(t *testing.T) {
 			return connectedPeers
 		}))
 
-		fullDriver := full.New(discovery, ab, p2ps, logger)
+		fullDriver := full.New(base, discovery, ab, p2ps, logger)
 		multiaddr, err := ma.NewMultiaddr(underlay)
 		if err != nil {
 			t.Fatal(err)
@@ -186,6 +187,145 @@ func TestAddPeer(t *testing.T) {
 	})
 }
 
+func TestNeighborhoodDepth(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	underlay := "/ip4/127.0.0.1/tcp/7070/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkS"
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	// peers in bins 0, 1, 2 and 3
+	connectedPeers := []p2p.Peer{
+		{Address: swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000")},
+		{Address: swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000")},
+		{Address: swarm.MustParseHexAddress("2000000000000000000000000000000000000000000000000000000000000000")},
+		{Address: swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")},
+	}
+	overlay := connectedPeers[3].Address
+
+	ab := addressbook.New(mockstate.NewStateStore())
+	p2ps := p2pmock.New(p2pmock.WithConnectFunc(func(_ context.Context, _ ma.Multiaddr) (swarm.Address, error) {
+		return overlay, nil
+	}), p2pmock.WithPeersFunc(func() []p2p.Peer {
+		return connectedPeers
+	}))
+
+	fullDriver := full.New(base, mock.NewDiscovery(), ab, p2ps, logger)
+
+	var depths []uint8
+	fullDriver.SetNeighborhoodDepthHandler(func(depth uint8) {
+		depths = append(depths, depth)
+	})
+
+	multiaddr, err := ma.NewMultiaddr(underlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ab.Put(overlay, multiaddr); err != nil {
+		t.Fatal(err)
+	}
+	if err := fullDriver.AddPeer(context.Background(), overlay); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fullDriver.NeighborhoodDepth(); got != 2 {
+		t.Errorf("got depth %v, want 2", got)
+	}
+	if fmt.Sprint(depths) != "[0 2]" {
+		t.Errorf("got depth handler calls %v, want [0 2]", depths)
+	}
+
+	// the neighbour disconnects
+	connectedPeers = connectedPeers[:3]
+	fullDriver.RemovePeer(overlay)
+
+	if got := fullDriver.NeighborhoodDepth(); got != 1 {
+		t.Errorf("got depth %v, want 1", got)
+	}
+	if fmt.Sprint(depths) != "[0 2 1]" {
+		t.Errorf("got depth handler calls %v, want [0 2 1]", depths)
+	}
+
+	// the neighbour connects again
+	connectedPeers = append(connectedPeers, p2p.Peer{Address: overlay})
+	if err := fullDriver.AddPeer(context.Background(), overlay); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(depths) != "[0 2 1 2]" {
+		t.Errorf("got depth handler calls %v, want [0 2 1 2]", depths)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	underlay := "/ip4/127.0.0.1/tcp/7070/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkS"
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	connectedPeers := []p2p.Peer{
+		{Address: swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000")},
+		{Address: swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000")},
+		{Address: swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")},
+	}
+	// the known peer in bin 3 can not be connected
+	known := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	errConnect := errors.New("connect")
+
+	ab := addressbook.New(mockstate.NewStateStore())
+	p2ps := p2pmock.New(p2pmock.WithConnectFunc(func(_ context.Context, _ ma.Multiaddr) (swarm.Address, error) {
+		return swarm.ZeroAddress, errConnect
+	}), p2pmock.WithPeersFunc(func() []p2p.Peer {
+		return connectedPeers
+	}))
+
+	fullDriver := full.New(base, mock.NewDiscovery(), ab, p2ps, logger)
+
+	multiaddr, err := ma.NewMultiaddr(underlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ab.Put(known, multiaddr); err != nil {
+		t.Fatal(err)
+	}
+	if err := fullDriver.AddPeer(context.Background(), known); !errors.Is(err, errConnect) {
+		t.Fatalf("got error %v, want %v", err, errConnect)
+	}
+
+	s := fullDriver.Snapshot()
+	if !s.BaseAddr.Equal(base) {
+		t.Errorf("got base address %s, want %s", s.BaseAddr, base)
+	}
+	if s.Connected != 3 || s.Known != 4 {
+		t.Errorf("got %v connected and %v known peers, want 3 and 4", s.Connected, s.Known)
+	}
+
+	wantPeers := []topology.PeerSnapshot{
+		{Address: known, Proximity: 3},
+		{Address: connectedPeers[1].Address, Proximity: 1, Connected: true},
+		{Address: connectedPeers[2].Address, Proximity: 1, Connected: true},
+		{Address: connectedPeers[0].Address, Proximity: 0, Connected: true},
+	}
+	if len(s.Peers) != len(wantPeers) {
+		t.Fatalf("got %v peers, want %v", len(s.Peers), len(wantPeers))
+	}
+	for i, want := range wantPeers {
+		got := s.Peers[i]
+		if !got.Address.Equal(want.Address) || got.Proximity != want.Proximity || got.Connected != want.Connected {
+			t.Errorf("peer %v: got %+v, want %+v", i, got, want)
+		}
+	}
+
+	if len(s.Bins) != swarm.MaxPO+1 {
+		t.Fatalf("got %v bins, want %v", len(s.Bins), swarm.MaxPO+1)
+	}
+	for po, want := range map[int]topology.BinSnapshot{
+		0: {PO: 0, Connected: 1, Known: 1},
+		1: {PO: 1, Connected: 2, Known: 2, Saturated: true},
+		2: {PO: 2},
+		3: {PO: 3, Known: 1},
+	} {
+		if got := s.Bins[po]; got != want {
+			t.Errorf("bin %v: got %+v, want %+v", po, got, want)
+		}
+	}
+}
+
 func checkAddreseeRecords(discovery *mock.Discovery, addr swarm.Address, expected []p2p.Peer) error {
 	got, exists := discovery.AddresseeRecords(addr)
 	if exists != true {
@@ -9,11 +9,13 @@ import (
 	"sync"
 
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
 )
 
 type TopologyDriver struct {
 	peers      []swarm.Address
 	addPeerErr error
+	snapshot   *topology.Snapshot
 	mtx        sync.Mutex
 }
 
@@ -39,3 +41,16 @@ func (d *TopologyDriver) AddPeer(_ context.Context, addr swarm.Address) error {
 func (d *TopologyDriver) Peers() []swarm.Address {
 	return d.peers
 }
+
+func (d *TopologyDriver) SetSnapshot(s *topology.Snapshot) {
+	d.mtx.Lock()
+	d.snapshot = s
+	d.mtx.Unlock()
+}
+
+// Snapshot returns the snapshot set by SetSnapshot, or nil if it is not set.
+func (d *TopologyDriver) Snapshot() *topology.Snapshot {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	return d.snapshot
+}
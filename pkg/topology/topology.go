@@ -7,6 +7,7 @@ package topology
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -25,3 +26,41 @@ type PeerAdder interface {
 type ChunkPeerer interface {
 	ChunkPeer(addr swarm.Address) (peerAddr swarm.Address, err error)
 }
+
+// Snapshotter is implemented by drivers that report their connectivity
+// state, so that it can be inspected when chunks are not found. The
+// snapshot is nil if the state is not available.
+type Snapshotter interface {
+	Snapshot() *Snapshot
+}
+
+// Snapshot is the connectivity state of a driver at a point in time.
+// Drivers that organize peers in bins by proximity order report them
+// in Bins, and drivers that track individual peers report them in
+// Peers.
+type Snapshot struct {
+	BaseAddr  swarm.Address  `json:"baseAddr"`
+	Timestamp time.Time      `json:"timestamp"`
+	Depth     uint8          `json:"depth"`
+	Connected int            `json:"connected"`
+	Known     int            `json:"known"`
+	Bins      []BinSnapshot  `json:"bins,omitempty"`
+	Peers     []PeerSnapshot `json:"peers,omitempty"`
+}
+
+// BinSnapshot holds the number of connected and known peers with the
+// proximity order to the base address. A bin is saturated if it has
+// at least as many connected peers as the driver requires in a bin.
+type BinSnapshot struct {
+	PO        uint8 `json:"po"`
+	Connected int   `json:"connected"`
+	Known     int   `json:"known"`
+	Saturated bool  `json:"saturated"`
+}
+
+// PeerSnapshot is a known peer with its proximity to the base address.
+type PeerSnapshot struct {
+	Address   swarm.Address `json:"address"`
+	Proximity uint8         `json:"proximity"`
+	Connected bool          `json:"connected"`
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
 	"github.com/uber/jaeger-client-go"
@@ -34,6 +35,10 @@ var (
 // LogField is the key in log message field that holds tracing id value.
 const LogField = "traceid"
 
+// RequestIDTag is the key in span tags and log message
+// fields that holds the HTTP request identifier.
+const RequestIDTag = "request_id"
+
 // Tracer connect to a tracing server and handles tracing spans and contexts
 // by using opentracing Tracer.
 type Tracer struct {
@@ -77,7 +82,8 @@ func NewTracer(o *Options) (*Tracer, io.Closer, error) {
 
 // StartSpanFromContext starts a new tracing span that is either a root one or a
 // child of existing one from the provided Context. If logger is provided, a new
-// log Entry will be returned with "traceid" log field.
+// log Entry will be returned with "traceid" log field. The HTTP request
+// identifier from the Context is added to the span and the log Entry.
 func (t *Tracer) StartSpanFromContext(ctx context.Context, operationName string, l logging.Logger, opts ...opentracing.StartSpanOption) (opentracing.Span, *logrus.Entry, context.Context) {
 	if t == nil {
 		t = noopTracer
@@ -91,7 +97,14 @@ func (t *Tracer) StartSpanFromContext(ctx context.Context, operationName string,
 		span = t.tracer.StartSpan(operationName, opts...)
 	}
 	sc := span.Context()
-	return span, loggerWithTraceID(sc, l), WithContext(ctx, sc)
+	entry := loggerWithTraceID(sc, l)
+	if id := sctx.GetHTTPRequestID(ctx); id != "" {
+		span.SetTag(RequestIDTag, id)
+		if entry != nil {
+			entry = entry.WithField(RequestIDTag, id)
+		}
+	}
+	return span, entry, WithContext(ctx, sc)
 }
 
 // AddContextHeader adds a tracing span context to provided p2p Headers from
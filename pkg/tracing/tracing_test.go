@@ -13,6 +13,7 @@ import (
 
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/ethersphere/bee/pkg/tracing"
 	"github.com/uber/jaeger-client-go"
 )
@@ -150,6 +151,22 @@ func TestStartSpanFromContext_logger(t *testing.T) {
 	}
 }
 
+func TestStartSpanFromContext_requestID(t *testing.T) {
+	tracer, closer := newTracer(t)
+	defer closer.Close()
+
+	ctx := sctx.SetHTTPRequestID(context.Background(), "some-request")
+	span, logger, _ := tracer.StartSpanFromContext(ctx, "some-operation", logging.New(os.Stdout, 5))
+	defer span.Finish()
+
+	if got := span.(*jaeger.Span).Tags()[tracing.RequestIDTag]; got != "some-request" {
+		t.Errorf("got span tag %q, want %q", got, "some-request")
+	}
+	if got := logger.Data[tracing.RequestIDTag]; got != "some-request" {
+		t.Errorf("got log field %q, want %q", got, "some-request")
+	}
+}
+
 func TestStartSpanFromContext_nilLogger(t *testing.T) {
 	tracer, closer := newTracer(t)
 	defer closer.Close()